@@ -1,8 +1,12 @@
 package application
 
 import (
+	"errors"
+	"github.com/hallgren/eventsourcing"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
 	"log/slog"
+	"strconv"
 )
 
 type BasketApplication struct {
@@ -22,7 +26,9 @@ func (a *BasketApplication) AddItemToBasket(shopId int, basketItem *basket.Baske
 
 	slog.Debug("Adding item to basket", "shopId", shopId, "basketItem", basketItem)
 
-	b.AddItem(basketItem)
+	if err := b.AddItem(basketItem); err != nil {
+		return nil, err
+	}
 
 	if err := a.r.Save(b); err != nil {
 		return nil, err
@@ -40,7 +46,9 @@ func (a *BasketApplication) RemoveItemFromBasket(shopId int, ingredientId string
 		return nil, err
 	}
 
-	b.RemoveItem(ingredientId)
+	if err := b.RemoveItem(ingredientId); err != nil {
+		return nil, err
+	}
 
 	if err := a.r.Save(b); err != nil {
 		return nil, err
@@ -58,3 +66,69 @@ func (a *BasketApplication) GetBasket(shopId int) (*basket.Basket, error) {
 
 	return b, nil
 }
+
+// HandleShopEvent keeps baskets in step with their shops, creating one when a
+// shop is started and freezing it once the shop is completed, in case
+// completing it didn't already. Both are safe to repeat.
+func (a *BasketApplication) HandleShopEvent(ev eventsourcing.Event) error {
+	switch e := ev.Data().(type) {
+	case *shop.Created:
+		return a.createBasket(e.Id)
+	case *shop.Completed:
+		shopId, err := strconv.Atoi(ev.AggregateID())
+
+		if err != nil {
+			return err
+		}
+
+		return a.freezeBasket(shopId)
+	}
+
+	return nil
+}
+
+func (a *BasketApplication) createBasket(shopId int) error {
+	_, err := a.r.FindByShopId(shopId)
+
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return err
+	}
+
+	slog.Debug("Creating basket", "shopId", shopId)
+
+	b, err := basket.NewBasket(shopId)
+
+	if err != nil {
+		return err
+	}
+
+	return a.r.Save(b)
+}
+
+func (a *BasketApplication) freezeBasket(shopId int) error {
+	return freezeBasket(a.r, shopId)
+}
+
+// freezeBasket freezes a shop's basket, creating it frozen if the shop's
+// basket hasn't been created yet.
+func freezeBasket(r *basket.BasketRepository, shopId int) error {
+	b, err := r.FindByShopId(shopId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		b, err = basket.NewBasket(shopId)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("Freezing basket", "shopId", shopId)
+
+	b.Freeze()
+
+	return r.Save(b)
+}
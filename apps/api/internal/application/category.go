@@ -1,13 +1,166 @@
 package application
 
-import "github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+import (
+	"errors"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"log/slog"
+)
 
-type CategoryApplication struct{}
+type CategoryApplication struct {
+	r *category.CategoryRepository
+}
+
+func NewCategoryApplication(r *category.CategoryRepository) *CategoryApplication {
+	return &CategoryApplication{r: r}
+}
+
+type CategoryAlreadyExists struct {
+	CategoryName string
+}
+
+func (*CategoryAlreadyExists) Error() string {
+	return "category already exists"
+}
+
+type CategoryNotFound struct {
+	CategoryId category.CategoryName
+}
+
+func (*CategoryNotFound) Error() string {
+	return "category not found"
+}
+
+// GetCategories lists the categories in aisle order.
+func (a *CategoryApplication) GetCategories() ([]*category.Category, error) {
+	c, err := a.r.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Categories, nil
+}
+
+// GetAisles returns the categories as a whole, for ordering things by aisle.
+func (a *CategoryApplication) GetAisles() (*category.Categories, error) {
+	return a.r.Get()
+}
+
+func (a *CategoryApplication) AddCategory(id category.CategoryName, name string) (*category.Category, error) {
+	if err := validateId(string(id)); err != nil {
+		return nil, err
+	}
+
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	c, err := a.r.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.FindByName(name) != nil {
+		return nil, &CategoryAlreadyExists{CategoryName: name}
+	}
+
+	slog.Debug("Adding category", "id", id, "name", name)
+
+	if err := c.Create(id, name); err != nil {
+		if errors.Is(err, category.ErrCategoryExists) {
+			return nil, &CategoryAlreadyExists{CategoryName: string(id)}
+		}
 
-func NewCategoryApplication() *CategoryApplication {
-	return &CategoryApplication{}
+		return nil, err
+	}
+
+	if err := a.r.Save(c); err != nil {
+		return nil, err
+	}
+
+	return c.Find(id), nil
+}
+
+func (a *CategoryApplication) RenameCategory(id category.CategoryName, name string) (*category.Category, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	c, err := a.r.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing := c.FindByName(name); existing != nil && existing.Id != id {
+		return nil, &CategoryAlreadyExists{CategoryName: name}
+	}
+
+	slog.Debug("Renaming category", "id", id, "name", name)
+
+	if err := c.Rename(id, name); err != nil {
+		if errors.Is(err, category.ErrCategoryNotFound) {
+			return nil, &CategoryNotFound{CategoryId: id}
+		}
+
+		return nil, err
+	}
+
+	if err := a.r.Save(c); err != nil {
+		return nil, err
+	}
+
+	return c.Find(id), nil
 }
 
-func (a *CategoryApplication) GetCategories() []category.Category {
-	return category.Categories()
+// ReorderCategories sets the aisle order, which must list every category once.
+func (a *CategoryApplication) ReorderCategories(ids []category.CategoryName) ([]*category.Category, error) {
+	c, err := a.r.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Reordering categories", "ids", ids)
+
+	if err := c.Reorder(ids); err != nil {
+		if errors.Is(err, category.ErrInvalidOrder) {
+			return nil, &ValidationError{
+				Field:   "ids",
+				Message: err.Error(),
+			}
+		}
+
+		return nil, err
+	}
+
+	if err := a.r.Save(c); err != nil {
+		return nil, err
+	}
+
+	return c.Categories, nil
+}
+
+// validateCategory checks a product's category is one of the configured
+// categories.
+func validateCategory(r *category.CategoryRepository, id category.CategoryName) error {
+	if err := validateNotEmpty("category", string(id)); err != nil {
+		return err
+	}
+
+	c, err := r.Get()
+
+	if err != nil {
+		return err
+	}
+
+	if c.Find(id) == nil {
+		return &ValidationError{
+			Field:   "category",
+			Message: "category does not exist",
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,129 @@
+package application
+
+import (
+	"encoding/csv"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"io"
+)
+
+type ExportMealsApplication struct {
+	ProductRepository product.ProductRepository
+	MealRepository    meal.MealRepository
+}
+
+func NewExportMealsApplication(productRepository product.ProductRepository, mealRepository meal.MealRepository) *ExportMealsApplication {
+	return &ExportMealsApplication{
+		ProductRepository: productRepository,
+		MealRepository:    mealRepository,
+	}
+}
+
+// ExportedMeal is a meal with its ingredients' products given by name rather
+// than id, so it can be shared with other meal planners.
+type ExportedMeal struct {
+	Name        string               `json:"name"`
+	Url         string               `json:"url"`
+	Servings    int                  `json:"servings"`
+	Ingredients []ExportedIngredient `json:"ingredients"`
+	Steps       []string             `json:"steps,omitempty"`
+	PrepTime    int                  `json:"prepTime,omitempty"`
+	CookTime    int                  `json:"cookTime,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+}
+
+type ExportedIngredient struct {
+	Product  product.ProductName `json:"product"`
+	Quantity quantity.Quantity   `json:"quantity"`
+	Note     string              `json:"note,omitempty"`
+}
+
+func (a *ExportMealsApplication) ExportMeals() ([]ExportedMeal, error) {
+	meals, err := a.MealRepository.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]product.ProductName{}
+	exported := make([]ExportedMeal, 0, len(meals))
+
+	for _, m := range meals {
+		ingredients := make([]ExportedIngredient, 0, len(m.Ingredients))
+
+		for _, i := range m.Ingredients {
+			name, err := a.productName(names, i.ProductId)
+			if err != nil {
+				return nil, err
+			}
+
+			ingredients = append(ingredients, ExportedIngredient{Product: name, Quantity: i.Quantity, Note: i.Note})
+		}
+
+		exported = append(exported, ExportedMeal{
+			Name:        m.Name,
+			Url:         m.Url,
+			Servings:    m.Servings,
+			Ingredients: ingredients,
+			Steps:       m.Steps,
+			PrepTime:    m.PrepTime,
+			CookTime:    m.CookTime,
+			Tags:        m.Tags,
+		})
+	}
+
+	return exported, nil
+}
+
+// ExportMealsCsv writes meals in the format UploadMeals reads: a row per
+// ingredient of name, product, amount and unit. Meals without ingredients
+// have no rows, so are left out.
+func (a *ExportMealsApplication) ExportMealsCsv(w io.Writer) error {
+	meals, err := a.ExportMeals()
+	if err != nil {
+		return err
+	}
+
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write([]string{"name", "product", "amount", "unit"}); err != nil {
+		return err
+	}
+
+	for _, m := range meals {
+		for _, i := range m.Ingredients {
+			if err := csvWriter.Write([]string{m.Name, string(i.Product), i.Quantity.Amount.String(), i.Quantity.Unit.Name()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// productName looks up a product's name, remembering it for the meals still
+// to export. Products that no longer exist are given by their id.
+func (a *ExportMealsApplication) productName(names map[string]product.ProductName, id string) (product.ProductName, error) {
+	if name, ok := names[id]; ok {
+		return name, nil
+	}
+
+	p, err := a.ProductRepository.Find(id)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		names[id] = product.ProductName(id)
+		return names[id], nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	names[id] = p.Name
+
+	return p.Name, nil
+}
@@ -2,16 +2,24 @@ package application
 
 import (
 	"errors"
+	"github.com/hallgren/eventsourcing"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"log/slog"
+	"slices"
+	"strings"
 )
 
 type MealApplication struct {
-	r meal.MealRepository
+	r        meal.MealRepository
+	products product.ProductRepository
+	uow      *unitofwork.UnitOfWork
 }
 
-func NewMealApplication(r meal.MealRepository) *MealApplication {
-	return &MealApplication{r: r}
+func NewMealApplication(r meal.MealRepository, products product.ProductRepository, uow *unitofwork.UnitOfWork) *MealApplication {
+	return &MealApplication{r: r, products: products, uow: uow}
 }
 
 type MealAlreadyExists struct {
@@ -22,12 +30,45 @@ func (*MealAlreadyExists) Error() string {
 	return "meal already exists"
 }
 
+type MealNotFound struct {
+	MealId string
+}
+
+func (*MealNotFound) Error() string {
+	return "meal not found"
+}
+
+type IngredientNotInMeal struct {
+	IngredientId string
+}
+
+func (*IngredientNotInMeal) Error() string {
+	return "ingredient not in meal"
+}
+
+type PartialIngredient struct {
+	Quantity *quantity.Quantity `json:"quantity"`
+	Note     *string            `json:"note"`
+}
+
 type PartialMeal struct {
-	Name *string `json:"name"`
-	Url  *string `json:"url"`
+	Name     *string   `json:"name"`
+	Url      *string   `json:"url"`
+	Servings *int      `json:"servings"`
+	Steps    *[]string `json:"steps"`
+	PrepTime *int      `json:"prepTime"`
+	CookTime *int      `json:"cookTime"`
+	Tags     *[]string `json:"tags"`
 }
 
-func (a *MealApplication) AddMeal(id string, name string, url string, ingredients []meal.Ingredient) (*meal.Meal, error) {
+// MealFilter narrows down the meals listed. Meals must have every tag, and
+// with a MaxTime, take a known number of minutes no greater than it.
+type MealFilter struct {
+	Tags    []string
+	MaxTime int
+}
+
+func (a *MealApplication) AddMeal(id string, name string, url string, servings int, ingredients []meal.Ingredient) (*meal.Meal, error) {
 	err := validateId(id)
 	if err != nil {
 		return nil, err
@@ -38,6 +79,11 @@ func (a *MealApplication) AddMeal(id string, name string, url string, ingredient
 		return nil, err
 	}
 
+	err = validateServings(servings)
+	if err != nil {
+		return nil, err
+	}
+
 	existingMeal, err := a.r.FindByName(name)
 
 	if err != nil {
@@ -50,7 +96,7 @@ func (a *MealApplication) AddMeal(id string, name string, url string, ingredient
 		}
 	}
 
-	m, err := meal.NewMeal(id, name, url, ingredients)
+	m, err := meal.NewMeal(id, name, url, servings, ingredients)
 	if err != nil {
 		return nil, err
 	}
@@ -64,24 +110,52 @@ func (a *MealApplication) AddMeal(id string, name string, url string, ingredient
 	return m, nil
 }
 
+// BulkAddMeals saves all the meals or, if any can't be saved, none of them.
 func (a *MealApplication) BulkAddMeals(meals []*meal.Meal) ([]*meal.Meal, error) {
-	for _, m := range meals {
-		if err := a.r.Save(m); err != nil {
-			return nil, err
+	err := a.uow.Do(func(unit *unitofwork.Unit) error {
+		r := a.r.In(unit)
+
+		for _, m := range meals {
+			if err := r.Save(m); err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return meals, nil
 }
 
-func (a *MealApplication) GetMeals() ([]*meal.Meal, error) {
+func (a *MealApplication) GetMeals(filter MealFilter) ([]*meal.Meal, error) {
+	if filter.MaxTime < 0 {
+		return nil, &ValidationError{
+			Field:   "maxTime",
+			Message: "maxTime cannot be negative",
+		}
+	}
+
 	m, err := a.r.Get()
 
 	if err != nil {
 		return nil, err
 	}
 
-	return m, nil
+	tags := normaliseTags(filter.Tags)
+
+	return slices.DeleteFunc(m, func(m *meal.Meal) bool {
+		for _, tag := range tags {
+			if !m.HasTag(tag) {
+				return true
+			}
+		}
+
+		return filter.MaxTime > 0 && (m.TotalTime() == 0 || m.TotalTime() > filter.MaxTime)
+	}), nil
 }
 
 func (a *MealApplication) FindMeal(id string) (*meal.Meal, error) {
@@ -94,13 +168,30 @@ func (a *MealApplication) FindMeal(id string) (*meal.Meal, error) {
 	return m, nil
 }
 
-func (a *MealApplication) AddIngredientToMeal(mealId string, i meal.Ingredient) (*meal.Meal, error) {
+// AddIngredientToMeal adds a product to a meal. Without a quantity, the
+// product's default quantity is used.
+func (a *MealApplication) AddIngredientToMeal(mealId string, productId string, q *quantity.Quantity, note string) (*meal.Meal, error) {
+	if q != nil {
+		if err := validateQuantity(*q); err != nil {
+			return nil, err
+		}
+	}
+
 	m, err := a.r.Find(mealId)
 	if err != nil {
 		return nil, err
 	}
 
-	m.AddIngredient(i)
+	if q == nil {
+		d, err := a.defaultQuantity(productId)
+		if err != nil {
+			return nil, err
+		}
+
+		q = &d
+	}
+
+	m.AddIngredient(meal.Ingredient{ProductId: productId, Quantity: *q, Note: note})
 
 	if err := a.r.Save(m); err != nil {
 		return nil, err
@@ -111,11 +202,18 @@ func (a *MealApplication) AddIngredientToMeal(mealId string, i meal.Ingredient)
 
 func (a *MealApplication) RemoveIngredientFromMeal(mealId string, ingredientId string) (*meal.Meal, error) {
 	m, err := a.r.Find(mealId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, &MealNotFound{MealId: mealId}
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	m.RemoveIngredient(ingredientId)
+	if err := m.RemoveIngredient(ingredientId); errors.Is(err, meal.ErrIngredientNotFound) {
+		return nil, &IngredientNotInMeal{IngredientId: ingredientId}
+	}
 
 	if err := a.r.Save(m); err != nil {
 		return nil, err
@@ -124,6 +222,64 @@ func (a *MealApplication) RemoveIngredientFromMeal(mealId string, ingredientId s
 	return m, nil
 }
 
+func (a *MealApplication) UpdateIngredient(mealId string, ingredientId string, body PartialIngredient) (*meal.Meal, error) {
+	if body.Quantity != nil {
+		if err := validateQuantity(*body.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := a.r.Find(mealId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, &MealNotFound{MealId: mealId}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m.FindIngredient(ingredientId) == nil {
+		return nil, &IngredientNotInMeal{IngredientId: ingredientId}
+	}
+
+	slog.Debug("Updating ingredient", "mealId", mealId, "ingredientId", ingredientId)
+
+	if body.Quantity != nil {
+		if err := m.ChangeIngredientQuantity(ingredientId, *body.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	if body.Note != nil {
+		if err := m.ChangeIngredientNote(ingredientId, *body.Note); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.r.Save(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// defaultQuantity is how much of a product goes into a meal when no quantity
+// is given. Unknown products get a single one.
+func (a *MealApplication) defaultQuantity(productId string) (quantity.Quantity, error) {
+	p, err := a.products.Find(productId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return meal.NewIngredient(productId).Quantity, nil
+	}
+
+	if err != nil {
+		return quantity.Quantity{}, err
+	}
+
+	return p.IngredientQuantity(), nil
+}
+
 func (a *MealApplication) UpdateMeal(mealId string, body PartialMeal) (*meal.Meal, error) {
 	m, err := a.r.Find(mealId)
 	if err != nil {
@@ -138,9 +294,140 @@ func (a *MealApplication) UpdateMeal(mealId string, body PartialMeal) (*meal.Mea
 		m.UpdateUrl(*body.Url)
 	}
 
+	if body.Servings != nil {
+		if err := validateServings(*body.Servings); err != nil {
+			return nil, err
+		}
+
+		m.UpdateServings(*body.Servings)
+	}
+
+	if body.Steps != nil {
+		steps, err := validateSteps(*body.Steps)
+		if err != nil {
+			return nil, err
+		}
+
+		m.UpdateSteps(steps)
+	}
+
+	if body.PrepTime != nil || body.CookTime != nil {
+		prepTime, cookTime := m.PrepTime, m.CookTime
+
+		if body.PrepTime != nil {
+			prepTime = *body.PrepTime
+		}
+
+		if body.CookTime != nil {
+			cookTime = *body.CookTime
+		}
+
+		if err := validateTime("prepTime", prepTime); err != nil {
+			return nil, err
+		}
+
+		if err := validateTime("cookTime", cookTime); err != nil {
+			return nil, err
+		}
+
+		m.UpdateTimes(prepTime, cookTime)
+	}
+
+	if body.Tags != nil {
+		m.UpdateTags(normaliseTags(*body.Tags))
+	}
+
+	if err := a.r.Save(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (a *MealApplication) ArchiveMeal(mealId string) (*meal.Meal, error) {
+	m, err := a.r.Find(mealId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, &MealNotFound{MealId: mealId}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Archiving meal", "mealId", mealId)
+
+	m.Archive()
+
 	if err := a.r.Save(m); err != nil {
 		return nil, err
 	}
 
 	return m, nil
 }
+
+func validateServings(servings int) error {
+	if servings < 0 {
+		return &ValidationError{
+			Field:   "servings",
+			Message: "servings cannot be negative",
+		}
+	}
+	return nil
+}
+
+// validateSteps trims each step of a method, and rejects blank ones.
+func validateSteps(steps []string) ([]string, error) {
+	trimmed := make([]string, 0, len(steps))
+
+	for _, step := range steps {
+		step = strings.TrimSpace(step)
+
+		if step == "" {
+			return nil, &ValidationError{
+				Field:   "steps",
+				Message: "steps cannot be empty",
+			}
+		}
+
+		trimmed = append(trimmed, step)
+	}
+
+	return trimmed, nil
+}
+
+func validateTime(field string, minutes int) error {
+	if minutes < 0 {
+		return &ValidationError{
+			Field:   field,
+			Message: field + " cannot be negative",
+		}
+	}
+	return nil
+}
+
+// normaliseTags lower-cases and trims tags so "Vegetarian" and "vegetarian "
+// are the same tag, dropping blanks and duplicates.
+func normaliseTags(tags []string) []string {
+	normalised := []string{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+
+		if tag != "" && !slices.Contains(normalised, tag) {
+			normalised = append(normalised, tag)
+		}
+	}
+
+	return normalised
+}
+
+func validateQuantity(q quantity.Quantity) error {
+	if q.Amount.Cmp(quantity.NewAmount(0)) <= 0 {
+		return &ValidationError{
+			Field:   "amount",
+			Message: "amount must be greater than zero",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package application
+
+import (
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"log/slog"
+	"slices"
+)
+
+type MergeProductsApplication struct {
+	ProductRepository product.ProductRepository
+	MealRepository    meal.MealRepository
+	ShopRepository    *shop.ShopRepository
+	BasketRepository  *basket.BasketRepository
+	PantryRepository  *pantry.PantryRepository
+	UnitOfWork        *unitofwork.UnitOfWork
+}
+
+func NewMergeProductsApplication(
+	productRepository product.ProductRepository,
+	mealRepository meal.MealRepository,
+	shopRepository *shop.ShopRepository,
+	basketRepository *basket.BasketRepository,
+	pantryRepository *pantry.PantryRepository,
+	unitOfWork *unitofwork.UnitOfWork,
+) *MergeProductsApplication {
+	return &MergeProductsApplication{
+		ProductRepository: productRepository,
+		MealRepository:    mealRepository,
+		ShopRepository:    shopRepository,
+		BasketRepository:  basketRepository,
+		PantryRepository:  pantryRepository,
+		UnitOfWork:        unitOfWork,
+	}
+}
+
+// MergeProduct folds a duplicate product into another. Meal ingredients, the
+// items and basket of the current shop, and pantry stock are re-pointed at the
+// product being kept before the duplicate is archived. Completed shops are left as
+// they were. Either everything is merged or nothing is.
+func (a *MergeProductsApplication) MergeProduct(id string, intoId string) (*product.Product, error) {
+	if err := validateNotEmpty("into", intoId); err != nil {
+		return nil, err
+	}
+
+	if id == intoId {
+		return nil, &ValidationError{
+			Field:   "into",
+			Message: "cannot merge a product into itself",
+		}
+	}
+
+	p, err := findProduct(a.ProductRepository, id)
+	if err != nil {
+		return nil, err
+	}
+
+	into, err := findProduct(a.ProductRepository, intoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Archived || into.Archived {
+		return nil, product.ErrProductArchived
+	}
+
+	slog.Debug("Merging product", "productId", id, "into", intoId)
+
+	err = a.UnitOfWork.Do(func(unit *unitofwork.Unit) error {
+		return a.in(unit).merge(p, intoId)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// in returns a copy of the application whose repositories save as part of
+// the unit.
+func (a MergeProductsApplication) in(unit *unitofwork.Unit) *MergeProductsApplication {
+	a.ProductRepository = a.ProductRepository.In(unit)
+	a.MealRepository = a.MealRepository.In(unit)
+	a.ShopRepository = a.ShopRepository.In(unit)
+	a.BasketRepository = a.BasketRepository.In(unit)
+	a.PantryRepository = a.PantryRepository.In(unit)
+	return &a
+}
+
+func (a *MergeProductsApplication) merge(p *product.Product, intoId string) error {
+	if err := a.mergeMealIngredients(p.Id, intoId); err != nil {
+		return err
+	}
+
+	if err := a.mergeCurrentShop(p.Id, intoId); err != nil {
+		return err
+	}
+
+	if err := a.mergePantry(p.Id, intoId); err != nil {
+		return err
+	}
+
+	if err := p.MergeInto(intoId); err != nil {
+		return err
+	}
+
+	return a.ProductRepository.Save(p)
+}
+
+func (a *MergeProductsApplication) mergeMealIngredients(id string, intoId string) error {
+	ms, err := a.MealRepository.Get()
+	if err != nil {
+		return err
+	}
+
+	for _, listed := range ms {
+		if !slices.ContainsFunc(listed.Ingredients, func(i meal.Ingredient) bool { return i.ProductId == id }) {
+			continue
+		}
+
+		// Listed meals are read models, so load the aggregate before changing it.
+		m, err := a.MealRepository.Find(listed.Id)
+		if err != nil {
+			return err
+		}
+
+		for _, from := range slices.Clone(m.Ingredients) {
+			if from.ProductId != id {
+				continue
+			}
+
+			if err := m.RemoveIngredient(from.Id); err != nil {
+				return err
+			}
+
+			// Fold into a matching ingredient of the kept product, or keep
+			// the ingredient as its own line when none has the same note.
+			i := slices.IndexFunc(m.Ingredients, func(i meal.Ingredient) bool {
+				return i.ProductId == intoId && i.Note == from.Note
+			})
+
+			if i == -1 {
+				from.ProductId = intoId
+				m.AddIngredient(from)
+				continue
+			}
+
+			to := m.Ingredients[i]
+			if err := m.ChangeIngredientQuantity(to.Id, mergeQuantities(to.Quantity, from.Quantity)); err != nil {
+				return err
+			}
+		}
+
+		if err := a.MealRepository.Save(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *MergeProductsApplication) mergeCurrentShop(id string, intoId string) error {
+	s, err := a.ShopRepository.Current()
+	if err != nil {
+		return err
+	}
+
+	if s == nil || s.IsCompleted() {
+		return nil
+	}
+
+	var from, to *shop.Item
+
+	for _, item := range s.Items {
+		switch item.ProductId {
+		case id:
+			from = item
+		case intoId:
+			to = item
+		}
+	}
+
+	if from != nil {
+		q := from.Quantity
+
+		if to != nil {
+			q = mergeQuantities(to.Quantity, from.Quantity)
+
+			if err := s.RemoveItem(intoId); err != nil {
+				return err
+			}
+		}
+
+		if err := s.RemoveItem(id); err != nil {
+			return err
+		}
+
+		if err := s.AddItem(&shop.Item{ProductId: intoId, Quantity: q}); err != nil {
+			return err
+		}
+
+		if err := a.ShopRepository.Save(s); err != nil {
+			return err
+		}
+	}
+
+	return a.mergeBasket(s.Id, id, intoId)
+}
+
+func (a *MergeProductsApplication) mergeBasket(shopId int, id string, intoId string) error {
+	b, err := a.BasketRepository.FindByShopId(shopId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if b.Frozen {
+		return nil
+	}
+
+	found, hasInto := false, false
+
+	for _, item := range b.Items {
+		switch item.IngredientId {
+		case id:
+			found = true
+		case intoId:
+			hasInto = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	if err := b.RemoveItem(id); err != nil {
+		return err
+	}
+
+	if !hasInto {
+		if err := b.AddItem(basket.NewBasketItem(intoId)); err != nil {
+			return err
+		}
+	}
+
+	return a.BasketRepository.Save(b)
+}
+
+func (a *MergeProductsApplication) mergePantry(id string, intoId string) error {
+	p, err := a.PantryRepository.Get()
+	if err != nil {
+		return err
+	}
+
+	from := p.Stock(id)
+	if from == nil {
+		return nil
+	}
+
+	q := *from
+
+	if to := p.Stock(intoId); to != nil {
+		q = mergeQuantities(*to, q)
+	}
+
+	p.ClearStock(id)
+	p.SetStock(intoId, q)
+
+	return a.PantryRepository.Save(p)
+}
+
+// mergeQuantities adds the duplicate's quantity to the one being kept. When
+// the units can't be converted between, the kept quantity wins.
+func mergeQuantities(kept quantity.Quantity, duplicate quantity.Quantity) quantity.Quantity {
+	if !kept.CompatibleWith(duplicate) {
+		return kept
+	}
+
+	duplicate, err := quantity.Convert(duplicate, kept.Unit)
+	if err != nil {
+		return kept
+	}
+
+	return quantity.Quantity{Amount: kept.Amount.Add(duplicate.Amount), Unit: kept.Unit}
+}
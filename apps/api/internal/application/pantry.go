@@ -0,0 +1,83 @@
+package application
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"log/slog"
+)
+
+type PantryApplication struct {
+	r *pantry.PantryRepository
+}
+
+func NewPantryApplication(r *pantry.PantryRepository) *PantryApplication {
+	return &PantryApplication{r: r}
+}
+
+func (a *PantryApplication) GetPantry() (*pantry.Pantry, error) {
+	return a.r.Get()
+}
+
+func (a *PantryApplication) SetStock(productId string, q quantity.Quantity) (*pantry.Pantry, error) {
+	if q.Amount.Cmp(quantity.NewAmount(0)) < 0 {
+		return nil, &ValidationError{
+			Field:   "amount",
+			Message: "stock cannot be negative",
+		}
+	}
+
+	p, err := a.r.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Setting pantry stock", "productId", productId, "quantity", q)
+	p.SetStock(productId, q)
+
+	if err := a.r.Save(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (a *PantryApplication) AdjustStock(productId string, q quantity.Quantity) (*pantry.Pantry, error) {
+	p, err := a.r.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Adjusting pantry stock", "productId", productId, "quantity", q)
+
+	if err := p.AdjustStock(productId, q); err != nil {
+		return nil, &ValidationError{
+			Field:   "unit",
+			Message: err.Error(),
+		}
+	}
+
+	if err := a.r.Save(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (a *PantryApplication) ClearStock(productId string) (*pantry.Pantry, error) {
+	p, err := a.r.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Clearing pantry stock", "productId", productId)
+	p.ClearStock(productId)
+
+	if err := a.r.Save(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -1,17 +1,21 @@
 package application
 
 import (
+	"errors"
+	"github.com/hallgren/eventsourcing"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"log/slog"
 )
 
 type ProductApplication struct {
-	r product.ProductRepository
+	r          product.ProductRepository
+	categories *category.CategoryRepository
 }
 
-func NewProductApplication(r product.ProductRepository) *ProductApplication {
-	return &ProductApplication{r: r}
+func NewProductApplication(r product.ProductRepository, categories *category.CategoryRepository) *ProductApplication {
+	return &ProductApplication{r: r, categories: categories}
 }
 
 type ValidationError struct {
@@ -31,6 +35,20 @@ func (*ProductAlreadyExists) Error() string {
 	return "product already exists"
 }
 
+type ProductNotFound struct {
+	ProductId string
+}
+
+func (*ProductNotFound) Error() string {
+	return "product not found"
+}
+
+type PartialProduct struct {
+	Name            *product.ProductName   `json:"name"`
+	Category        *category.CategoryName `json:"category"`
+	DefaultQuantity *quantity.Quantity     `json:"defaultQuantity"`
+}
+
 func (a *ProductApplication) AddProduct(id string, name product.ProductName, category category.CategoryName) (*product.Product, error) {
 	err := validateId(id)
 	if err != nil {
@@ -42,6 +60,11 @@ func (a *ProductApplication) AddProduct(id string, name product.ProductName, cat
 		return nil, err
 	}
 
+	err = validateCategory(a.categories, category)
+	if err != nil {
+		return nil, err
+	}
+
 	existingProduct, err := a.r.FindByName(name)
 	if err != nil {
 		return nil, err
@@ -95,3 +118,90 @@ func (a *ProductApplication) GetProducts() ([]*product.Product, error) {
 
 	return ps, nil
 }
+
+func (a *ProductApplication) UpdateProduct(id string, body PartialProduct) (*product.Product, error) {
+	p, err := findProduct(a.r, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.Name != nil && *body.Name != p.Name {
+		if err := validateName(body.Name.String()); err != nil {
+			return nil, err
+		}
+
+		existingProduct, err := a.r.FindByName(*body.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if existingProduct != nil {
+			return nil, &ProductAlreadyExists{
+				ProductName: body.Name.String(),
+			}
+		}
+
+		if err := p.Rename(*body.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if body.Category != nil {
+		if err := validateCategory(a.categories, *body.Category); err != nil {
+			return nil, err
+		}
+
+		if err := p.ChangeCategory(*body.Category); err != nil {
+			return nil, err
+		}
+	}
+
+	if body.DefaultQuantity != nil {
+		if err := validateQuantity(*body.DefaultQuantity); err != nil {
+			return nil, err
+		}
+
+		if err := p.SetDefaultQuantity(*body.DefaultQuantity); err != nil {
+			return nil, err
+		}
+	}
+
+	slog.Debug("Updating product", "product", p)
+
+	if err := a.r.Save(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (a *ProductApplication) ArchiveProduct(id string) (*product.Product, error) {
+	p, err := findProduct(a.r, id)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Archiving product", "productId", id)
+
+	p.Archive()
+
+	if err := a.r.Save(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func findProduct(r product.ProductRepository, id string) (*product.Product, error) {
+	p, err := r.Find(id)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, &ProductNotFound{ProductId: id}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
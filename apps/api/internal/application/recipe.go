@@ -0,0 +1,195 @@
+package application
+
+import (
+	"errors"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/recipe"
+	"io"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type RecipeApplication struct {
+	products product.ProductRepository
+	fetcher  recipe.Fetcher
+}
+
+func NewRecipeApplication(products product.ProductRepository, fetcher recipe.Fetcher) *RecipeApplication {
+	return &RecipeApplication{products: products, fetcher: fetcher}
+}
+
+type RecipeNotFound struct {
+	Url string
+}
+
+func (*RecipeNotFound) Error() string {
+	return "no recipe found"
+}
+
+type RecipeNotFetched struct {
+	Url string
+	Err error
+}
+
+func (*RecipeNotFetched) Error() string {
+	return "could not fetch recipe"
+}
+
+func (e *RecipeNotFetched) Unwrap() error {
+	return e.Err
+}
+
+// MealProposal is a meal read from a recipe, for checking before it's added.
+// Ingredients whose product couldn't be matched are flagged and listed in
+// NotFoundProducts, as they are for uploads.
+type MealProposal struct {
+	Name             string                `json:"name"`
+	Url              string                `json:"url"`
+	Servings         int                   `json:"servings"`
+	Ingredients      []ProposedIngredient  `json:"ingredients"`
+	Steps            []string              `json:"steps"`
+	PrepTime         int                   `json:"prepTime"`
+	CookTime         int                   `json:"cookTime"`
+	Tags             []string              `json:"tags"`
+	NotFoundProducts []product.ProductName `json:"notFoundProducts"`
+}
+
+type ProposedIngredient struct {
+	Line      string            `json:"line"`
+	Product   string            `json:"product"`
+	ProductId string            `json:"productId,omitempty"`
+	Matched   bool              `json:"matched"`
+	Quantity  quantity.Quantity `json:"quantity"`
+	Note      string            `json:"note,omitempty"`
+}
+
+// ImportRecipe proposes a meal from a recipe page's HTML. The url is where
+// the page came from, and is used over the one the recipe gives.
+func (a *RecipeApplication) ImportRecipe(src io.Reader, url string) (*MealProposal, error) {
+	r, err := recipe.Extract(src)
+
+	if errors.Is(err, recipe.ErrRecipeNotFound) {
+		return nil, &RecipeNotFound{Url: url}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if url == "" {
+		url = r.Url
+	}
+
+	products, err := a.products.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := &MealProposal{
+		Name:             r.Name,
+		Url:              url,
+		Servings:         r.Servings,
+		Ingredients:      []ProposedIngredient{},
+		Steps:            r.Steps,
+		PrepTime:         r.PrepTime,
+		CookTime:         r.CookTime,
+		Tags:             normaliseTags(r.Tags),
+		NotFoundProducts: []product.ProductName{},
+	}
+
+	for _, text := range r.Ingredients {
+		line := recipe.ParseLine(text)
+		ingredient := ProposedIngredient{Line: text, Product: line.Product, Note: line.Note}
+
+		if p := matchProduct(products, line.Product); p != nil {
+			ingredient.ProductId = p.Id
+			ingredient.Product = string(p.Name)
+			ingredient.Matched = true
+			ingredient.Quantity = p.IngredientQuantity()
+		} else {
+			ingredient.Quantity = meal.NewIngredient("").Quantity
+
+			if name := product.ProductName(line.Product); name != "" && !slices.Contains(proposal.NotFoundProducts, name) {
+				proposal.NotFoundProducts = append(proposal.NotFoundProducts, name)
+			}
+		}
+
+		if line.Quantity != nil {
+			ingredient.Quantity = *line.Quantity
+		}
+
+		proposal.Ingredients = append(proposal.Ingredients, ingredient)
+	}
+
+	slog.Debug("Imported recipe", "url", url, "name", proposal.Name, "notFoundProducts", proposal.NotFoundProducts)
+
+	return proposal, nil
+}
+
+// ImportRecipeFromUrl fetches a recipe page and proposes a meal from it.
+func (a *RecipeApplication) ImportRecipeFromUrl(recipeUrl string) (*MealProposal, error) {
+	if u, err := url.Parse(recipeUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, &ValidationError{
+			Field:   "url",
+			Message: "url must be an http or https address",
+		}
+	}
+
+	src, err := a.fetcher.Fetch(recipeUrl)
+	if errors.Is(err, recipe.ErrAddressNotAllowed) {
+		return nil, &ValidationError{
+			Field:   "url",
+			Message: "url must be a public address",
+		}
+	}
+
+	if err != nil {
+		return nil, &RecipeNotFetched{Url: recipeUrl, Err: err}
+	}
+
+	defer src.Close()
+
+	return a.ImportRecipe(src, recipeUrl)
+}
+
+// matchProduct finds the product an ingredient line names, ignoring case and
+// plurals, so "large onions" matches "Onion". The longest name wins, so "red
+// onions" matches "Red onion" over "Onion".
+func matchProduct(products []*product.Product, name string) *product.Product {
+	words := " " + singular(name) + " "
+
+	var match *product.Product
+	matchLength := 0
+
+	for _, p := range products {
+		n := singular(string(p.Name))
+
+		if n != "" && len(n) > matchLength && strings.Contains(words, " "+n+" ") {
+			match = p
+			matchLength = len(n)
+		}
+	}
+
+	return match
+}
+
+func singular(s string) string {
+	words := strings.Fields(strings.ToLower(s))
+
+	for i, w := range words {
+		switch {
+		case strings.HasSuffix(w, "ies") && len(w) > 4:
+			words[i] = strings.TrimSuffix(w, "ies") + "y"
+		case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "xes"):
+			words[i] = strings.TrimSuffix(w, "es")
+		case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+			words[i] = strings.TrimSuffix(w, "s")
+		}
+	}
+
+	return strings.Join(words, " ")
+}
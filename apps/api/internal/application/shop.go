@@ -1,25 +1,119 @@
 package application
 
 import (
+	"errors"
 	"fmt"
+	"github.com/hallgren/eventsourcing"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"log/slog"
+	"sort"
+	"time"
 )
 
 type ShopApplication struct {
-	r         *shop.ShopRepository
-	Publisher func(string)
+	r       *shop.ShopRepository
+	meals   meal.MealRepository
+	stores  *store.StoreRepository
+	baskets *basket.BasketRepository
+	uow     *unitofwork.UnitOfWork
 }
 
-func NewShopApplication(r *shop.ShopRepository, p func(string)) *ShopApplication {
-	return &ShopApplication{r: r, Publisher: p}
+func NewShopApplication(r *shop.ShopRepository, meals meal.MealRepository, stores *store.StoreRepository, baskets *basket.BasketRepository, uow *unitofwork.UnitOfWork) *ShopApplication {
+	return &ShopApplication{r: r, meals: meals, stores: stores, baskets: baskets, uow: uow}
+}
+
+type MealNotInShop struct {
+	MealId string
+}
+
+func (*MealNotInShop) Error() string {
+	return "meal not in shop"
+}
+
+type ShopNotFound struct {
+	ShopId int
+}
+
+func (*ShopNotFound) Error() string {
+	return "shop not found"
+}
+
+type ShopsPage struct {
+	Shops    []*shop.Summary `json:"shops"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"pageSize"`
+	Total    int             `json:"total"`
+}
+
+func (a *ShopApplication) GetShops(page int, pageSize int) (*ShopsPage, error) {
+	if page < 1 {
+		return nil, &ValidationError{
+			Field:   "page",
+			Message: "page must be at least 1",
+		}
+	}
+
+	if pageSize < 1 || pageSize > 100 {
+		return nil, &ValidationError{
+			Field:   "pageSize",
+			Message: "pageSize must be between 1 and 100",
+		}
+	}
+
+	shops, total, err := a.r.List((page-1)*pageSize, pageSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShopsPage{Shops: shops, Page: page, PageSize: pageSize, Total: total}, nil
+}
+
+func (a *ShopApplication) GetShop(id int) (*shop.Shop, error) {
+	s, err := a.r.Find(id)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, &ShopNotFound{ShopId: id}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
 func (a *ShopApplication) GetCurrentShop() (*shop.Shop, error) {
 	return a.r.Current()
 }
 
-func (a *ShopApplication) StartShop() (*shop.Shop, error) {
+func (a *ShopApplication) GetCurrentShopWeek(date time.Time) (*shop.Week, error) {
+	s, err := a.r.Current()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("no current shop")
+	}
+
+	return s.Week(date), nil
+}
+
+// StartShop starts a new shop, optionally at one of the stores.
+func (a *ShopApplication) StartShop(storeId string) (*shop.Shop, error) {
+	if storeId != "" {
+		if _, err := findStore(a.stores, storeId); err != nil {
+			return nil, err
+		}
+	}
+
 	s, err := a.r.Current()
 	if err != nil {
 		return nil, err
@@ -28,9 +122,9 @@ func (a *ShopApplication) StartShop() (*shop.Shop, error) {
 	var newShop *shop.Shop
 
 	if s == nil {
-		newShop, err = shop.NewShop(1)
+		newShop, err = shop.NewShopAtStore(1, storeId)
 	} else {
-		newShop, err = shop.NewShop(s.Id + 1)
+		newShop, err = shop.NewShopAtStore(s.Id+1, storeId)
 	}
 
 	if err != nil {
@@ -43,8 +137,6 @@ func (a *ShopApplication) StartShop() (*shop.Shop, error) {
 		return nil, err
 	}
 
-	a.Publisher(fmt.Sprintf("shopStarted:%d", newShop.Id))
-
 	return newShop, nil
 }
 
@@ -59,8 +151,30 @@ func (a *ShopApplication) AddMealToCurrentShop(shopMeal *shop.ShopMeal) (*shop.S
 		return nil, fmt.Errorf("no current shop")
 	}
 
+	if shopMeal.Schedule != nil {
+		if err := validateDate(shopMeal.Schedule.Date); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := a.meals.Find(shopMeal.MealId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, &MealNotFound{MealId: shopMeal.MealId}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Archived {
+		return nil, meal.ErrMealArchived
+	}
+
 	slog.Debug("Adding meal to shop", "shopId", s.Id, "mealId", shopMeal.MealId)
-	s.AddMeal(shopMeal)
+	if err := s.AddMeal(shopMeal); err != nil {
+		return nil, err
+	}
 
 	if err := a.r.Save(s); err != nil {
 		return nil, err
@@ -81,7 +195,9 @@ func (a *ShopApplication) RemoveMealFromCurrentShop(mealId string) (*shop.Shop,
 	}
 
 	slog.Debug("Removing meal from shop", "shopId", s.Id, "mealId", mealId)
-	s.RemoveMeal(mealId)
+	if err := s.RemoveMeal(mealId); err != nil {
+		return nil, err
+	}
 
 	if err := a.r.Save(s); err != nil {
 		return nil, err
@@ -90,6 +206,88 @@ func (a *ShopApplication) RemoveMealFromCurrentShop(mealId string) (*shop.Shop,
 	return s, nil
 }
 
+func (a *ShopApplication) ScheduleMealInCurrentShop(mealId string, schedule shop.Schedule) (*shop.Shop, error) {
+	if err := validateDate(schedule.Date); err != nil {
+		return nil, err
+	}
+
+	if err := validateSlot(schedule.Slot); err != nil {
+		return nil, err
+	}
+
+	s, err := a.r.Current()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("no current shop")
+	}
+
+	if !s.HasMeal(mealId) {
+		return nil, &MealNotInShop{MealId: mealId}
+	}
+
+	slog.Debug("Scheduling meal in shop", "shopId", s.Id, "mealId", mealId, "schedule", schedule)
+	if err := s.ScheduleMeal(mealId, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := a.r.Save(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (a *ShopApplication) UnscheduleMealInCurrentShop(mealId string) (*shop.Shop, error) {
+	s, err := a.r.Current()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("no current shop")
+	}
+
+	if !s.HasMeal(mealId) {
+		return nil, &MealNotInShop{MealId: mealId}
+	}
+
+	slog.Debug("Unscheduling meal in shop", "shopId", s.Id, "mealId", mealId)
+	if err := s.UnscheduleMeal(mealId); err != nil {
+		return nil, err
+	}
+
+	if err := a.r.Save(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func validateDate(date string) error {
+	if _, err := shop.ParseDate(date); err != nil {
+		return &ValidationError{
+			Field:   "date",
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func validateSlot(slot shop.Slot) error {
+	if slot < shop.Breakfast || slot > shop.Dinner {
+		return &ValidationError{
+			Field:   "slot",
+			Message: "slot must be Breakfast, Lunch or Dinner",
+		}
+	}
+	return nil
+}
+
 func (a *ShopApplication) AddItemToCurrentShop(item *shop.Item) (*shop.Shop, error) {
 	s, err := a.r.Current()
 
@@ -102,7 +300,9 @@ func (a *ShopApplication) AddItemToCurrentShop(item *shop.Item) (*shop.Shop, err
 	}
 
 	slog.Debug("Adding item to shop", "shopId", s.Id, "item", item)
-	s.AddItem(item)
+	if err := s.AddItem(item); err != nil {
+		return nil, err
+	}
 
 	if err := a.r.Save(s); err != nil {
 		return nil, err
@@ -123,7 +323,9 @@ func (a *ShopApplication) RemoveItemFromCurrentShop(productId string) (*shop.Sho
 	}
 
 	slog.Debug("Removing item from shop", "shopId", s.Id, "productId", productId)
-	s.RemoveItem(productId)
+	if err := s.RemoveItem(productId); err != nil {
+		return nil, err
+	}
 
 	if err := a.r.Save(s); err != nil {
 		return nil, err
@@ -131,3 +333,55 @@ func (a *ShopApplication) RemoveItemFromCurrentShop(productId string) (*shop.Sho
 
 	return s, nil
 }
+
+// CompleteCurrentShop finishes the current shop, recording which items on its
+// shopping list made it into the basket. Items the pantry covered are recorded
+// as such rather than as abandoned, unless they were bought anyway. The basket
+// is frozen along with the shop, so nothing more can be put in it.
+func (a *ShopApplication) CompleteCurrentShop(shoppingList map[string]shoppinglist.ShoppingListItem) (*shop.Shop, error) {
+	s, err := a.r.Current()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("no current shop")
+	}
+
+	bought := []string{}
+	abandoned := []string{}
+	covered := []string{}
+	for productId, item := range shoppingList {
+		switch {
+		case item.IsInBasket:
+			bought = append(bought, productId)
+		case item.Covered:
+			covered = append(covered, productId)
+		default:
+			abandoned = append(abandoned, productId)
+		}
+	}
+	sort.Strings(bought)
+	sort.Strings(abandoned)
+	sort.Strings(covered)
+
+	slog.Debug("Completing shop", "shopId", s.Id, "bought", bought, "abandoned", abandoned, "covered", covered)
+	if err := s.Complete(bought, abandoned, covered); err != nil {
+		return nil, err
+	}
+
+	err = a.uow.Do(func(unit *unitofwork.Unit) error {
+		if err := a.r.In(unit).Save(s); err != nil {
+			return err
+		}
+
+		return freezeBasket(a.baskets.In(unit), s.Id)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,248 @@
+package application
+
+import (
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"log/slog"
+	"slices"
+)
+
+type StoreApplication struct {
+	r          *store.StoreRepository
+	categories *category.CategoryRepository
+	products   product.ProductRepository
+	shops      *shop.ShopRepository
+}
+
+func NewStoreApplication(
+	r *store.StoreRepository,
+	categories *category.CategoryRepository,
+	products product.ProductRepository,
+	shops *shop.ShopRepository,
+) *StoreApplication {
+	return &StoreApplication{r: r, categories: categories, products: products, shops: shops}
+}
+
+type StoreAlreadyExists struct {
+	StoreName string
+}
+
+func (*StoreAlreadyExists) Error() string {
+	return "store already exists"
+}
+
+type StoreNotFound struct {
+	StoreId string
+}
+
+func (*StoreNotFound) Error() string {
+	return "store not found"
+}
+
+type PartialStore struct {
+	Name   *string                  `json:"name"`
+	Layout *[]category.CategoryName `json:"layout"`
+}
+
+func (a *StoreApplication) GetStores() ([]*store.Store, error) {
+	return a.r.Get()
+}
+
+func (a *StoreApplication) GetStore(id string) (*store.Store, error) {
+	return findStore(a.r, id)
+}
+
+func (a *StoreApplication) AddStore(id string, name string, layout []category.CategoryName) (*store.Store, error) {
+	if err := validateId(id); err != nil {
+		return nil, err
+	}
+
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	if err := a.validateLayout(layout); err != nil {
+		return nil, err
+	}
+
+	if err := a.validateUniqueName(id, name); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.r.Find(id); err == nil {
+		return nil, &StoreAlreadyExists{StoreName: name}
+	} else if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, err
+	}
+
+	slog.Debug("Adding store", "id", id, "name", name)
+
+	s, err := store.NewStore(id, name, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.r.Save(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (a *StoreApplication) UpdateStore(id string, update PartialStore) (*store.Store, error) {
+	s, err := findStore(a.r, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		if err := validateName(*update.Name); err != nil {
+			return nil, err
+		}
+
+		if err := a.validateUniqueName(id, *update.Name); err != nil {
+			return nil, err
+		}
+
+		s.Rename(*update.Name)
+	}
+
+	if update.Layout != nil {
+		if err := a.validateLayout(*update.Layout); err != nil {
+			return nil, err
+		}
+
+		s.ChangeLayout(*update.Layout)
+	}
+
+	slog.Debug("Updating store", "id", id)
+
+	if err := a.r.Save(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// LocateProduct moves a product into another category's aisle at one store.
+func (a *StoreApplication) LocateProduct(id string, productId string, c category.CategoryName) (*store.Store, error) {
+	s, err := findStore(a.r, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findProduct(a.products, productId); err != nil {
+		return nil, err
+	}
+
+	if err := validateCategory(a.categories, c); err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Locating product in store", "storeId", id, "productId", productId, "category", c)
+
+	s.LocateProduct(productId, c)
+
+	if err := a.r.Save(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (a *StoreApplication) ClearProductLocation(id string, productId string) (*store.Store, error) {
+	s, err := findStore(a.r, id)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Clearing product location in store", "storeId", id, "productId", productId)
+
+	s.ClearProductLocation(productId)
+
+	if err := a.r.Save(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetShopStore returns the store a shop was started at, or nil when there's
+// no such shop or it wasn't started at one.
+func (a *StoreApplication) GetShopStore(shopId int) (*store.Store, error) {
+	s, err := a.shops.Find(shopId)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.StoreId == "" {
+		return nil, nil
+	}
+
+	return findStore(a.r, s.StoreId)
+}
+
+func (a *StoreApplication) validateUniqueName(id string, name string) error {
+	stores, err := a.r.Get()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stores {
+		if s.Name == name && s.Id != id {
+			return &StoreAlreadyExists{StoreName: name}
+		}
+	}
+
+	return nil
+}
+
+// validateLayout checks a layout only lists configured categories, each at
+// most once. It doesn't need to list them all.
+func (a *StoreApplication) validateLayout(layout []category.CategoryName) error {
+	c, err := a.categories.Get()
+	if err != nil {
+		return err
+	}
+
+	for i, id := range layout {
+		if c.Find(id) == nil {
+			return &ValidationError{
+				Field:   "layout",
+				Message: "category does not exist",
+			}
+		}
+
+		if slices.Contains(layout[:i], id) {
+			return &ValidationError{
+				Field:   "layout",
+				Message: "category listed more than once",
+			}
+		}
+	}
+
+	return nil
+}
+
+func findStore(r *store.StoreRepository, id string) (*store.Store, error) {
+	s, err := r.Find(id)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, &StoreNotFound{StoreId: id}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
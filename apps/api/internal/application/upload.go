@@ -1,143 +1,503 @@
 package application
 
 import (
-	"bytes"
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"io"
 	"log/slog"
-	"strconv"
+	"strings"
 )
 
 type UploadMealsApplication struct {
-	ProductRepository *product.EventSourcedProductRepository
-	MealRepository    *meal.EventSourcedMealRepository
+	ProductRepository  *product.EventSourcedProductRepository
+	MealRepository     *meal.EventSourcedMealRepository
+	CategoryRepository *category.CategoryRepository
+	UnitOfWork         *unitofwork.UnitOfWork
 }
 
-func NewUploadMealsApplication(productRepository *product.EventSourcedProductRepository, mealRepository *meal.EventSourcedMealRepository) *UploadMealsApplication {
+func NewUploadMealsApplication(
+	productRepository *product.EventSourcedProductRepository,
+	mealRepository *meal.EventSourcedMealRepository,
+	categoryRepository *category.CategoryRepository,
+	unitOfWork *unitofwork.UnitOfWork,
+) *UploadMealsApplication {
 	return &UploadMealsApplication{
-		ProductRepository: productRepository,
-		MealRepository:    mealRepository,
+		ProductRepository:  productRepository,
+		MealRepository:     mealRepository,
+		CategoryRepository: categoryRepository,
+		UnitOfWork:         unitOfWork,
 	}
 }
 
+// ProductsNotFound lists the products named in an upload or a recipe that
+// don't exist yet.
 type ProductsNotFound struct {
-	NotFoundProducts []product.ProductName
+	NotFoundProducts []product.ProductName `json:"notFoundProducts"`
 }
 
 func (*ProductsNotFound) Error() string {
 	return "products not found"
 }
 
-func (a *UploadMealsApplication) UploadMeals(src io.Reader) error {
-	ms, notFoundProducts, err := a.parseMeals(src)
+// UploadMode is what to do with uploaded meals that have the same name as an
+// existing meal.
+type UploadMode string
+
+const (
+	// CreateMeals rejects the whole upload.
+	CreateMeals UploadMode = "create"
+	// UpdateMeals changes the existing meal to match the upload.
+	UpdateMeals UploadMode = "update"
+	// SkipMeals leaves the existing meal as it is.
+	SkipMeals UploadMode = "skip"
+)
+
+type UploadOptions struct {
+	Mode UploadMode
+	// DryRun reports what an upload would do without saving anything.
+	DryRun bool
+	// AutoCreateProducts adds products the upload uses that don't exist yet,
+	// in the category given in the CSV's optional category column.
+	AutoCreateProducts bool
+}
+
+type UploadAction string
+
+const (
+	MealCreated   UploadAction = "create"
+	MealUpdated   UploadAction = "update"
+	MealUnchanged UploadAction = "unchanged"
+	MealSkipped   UploadAction = "skip"
+	MealRejected  UploadAction = "reject"
+)
+
+type UploadReport struct {
+	DryRun           bool                  `json:"dryRun"`
+	Meals            []UploadedMealReport  `json:"meals"`
+	NotFoundProducts []product.ProductName `json:"notFoundProducts"`
+	CreatedProducts  []*product.Product    `json:"createdProducts"`
+}
+
+// UploadedMealReport is what an upload did, or would do, with the meal on the
+// given rows of the CSV, numbered by their line in the file.
+type UploadedMealReport struct {
+	Rows    []int             `json:"rows"`
+	Name    string            `json:"name"`
+	MealId  string            `json:"mealId,omitempty"`
+	Action  UploadAction      `json:"action"`
+	Reason  string            `json:"reason,omitempty"`
+	Added   []meal.Ingredient `json:"added,omitempty"`
+	Removed []meal.Ingredient `json:"removed,omitempty"`
+	Changed []meal.Ingredient `json:"changed,omitempty"`
+}
+
+// UploadRowError is a problem with a row of an upload. Rows are numbered by
+// their line in the file, with the header on line 1.
+type UploadRowError struct {
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// InvalidUpload lists every problem with an upload's rows, so they can all be
+// fixed at once.
+type InvalidUpload struct {
+	Errors []UploadRowError
+}
+
+func (*InvalidUpload) Error() string {
+	return "invalid upload"
+}
+
+// uploadedMeal is a meal read from consecutive rows of an uploaded CSV.
+type uploadedMeal struct {
+	name             string
+	rows             []int
+	ingredients      []meal.Ingredient
+	notFoundProducts []product.ProductName
+}
+
+// UploadMeals adds the meals in a CSV of name, product, amount, unit and
+// optionally category. An uploaded meal matches an existing one with the same
+// name, and what happens to it then depends on the mode. Nothing is saved
+// unless every meal in the upload can be, and the meals and any products
+// created for them are saved in a single transaction.
+func (a *UploadMealsApplication) UploadMeals(src io.Reader, options UploadOptions) (*UploadReport, error) {
+	if options.Mode == "" {
+		options.Mode = CreateMeals
+	}
+
+	if options.Mode != CreateMeals && options.Mode != UpdateMeals && options.Mode != SkipMeals {
+		return nil, &ValidationError{
+			Field:   "mode",
+			Message: "mode must be create, update or skip",
+		}
+	}
+
+	uploaded, notFoundProducts, createdProducts, err := a.parseMeals(src, options)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if len(notFoundProducts) > 0 {
-		return &ProductsNotFound{
+	if len(notFoundProducts) > 0 && !options.DryRun {
+		return nil, &ProductsNotFound{
 			NotFoundProducts: notFoundProducts,
 		}
 	}
 
-	for _, m := range ms {
-		m, _ := a.MealRepository.FindByName(m.Name)
+	existing, err := a.existingMeals()
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UploadReport{
+		DryRun:           options.DryRun,
+		Meals:            []UploadedMealReport{},
+		NotFoundProducts: notFoundProducts,
+		CreatedProducts:  createdProducts,
+	}
+
+	if report.NotFoundProducts == nil {
+		report.NotFoundProducts = []product.ProductName{}
+	}
+
+	if report.CreatedProducts == nil {
+		report.CreatedProducts = []*product.Product{}
+	}
+
+	var changed []*meal.Meal
+
+	for _, u := range uploaded {
+		r := UploadedMealReport{Rows: u.rows, Name: u.name}
+
+		m, err := a.uploadMeal(u, existing[u.name], options, &r)
+
+		if err != nil {
+			return nil, err
+		}
 
 		if m != nil {
-			return &MealAlreadyExists{
-				MealName: m.Name,
-			}
+			changed = append(changed, m)
 		}
+
+		report.Meals = append(report.Meals, r)
+	}
+
+	if options.DryRun {
+		return report, nil
 	}
 
-	slog.Info("uploading meals", "meals", ms)
+	slog.Info("uploading meals", "mode", options.Mode, "meals", len(changed), "createdProducts", len(createdProducts))
 
-	for _, m := range ms {
-		if err := a.MealRepository.Save(m); err != nil {
-			return err
+	err = a.UnitOfWork.Do(func(unit *unitofwork.Unit) error {
+		products, meals := a.ProductRepository.In(unit), a.MealRepository.In(unit)
+
+		for _, p := range createdProducts {
+			if err := products.Add(p); err != nil {
+				return err
+			}
+		}
+
+		for _, m := range changed {
+			if err := meals.Save(m); err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return report, nil
+}
+
+// uploadMeal works out what to do with an uploaded meal, filling in its
+// report, and returns the meal to save if there are changes to it.
+func (a *UploadMealsApplication) uploadMeal(u uploadedMeal, existing *meal.Meal, options UploadOptions, r *UploadedMealReport) (*meal.Meal, error) {
+	if len(u.notFoundProducts) > 0 {
+		r.Action = MealRejected
+		r.Reason = (&ProductsNotFound{}).Error()
+		return nil, nil
+	}
+
+	if existing == nil {
+		m := meal.NewMealBuilder().WithName(u.name).AddIngredients(u.ingredients).Build()
+
+		r.MealId = m.Id
+		r.Action = MealCreated
+		r.Added = m.Ingredients
+
+		return m, nil
+	}
+
+	r.MealId = existing.Id
+
+	switch options.Mode {
+	case SkipMeals:
+		r.Action = MealSkipped
+		return nil, nil
+	case UpdateMeals:
+		m, err := a.MealRepository.Find(existing.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Added, r.Removed, r.Changed = m.ReplaceIngredients(u.ingredients)
+
+		if len(m.Events()) == 0 {
+			r.Action = MealUnchanged
+			return nil, nil
+		}
+
+		r.Action = MealUpdated
+		return m, nil
+	default:
+		if !options.DryRun {
+			return nil, &MealAlreadyExists{
+				MealName: existing.Name,
+			}
+		}
+
+		r.Action = MealRejected
+		r.Reason = (&MealAlreadyExists{}).Error()
+		return nil, nil
+	}
 }
 
-func (a *UploadMealsApplication) parseMeals(src io.Reader) (meals []*meal.Meal, notFoundProducts []product.ProductName, err error) {
-	var buf bytes.Buffer
-	_, err = buf.ReadFrom(src)
+func (a *UploadMealsApplication) existingMeals() (map[string]*meal.Meal, error) {
+	meals, err := a.MealRepository.Get()
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	var m *meal.Meal
+	byName := make(map[string]*meal.Meal, len(meals))
 
-	csvReader := csv.NewReader(&buf)
-	records, err := csvReader.ReadAll()
+	for _, m := range meals {
+		byName[m.Name] = m
+	}
+
+	return byName, nil
+}
+
+// parseMeals reads the meals in an upload. Products it can't find are
+// created if the options allow and the row gives a category, and otherwise
+// reported as not found. Every invalid row is reported, rather than just the
+// first.
+func (a *UploadMealsApplication) parseMeals(src io.Reader, options UploadOptions) (meals []uploadedMeal, notFoundProducts []product.ProductName, createdProducts []*product.Product, err error) {
+	categories, err := a.CategoryRepository.Get()
 
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	for i, record := range records {
-		if i == 0 {
-			if record[0] != "name" || record[1] != "product" || record[2] != "amount" || record[3] != "unit" {
-				return nil, nil, errors.New("invalid csv header")
+	csvReader := csv.NewReader(src)
+	csvReader.FieldsPerRecord = -1
+
+	var header []string
+	var rowErrors []UploadRowError
+	var m *uploadedMeal
+
+	firstRows := map[string]int{}
+	created := map[product.ProductName]*product.Product{}
+
+	for {
+		record, err := csvReader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			rowErrors = append(rowErrors, UploadRowError{Row: parseError.StartLine, Reason: parseError.Err.Error()})
+
+			if header == nil {
+				return nil, nil, nil, &InvalidUpload{Errors: rowErrors}
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		row, _ := csvReader.FieldPos(0)
+
+		if header == nil {
+			if headerErrors := validateHeader(row, record); len(headerErrors) > 0 {
+				return nil, nil, nil, &InvalidUpload{Errors: headerErrors}
 			}
 
+			header = record
 			continue
 		}
 
-		if len(record) != 4 {
-			return nil, nil, errors.New("invalid csv row")
+		if len(record) != len(header) {
+			rowErrors = append(rowErrors, UploadRowError{
+				Row:    row,
+				Reason: fmt.Sprintf("expected %d columns, found %d", len(header), len(record)),
+			})
+			continue
 		}
 
-		mealName := record[0]
+		errorCount := len(rowErrors)
+		invalid := func(column string, value string, reason string) {
+			rowErrors = append(rowErrors, UploadRowError{Row: row, Column: column, Value: value, Reason: reason})
+		}
+
+		mealName := strings.TrimSpace(record[0])
+
+		if mealName == "" {
+			invalid("name", record[0], "name cannot be empty")
+		} else if m == nil || mealName != m.name {
+			if first, ok := firstRows[mealName]; ok {
+				invalid("name", mealName, fmt.Sprintf("rows for a meal must be together, but this meal's rows start on row %d", first))
+			} else {
+				firstRows[mealName] = row
+			}
 
-		if m == nil || mealName != m.Name {
 			if m != nil {
-				meals = append(meals, m)
+				meals = append(meals, *m)
 			}
 
-			m = meal.NewMealBuilder().WithName(mealName).Build()
+			m = &uploadedMeal{name: mealName, ingredients: []meal.Ingredient{}}
 		}
 
-		productName, err := product.NewProductName(record[1])
+		productName, err := product.NewProductName(strings.TrimSpace(record[1]))
 
 		if err != nil {
-			return nil, nil, err
+			invalid("product", record[1], "product cannot be empty")
 		}
 
-		amount, err := strconv.Atoi(record[2])
+		amount, err := quantity.ParseAmount(record[2])
 
 		if err != nil {
-			return nil, nil, err
+			invalid("amount", record[2], err.Error())
+		} else if err := validateQuantity(quantity.Quantity{Amount: amount}); err != nil {
+			invalid("amount", record[2], err.Error())
 		}
 
-		unit, ok := quantity.UnitFromString(record[3])
+		unit, ok := quantity.UnitFromString(strings.TrimSpace(record[3]))
 
 		if !ok {
-			return nil, nil, fmt.Errorf("invalid unit: %s", record[3])
+			invalid("unit", record[3], "invalid unit")
+		}
+
+		if len(rowErrors) > errorCount {
+			continue
 		}
 
-		i, err := a.ProductRepository.GetByName(productName)
+		m.rows = append(m.rows, row)
+
+		p, err := a.ProductRepository.FindByName(productName)
 
 		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if p == nil {
+			p = created[productName]
+		}
+
+		if p == nil && options.AutoCreateProducts && len(record) == 5 && strings.TrimSpace(record[4]) != "" {
+			c := findCategory(categories, record[4])
+
+			if c == nil {
+				invalid("category", record[4], "category does not exist")
+				continue
+			}
+
+			p, err = product.NewProduct(uuid.New().String(), productName, c.Id)
+
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			created[productName] = p
+			createdProducts = append(createdProducts, p)
+		}
+
+		if p == nil {
 			notFoundProducts = append(notFoundProducts, productName)
+			m.notFoundProducts = append(m.notFoundProducts, productName)
 			continue
 		}
 
-		m.AddIngredient(*meal.NewIngredient(i.Id).WithQuantity(amount, unit))
+		m.ingredients = append(m.ingredients, *meal.NewIngredient(p.Id).WithQuantity(amount, unit))
+	}
+
+	if header == nil {
+		return nil, nil, nil, &InvalidUpload{Errors: []UploadRowError{{Row: 1, Reason: "missing header"}}}
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, nil, nil, &InvalidUpload{Errors: rowErrors}
 	}
 
 	if m != nil {
-		meals = append(meals, m)
+		meals = append(meals, *m)
 	}
 
-	return meals, notFoundProducts, nil
+	return meals, notFoundProducts, createdProducts, nil
+}
+
+var uploadColumns = []string{"name", "product", "amount", "unit", "category"}
+
+// validateHeader checks an upload's header names its columns in order, with
+// the category column optional.
+func validateHeader(row int, header []string) []UploadRowError {
+	if len(header) < 4 || len(header) > len(uploadColumns) {
+		return []UploadRowError{{
+			Row:    row,
+			Reason: "expected columns name, product, amount, unit and optionally category",
+		}}
+	}
+
+	var headerErrors []UploadRowError
+
+	for i, column := range header {
+		if strings.TrimSpace(column) != uploadColumns[i] {
+			headerErrors = append(headerErrors, UploadRowError{
+				Row:    row,
+				Column: uploadColumns[i],
+				Value:  column,
+				Reason: "expected " + uploadColumns[i] + " column",
+			})
+		}
+	}
+
+	return headerErrors
+}
+
+// findCategory finds the category an upload names, by its id or, ignoring
+// case, its name.
+func findCategory(categories *category.Categories, name string) *category.Category {
+	name = strings.TrimSpace(name)
+
+	if c := categories.Find(category.CategoryName(name)); c != nil {
+		return c
+	}
+
+	for _, c := range categories.Categories {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,214 @@
+package bus
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+const batchSize = 100
+
+// Handler reacts to a single committed event. The event's data is the typed
+// domain event, e.g. *shop.Created. Events may be delivered more than once, so
+// handlers must be idempotent.
+type Handler func(ev eventsourcing.Event) error
+
+// subscription is a named handler. Its lock stops two dispatches delivering
+// to it at once.
+type subscription struct {
+	name   string
+	handle Handler
+	lock   *sync.Mutex
+}
+
+type Health struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Bus delivers events committed to the store to every subscriber. Each
+// subscriber has its own checkpoint, stored in the database, so delivery
+// picks up where it left off after a restart. Subscribers are delivered to
+// independently. A failing handler is retried and, if it keeps failing, holds
+// its subscriber at that event until the next dispatch.
+type Bus struct {
+	db            *sql.DB
+	es            *sqlStore.SQLite
+	lock          sync.Mutex
+	subscriptions []subscription
+	err           error
+	Retries       int
+	Backoff       time.Duration
+}
+
+func NewBus(db *sql.DB, es *sqlStore.SQLite) (*Bus, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS bus_checkpoints (
+		name     VARCHAR PRIMARY KEY,
+		position INTEGER NOT NULL
+	);`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bus{db: db, es: es, Retries: 3, Backoff: 100 * time.Millisecond}, nil
+}
+
+// Subscribe registers a handler under a name identifying its checkpoint. A
+// new subscriber starts from the beginning of the store.
+func (b *Bus) Subscribe(name string, handle Handler) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	_, err := b.db.Exec(`INSERT OR IGNORE INTO bus_checkpoints (name, position) VALUES (?, 0)`, name)
+
+	if err != nil {
+		return err
+	}
+
+	b.subscriptions = append(b.subscriptions, subscription{name: name, handle: handle, lock: &sync.Mutex{}})
+
+	return nil
+}
+
+// Run dispatches events at the given interval until the context is
+// cancelled.
+func (b *Bus) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := b.Dispatch(); err != nil {
+			slog.Error("Dispatching events failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch delivers every event saved since each subscriber's checkpoint,
+// to each subscriber at the same time.
+func (b *Bus) Dispatch() error {
+	b.lock.Lock()
+	subscriptions := slices.Clone(b.subscriptions)
+	b.lock.Unlock()
+
+	errs := make([]error, len(subscriptions))
+
+	var wg sync.WaitGroup
+
+	for i, s := range subscriptions {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			errs[i] = b.deliver(s)
+		}()
+	}
+
+	wg.Wait()
+
+	err := errors.Join(errs...)
+
+	b.lock.Lock()
+	b.err = err
+	b.lock.Unlock()
+
+	return err
+}
+
+// Health reports whether the last dispatch delivered every event.
+func (b *Bus) Health() Health {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.err != nil {
+		return Health{Healthy: false, Error: b.err.Error()}
+	}
+
+	return Health{Healthy: true}
+}
+
+func (b *Bus) Checkpoint(name string) (core.Version, error) {
+	var position core.Version
+	err := b.db.QueryRow(`SELECT position FROM bus_checkpoints WHERE name = ?`, name).Scan(&position)
+	return position, err
+}
+
+func (b *Bus) deliver(s subscription) error {
+	for {
+		checkpoint, err := b.Checkpoint(s.name)
+
+		if err != nil {
+			return err
+		}
+
+		events, err := database.ReadBatch(b.es, checkpoint+1, batchSize)
+
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		for _, ev := range events {
+			if ev.Event != nil {
+				if err := b.handle(s, *ev.Event); err != nil {
+					return err
+				}
+			}
+
+			if err := b.setCheckpoint(s.name, ev.Position); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (b *Bus) handle(s subscription, ev eventsourcing.Event) error {
+	var err error
+
+	for attempt := 0; attempt <= b.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(b.Backoff << (attempt - 1))
+		}
+
+		if err = s.handle(ev); err == nil {
+			return nil
+		}
+
+		slog.Error(
+			"Handling event failed",
+			"subscriber", s.name,
+			"event", ev.Reason(),
+			"aggregateId", ev.AggregateID(),
+			"attempt", attempt+1,
+			"error", err,
+		)
+	}
+
+	return err
+}
+
+func (b *Bus) setCheckpoint(name string, position core.Version) error {
+	_, err := b.db.Exec(`UPDATE bus_checkpoints SET position = ? WHERE name = ?`, position, name)
+	return err
+}
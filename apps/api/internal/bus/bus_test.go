@@ -0,0 +1,273 @@
+package bus_test
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/bus"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type BusSuite struct {
+	suite.Suite
+	db                *sql.DB
+	es                *sqlStore.SQLite
+	productRepository *product.EventSourcedProductRepository
+}
+
+func (suite *BusSuite) SetupTest() {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(suite.T(), err)
+	db.SetMaxOpenConns(1)
+
+	suite.db = db
+	suite.es, err = sqlStore.NewSQLiteSingelWriter(db)
+	assert.NoError(suite.T(), err)
+
+	suite.productRepository = product.NewProductRepository(suite.es, nil)
+}
+
+func (suite *BusSuite) TearDownTest() {
+	err := suite.db.Close()
+
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+}
+
+func (suite *BusSuite) TestDeliveringTypedEventsToEverySubscriber() {
+	suite.addProduct("a")
+
+	var first, second []string
+	b := suite.createBus()
+	suite.subscribe(b, "first", recordProductNames(&first))
+	suite.subscribe(b, "second", recordProductNames(&second))
+
+	err := b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"Product a"}, first)
+	assert.Equal(suite.T(), []string{"Product a"}, second)
+	suite.assertCheckpoint(b, "first", 1)
+	suite.assertCheckpoint(b, "second", 1)
+}
+
+func (suite *BusSuite) TestDeliveringOnlyNewEvents() {
+	var handled []string
+	b := suite.createBus()
+	suite.subscribe(b, "test", recordProductNames(&handled))
+
+	suite.addProduct("a")
+	err := b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	suite.addProduct("b")
+	err = b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	err = b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"Product a", "Product b"}, handled)
+}
+
+func (suite *BusSuite) TestResumingFromCheckpointAfterRestart() {
+	var handled []string
+
+	suite.addProduct("a")
+	b := suite.createBus()
+	suite.subscribe(b, "test", recordProductNames(&handled))
+	err := b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	suite.addProduct("b")
+	b = suite.createBus()
+	suite.subscribe(b, "test", recordProductNames(&handled))
+	err = b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"Product a", "Product b"}, handled)
+	suite.assertCheckpoint(b, "test", 2)
+}
+
+func (suite *BusSuite) TestRetryingFailedHandler() {
+	suite.addProduct("a")
+
+	attempts := 0
+	b := suite.createBus()
+	suite.subscribe(b, "test", func(ev eventsourcing.Event) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	err := b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), 3, attempts)
+	suite.assertCheckpoint(b, "test", 1)
+}
+
+func (suite *BusSuite) TestRedeliveringEventAfterRetriesRunOut() {
+	suite.addProduct("a")
+	suite.addProduct("b")
+
+	failing := true
+	var handled []string
+	record := recordProductNames(&handled)
+
+	b := suite.createBus()
+	suite.subscribe(b, "test", func(ev eventsourcing.Event) error {
+		if failing && ev.AggregateID() == "b" {
+			return errors.New("failed")
+		}
+		return record(ev)
+	})
+
+	err := b.Dispatch()
+	assert.EqualError(suite.T(), err, "failed")
+	suite.assertCheckpoint(b, "test", 1)
+
+	failing = false
+	err = b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"Product a", "Product b"}, handled)
+	suite.assertCheckpoint(b, "test", 2)
+}
+
+func (suite *BusSuite) TestReportingHealthOfLastDispatch() {
+	suite.addProduct("a")
+
+	failing := true
+	b := suite.createBus()
+	b.Retries = 0
+	suite.subscribe(b, "test", func(ev eventsourcing.Event) error {
+		if failing {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	assert.Equal(suite.T(), bus.Health{Healthy: true}, b.Health())
+
+	err := b.Dispatch()
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), bus.Health{Healthy: false, Error: "failed"}, b.Health())
+
+	failing = false
+	err = b.Dispatch()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), bus.Health{Healthy: true}, b.Health())
+}
+
+func (suite *BusSuite) TestSubscribingWhileHandlerRuns() {
+	suite.addProduct("a")
+
+	handling := make(chan struct{})
+	release := make(chan struct{})
+	b := suite.createBus()
+	suite.subscribe(b, "slow", func(ev eventsourcing.Event) error {
+		close(handling)
+		<-release
+		return nil
+	})
+
+	dispatched := make(chan error)
+	go func() { dispatched <- b.Dispatch() }()
+	<-handling
+
+	var handled []string
+	suite.subscribe(b, "other", recordProductNames(&handled))
+
+	close(release)
+	assert.NoError(suite.T(), <-dispatched)
+
+	err := b.Dispatch()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Product a"}, handled)
+}
+
+func (suite *BusSuite) TestCreatingAndFreezingBasketsForShops() {
+	shopRepository := shop.NewShopRepository(suite.es, nil)
+	basketRepository := basket.NewBasketRepository(suite.es, nil)
+
+	b := suite.createBus()
+	suite.subscribe(b, "baskets", application.NewBasketApplication(basketRepository).HandleShopEvent)
+
+	s, err := shop.NewShop(1)
+	assert.NoError(suite.T(), err)
+	err = shopRepository.Save(s)
+	assert.NoError(suite.T(), err)
+
+	err = b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	found, err := basketRepository.FindByShopId(1)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), found.Frozen)
+
+	err = s.Complete([]string{}, []string{}, []string{})
+	assert.NoError(suite.T(), err)
+	err = shopRepository.Save(s)
+	assert.NoError(suite.T(), err)
+
+	err = b.Dispatch()
+	assert.NoError(suite.T(), err)
+
+	found, err = basketRepository.FindByShopId(1)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), found.Frozen)
+}
+
+func (suite *BusSuite) createBus() *bus.Bus {
+	b, err := bus.NewBus(suite.db, suite.es)
+	assert.NoError(suite.T(), err)
+
+	b.Backoff = 0
+
+	return b
+}
+
+func (suite *BusSuite) subscribe(b *bus.Bus, name string, handle bus.Handler) {
+	err := b.Subscribe(name, handle)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *BusSuite) addProduct(id string) {
+	p, err := product.NewProduct(id, product.ProductName("Product "+id), category.Bakery)
+	assert.NoError(suite.T(), err)
+
+	err = suite.productRepository.Add(p)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *BusSuite) assertCheckpoint(b *bus.Bus, name string, expected int) {
+	checkpoint, err := b.Checkpoint(name)
+	assert.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), expected, checkpoint)
+}
+
+func recordProductNames(handled *[]string) bus.Handler {
+	return func(ev eventsourcing.Event) error {
+		if e, ok := ev.Data().(*product.Created); ok {
+			*handled = append(*handled, e.Name)
+		}
+		return nil
+	}
+}
+
+func TestBusSuite(t *testing.T) {
+	suite.Run(t, new(BusSuite))
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+// RecordedEvent is an event read from the store along with its global
+// version. Events whose type isn't registered have no event, but their
+// position still lets a checkpoint move past them.
+type RecordedEvent struct {
+	Event    *eventsourcing.Event
+	Position core.Version
+}
+
+// ReadBatch reads up to size events starting at the given version. The rows
+// are fully read before returning so the caller is free to write to the
+// database.
+func ReadBatch(es *sqlStore.SQLite, start core.Version, size int) ([]RecordedEvent, error) {
+	coreIterator, err := es.All(start)()
+
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &recordingIterator{Iterator: coreIterator}
+	iterator := &eventsourcing.Iterator{CoreIterator: recorder}
+	defer iterator.Close()
+
+	var events []RecordedEvent
+
+	for len(events) < size && iterator.Next() {
+		ev, err := iterator.Value()
+
+		if err != nil && !errors.Is(err, eventsourcing.ErrEventNotRegistered) {
+			return nil, err
+		}
+
+		recorded := RecordedEvent{Position: recorder.last}
+		if err == nil {
+			recorded.Event = &ev
+		}
+
+		events = append(events, recorded)
+	}
+
+	return events, nil
+}
+
+// recordingIterator remembers the global version of the last event read, so
+// the position of events that aren't registered is still known.
+type recordingIterator struct {
+	core.Iterator
+	last core.Version
+}
+
+func (i *recordingIterator) Value() (core.Event, error) {
+	ev, err := i.Iterator.Value()
+
+	if err == nil {
+		i.last = ev.GlobalVersion
+	}
+
+	return ev, err
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"github.com/hallgren/eventsourcing/core"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"sync"
+)
+
+var writeLocks sync.Map
+
+// WriteLock returns the lock held while writing events to db, by its event
+// stores and by units of work committing to it, so that only one of them
+// writes at a time.
+func WriteLock(db *sql.DB) *sync.Mutex {
+	lock, _ := writeLocks.LoadOrStore(db, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// EventStore is a SQLite event store that saves under its database's write
+// lock.
+type EventStore struct {
+	*sqlStore.SQLite
+	lock *sync.Mutex
+}
+
+func NewEventStore(db *sql.DB) (*EventStore, error) {
+	es, err := sqlStore.NewSQLite(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventStore{SQLite: es, lock: WriteLock(db)}, nil
+}
+
+func (s *EventStore) Save(events []core.Event) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.SQLite.Save(events)
+}
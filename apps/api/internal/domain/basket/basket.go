@@ -1,15 +1,19 @@
 package basket
 
 import (
+	"errors"
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"strconv"
 )
 
+var ErrBasketFrozen = errors.New("basket has been frozen")
+
 type Basket struct {
 	aggregate.Root
 	ShopId int           `json:"shopId"`
 	Items  []*BasketItem `json:"items"`
+	Frozen bool          `json:"frozen,omitempty"`
 }
 
 type BasketItem struct {
@@ -37,11 +41,21 @@ func (b *Basket) Transition(event eventsourcing.Event) {
 			Items = append(Items, &BasketItem{IngredientId: Item.IngredientId})
 		}
 		b.Items = Items
+	case *Frozen:
+		b.Frozen = true
 	}
 }
 
 func (b *Basket) Register(r aggregate.RegisterFunc) {
-	r(&Created{}, &ItemAdded{}, &ItemRemoved{}, &ItemsSet{})
+	r(&Created{}, &ItemAdded{}, &ItemRemoved{}, &ItemsSet{}, &Frozen{})
+}
+
+func (b *Basket) SerializeSnapshot(f aggregate.SnapshotMarshal) ([]byte, error) {
+	return f(b)
+}
+
+func (b *Basket) DeserializeSnapshot(f aggregate.SnapshotUnmarshal, d []byte) error {
+	return f(d, b)
 }
 
 func NewBasket(shopId int) (*Basket, error) {
@@ -58,18 +72,40 @@ func NewBasket(shopId int) (*Basket, error) {
 	return b, nil
 }
 
-func (b *Basket) AddItem(m *BasketItem) *Basket {
+func (b *Basket) AddItem(m *BasketItem) error {
+	if b.Frozen {
+		return ErrBasketFrozen
+	}
+
 	aggregate.TrackChange(b, &ItemAdded{Item: *m})
-	return b
+	return nil
 }
 
-func (b *Basket) SetItems(m []*BasketItem) *Basket {
+func (b *Basket) SetItems(m []*BasketItem) error {
+	if b.Frozen {
+		return ErrBasketFrozen
+	}
+
 	aggregate.TrackChange(b, &ItemsSet{Items: m})
-	return b
+	return nil
 }
 
-func (b *Basket) RemoveItem(id string) {
+func (b *Basket) RemoveItem(id string) error {
+	if b.Frozen {
+		return ErrBasketFrozen
+	}
+
 	aggregate.TrackChange(b, &ItemRemoved{IngredientId: id})
+	return nil
+}
+
+// Freeze stops the basket from changing once its shop has been completed.
+func (b *Basket) Freeze() {
+	if b.Frozen {
+		return
+	}
+
+	aggregate.TrackChange(b, &Frozen{})
 }
 
 func NewBasketItem(ingredientId string) *BasketItem {
@@ -3,45 +3,95 @@ package basket
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/eventstore/memory"
-	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	snapshotStore "github.com/hallgren/eventsourcing/snapshotstore/memory"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/snapshot"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	_ "github.com/mattn/go-sqlite3"
 	"strconv"
 )
 
 type BasketRepository struct {
-	es  core.EventStore
-	all func() (core.Iterator, error)
+	es        core.EventStore
+	all       func() (core.Iterator, error)
+	snapshots *snapshot.Snapshotter
 }
 
 func NewBasketRepository(es core.EventStore, all func() (core.Iterator, error)) *BasketRepository {
 	aggregate.Register(&Basket{})
-	r := &BasketRepository{es, all}
+	r := &BasketRepository{es: es, all: all}
 	return r
 }
 
 func NewSqliteBasketRepository(db *sql.DB) (*BasketRepository, error) {
-	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	es, err := database.NewEventStore(db)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return NewBasketRepository(es, func() (core.Iterator, error) {
+	r := NewBasketRepository(es, func() (core.Iterator, error) {
 		return es.All(0)()
-	}), nil
+	})
+
+	r.snapshots, err = snapshot.NewSqliteSnapshotter(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 func NewFakeBasketRepository() *BasketRepository {
 	es := memory.Create()
 
-	return NewBasketRepository(es, es.All(0, 100000))
+	r := NewBasketRepository(es, func() (core.Iterator, error) {
+		return es.All(0, 100000)()
+	})
+
+	r.snapshots = &snapshot.Snapshotter{Store: snapshotStore.Create(), Interval: snapshot.DefaultInterval}
+
+	return r
+}
+
+// WithSnapshotInterval returns a copy of the repository sharing its stores but
+// snapshotting baskets at the given interval. Zero turns snapshots off.
+func (r BasketRepository) WithSnapshotInterval(interval int) *BasketRepository {
+	r.snapshots = r.snapshots.WithInterval(interval)
+	return &r
+}
+
+// SnapshotStore returns the store the repository keeps basket snapshots in.
+func (r BasketRepository) SnapshotStore() core.SnapshotStore {
+	if r.snapshots == nil {
+		return nil
+	}
+
+	return r.snapshots.Store
+}
+
+// In returns a copy of the repository that saves baskets as part of the unit.
+func (r BasketRepository) In(unit *unitofwork.Unit) *BasketRepository {
+	r.es = unit.Store(r.es)
+	return &r
 }
 
 func (r BasketRepository) FindByShopId(shopId int) (*Basket, error) {
 	b := &Basket{}
+
+	if r.snapshots.Enabled() {
+		err := aggregate.LoadSnapshot(context.Background(), r.snapshots.Store, strconv.Itoa(shopId), b)
+		if err != nil && !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+			return nil, err
+		}
+	}
+
 	err := aggregate.Load(context.Background(), r.es, strconv.Itoa(shopId), b)
 	if err != nil {
 		return nil, err
@@ -51,5 +101,23 @@ func (r BasketRepository) FindByShopId(shopId int) (*Basket, error) {
 }
 
 func (r BasketRepository) Save(b *Basket) error {
-	return aggregate.Save(r.es, b)
+	saved := b.Version() - eventsourcing.Version(len(b.Events()))
+
+	err := aggregate.Save(r.es, b)
+	if err != nil {
+		return err
+	}
+
+	if !r.snapshots.Due(saved, b.Version()) {
+		return nil
+	}
+
+	return unitofwork.AfterCommit(r.es, func() error {
+		loaded, err := r.FindByShopId(b.ShopId)
+		if err != nil {
+			return err
+		}
+
+		return aggregate.SaveSnapshot(r.snapshots.Store, loaded)
+	})
 }
@@ -0,0 +1,105 @@
+package basket_test
+
+import (
+	"context"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestFakeBasketRepository(t *testing.T) {
+	runSuite(t, func() *basket.BasketRepository {
+		return basket.NewFakeBasketRepository()
+	}, func() {})
+}
+
+func TestSqliteBasketRepository(t *testing.T) {
+	runSuite(t, func() *basket.BasketRepository {
+		db, err := database.CreateDatabase("test.db")
+		assert.NoError(t, err)
+		r, err := basket.NewSqliteBasketRepository(db)
+		assert.NoError(t, err)
+		return r
+	}, func() {
+		err := os.Remove("test.db")
+		assert.NoError(t, err)
+	})
+}
+
+func runSuite(t *testing.T, factory func() *basket.BasketRepository, teardown func()) {
+	tests := []struct {
+		title string
+		run   func(t *testing.T, r *basket.BasketRepository)
+	}{
+		{"finding basket by shop id", testFindingBasketByShopId},
+		{"loading basket from a snapshot", testLoadingBasketFromSnapshot},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.run(t, factory())
+			teardown()
+		})
+	}
+}
+
+func testFindingBasketByShopId(t *testing.T, r *basket.BasketRepository) {
+	b, err := basket.NewBasket(1)
+	assert.NoError(t, err)
+	err = b.AddItem(basket.NewBasketItem("abc"))
+	assert.NoError(t, err)
+
+	err = r.Save(b)
+	assert.NoError(t, err)
+
+	found, err := r.FindByShopId(1)
+	assert.NoError(t, err)
+	assert.EqualExportedValues(t, b, found)
+}
+
+func testLoadingBasketFromSnapshot(t *testing.T, r *basket.BasketRepository) {
+	r = r.WithSnapshotInterval(4)
+
+	b, err := basket.NewBasket(1)
+	assert.NoError(t, err)
+	err = r.Save(b)
+	assert.NoError(t, err)
+
+	for i := 1; i <= 10; i++ {
+		err = b.AddItem(basket.NewBasketItem(strconv.Itoa(i)))
+		assert.NoError(t, err)
+
+		if i%3 == 0 {
+			err = b.RemoveItem(strconv.Itoa(i - 1))
+			assert.NoError(t, err)
+		}
+
+		err = r.Save(b)
+		assert.NoError(t, err)
+	}
+
+	b.Freeze()
+	err = r.Save(b)
+	assert.NoError(t, err)
+
+	snapshotted := &basket.Basket{}
+	err = aggregate.LoadSnapshot(context.Background(), r.SnapshotStore(), b.ID(), snapshotted)
+	assert.NoError(t, err)
+	assert.Equal(t, eventsourcing.Version(13), snapshotted.Version())
+
+	found, err := r.FindByShopId(1)
+	assert.NoError(t, err)
+
+	replayed, err := r.WithSnapshotInterval(0).FindByShopId(1)
+	assert.NoError(t, err)
+
+	assert.EqualExportedValues(t, replayed, found)
+	assert.EqualExportedValues(t, b, found)
+	assert.Equal(t, replayed.Version(), found.Version())
+	assert.Equal(t, replayed.GlobalVersion(), found.GlobalVersion())
+}
@@ -15,3 +15,5 @@ type ItemRemoved struct {
 type ItemsSet struct {
 	Items []*BasketItem
 }
+
+type Frozen struct{}
@@ -2,55 +2,192 @@ package category
 
 import (
 	"errors"
-	"sort"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"slices"
 )
 
-type CategoryName int
+// CategoryName identifies a category. Products refer to categories by it, so
+// renaming a category only changes the name it is shown with. The built-in
+// categories are identified by their original names.
+type CategoryName string
 
-func (r CategoryName) MarshalText() (text []byte, err error) {
-	if name, ok := _CategoryNameValueToName[r]; ok {
-		return []byte(name), nil
-	}
-	return nil, errors.New("invalid category name")
+const (
+	Fruit                   CategoryName = "Fruit"
+	Meat                    CategoryName = "Meat"
+	FishAndSeafood          CategoryName = "FishAndSeafood"
+	FoodCupboard            CategoryName = "FoodCupboard"
+	Drinks                  CategoryName = "Drinks"
+	Chilled                 CategoryName = "Chilled"
+	Frozen                  CategoryName = "Frozen"
+	Bakery                  CategoryName = "Bakery"
+	Vegetables              CategoryName = "Vegetables"
+	TeaAndCoffee            CategoryName = "TeaAndCoffee"
+	AlcoholicDrinks         CategoryName = "AlcoholicDrinks"
+	SaucesOilsAndDressings  CategoryName = "SaucesOilsAndDressings"
+	PastaRiceAndNoodles     CategoryName = "PastaRiceAndNoodles"
+	SeedsNutsAndDriedFruits CategoryName = "SeedsNutsAndDriedFruits"
+	ChocolateAndSweets      CategoryName = "ChocolateAndSweets"
+	TinsCansAndPackets      CategoryName = "TinsCansAndPackets"
+	Desserts                CategoryName = "Desserts"
+	Dairy                   CategoryName = "Dairy"
+	Eggs                    CategoryName = "Eggs"
+)
+
+// Defaults are the built-in categories the list is seeded with, in their
+// starting aisle order.
+var Defaults = []CategoryName{
+	Fruit,
+	Meat,
+	FishAndSeafood,
+	FoodCupboard,
+	Drinks,
+	Chilled,
+	Frozen,
+	Bakery,
+	Vegetables,
+	TeaAndCoffee,
+	AlcoholicDrinks,
+	SaucesOilsAndDressings,
+	PastaRiceAndNoodles,
+	SeedsNutsAndDriedFruits,
+	ChocolateAndSweets,
+	TinsCansAndPackets,
+	Desserts,
+	Dairy,
+	Eggs,
 }
 
-//go:generate go run github.com/campoy/jsonenums -type=CategoryName
-const (
-	Fruit CategoryName = iota
-	Meat
-	FishAndSeafood
-	FoodCupboard
-	Drinks
-	Chilled
-	Frozen
-	Bakery
-	Vegetables
-	TeaAndCoffee
-	AlcoholicDrinks
-	SaucesOilsAndDressings
-	PastaRiceAndNoodles
-	SeedsNutsAndDriedFruits
-	ChocolateAndSweets
-	TinsCansAndPackets
-	Desserts
-	Dairy
-	Eggs
+var (
+	ErrCategoryExists   = errors.New("category already exists")
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidOrder     = errors.New("order must include every category exactly once")
 )
 
+// Id identifies the household's single list of categories.
+const Id = "categories"
+
 type Category struct {
-	Name string `json:"name"`
+	Id   CategoryName `json:"id"`
+	Name string       `json:"name"`
 }
 
-func Categories() []Category {
-	v := make([]Category, 0, len(_CategoryNameValueToName))
+// Categories holds every category in the order the aisles are walked in the
+// shop.
+type Categories struct {
+	aggregate.Root
+	Categories []*Category `json:"categories"`
+}
 
-	for _, value := range _CategoryNameValueToName {
-		v = append(v, Category{Name: value})
+func (c *Categories) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Seeded:
+		c.Categories = []*Category{}
+		for _, category := range e.Categories {
+			c.Categories = append(c.Categories, &Category{Id: category.Id, Name: category.Name})
+		}
+	case *Created:
+		c.Categories = append(c.Categories, &Category{Id: e.Id, Name: e.Name})
+	case *Renamed:
+		c.Find(e.Id).Name = e.Name
+	case *Reordered:
+		slices.SortFunc(c.Categories, func(a, b *Category) int {
+			return slices.Index(e.Ids, a.Id) - slices.Index(e.Ids, b.Id)
+		})
 	}
+}
+
+func (c *Categories) Register(r aggregate.RegisterFunc) {
+	r(&Seeded{}, &Created{}, &Renamed{}, &Reordered{})
+}
+
+// NewCategories starts the list with the built-in categories.
+func NewCategories() (*Categories, error) {
+	c := &Categories{}
+
+	err := c.SetID(Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	seeded := make([]Category, 0, len(Defaults))
+	for _, id := range Defaults {
+		seeded = append(seeded, Category{Id: id, Name: string(id)})
+	}
+
+	aggregate.TrackChange(c, &Seeded{Categories: seeded})
+
+	return c, nil
+}
+
+func (c *Categories) Find(id CategoryName) *Category {
+	for _, category := range c.Categories {
+		if category.Id == id {
+			return category
+		}
+	}
+
+	return nil
+}
 
-	sort.Slice(v, func(i, j int) bool {
-		return v[i].Name < v[j].Name
+func (c *Categories) FindByName(name string) *Category {
+	for _, category := range c.Categories {
+		if category.Name == name {
+			return category
+		}
+	}
+
+	return nil
+}
+
+// Position is the category's place in the aisle order, or -1 when there is no
+// such category.
+func (c *Categories) Position(id CategoryName) int {
+	return slices.IndexFunc(c.Categories, func(category *Category) bool {
+		return category.Id == id
 	})
+}
+
+// Create adds a category at the end of the aisle order.
+func (c *Categories) Create(id CategoryName, name string) error {
+	if c.Find(id) != nil {
+		return ErrCategoryExists
+	}
+
+	aggregate.TrackChange(c, &Created{Id: id, Name: name})
+
+	return nil
+}
+
+func (c *Categories) Rename(id CategoryName, name string) error {
+	category := c.Find(id)
+
+	if category == nil {
+		return ErrCategoryNotFound
+	}
+
+	if category.Name != name {
+		aggregate.TrackChange(c, &Renamed{Id: id, Name: name})
+	}
+
+	return nil
+}
+
+// Reorder puts the categories in the given order, which must list each of
+// them once.
+func (c *Categories) Reorder(ids []CategoryName) error {
+	if len(ids) != len(c.Categories) {
+		return ErrInvalidOrder
+	}
+
+	for i, id := range ids {
+		if c.Find(id) == nil || slices.Index(ids, id) != i {
+			return ErrInvalidOrder
+		}
+	}
+
+	aggregate.TrackChange(c, &Reordered{Ids: ids})
 
-	return v
+	return nil
 }
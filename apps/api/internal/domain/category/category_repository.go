@@ -0,0 +1,57 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type CategoryRepository struct {
+	es core.EventStore
+}
+
+func NewCategoryRepository(es core.EventStore) *CategoryRepository {
+	aggregate.Register(&Categories{})
+	return &CategoryRepository{es}
+}
+
+func NewSqliteCategoryRepository(db *sql.DB) (*CategoryRepository, error) {
+	es, err := database.NewEventStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCategoryRepository(es), nil
+}
+
+func NewFakeCategoryRepository() *CategoryRepository {
+	return NewCategoryRepository(memory.Create())
+}
+
+// Get loads the categories, seeding them with the built-in ones if they have
+// never been changed.
+func (r CategoryRepository) Get() (*Categories, error) {
+	c := &Categories{}
+	err := aggregate.Load(context.Background(), r.es, Id, c)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return NewCategories()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r CategoryRepository) Save(c *Categories) error {
+	return aggregate.Save(r.es, c)
+}
@@ -0,0 +1,110 @@
+package category_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestFakeCategoryRepository(t *testing.T) {
+	runSuite(t, func() *category.CategoryRepository {
+		return category.NewFakeCategoryRepository()
+	}, func() {})
+}
+
+func TestSqliteCategoryRepository(t *testing.T) {
+	runSuite(t, func() *category.CategoryRepository {
+		db, err := database.CreateDatabase("test.db")
+		assert.NoError(t, err)
+		r, err := category.NewSqliteCategoryRepository(db)
+		assert.NoError(t, err)
+		return r
+	}, func() {
+		err := os.Remove("test.db")
+		assert.NoError(t, err)
+	})
+}
+
+func runSuite(t *testing.T, factory func() *category.CategoryRepository, teardown func()) {
+	tests := []struct {
+		title string
+		run   func(t *testing.T, r *category.CategoryRepository)
+	}{
+		{"getting seeded categories", testGettingSeededCategories},
+		{"creating a category", testCreatingCategory},
+		{"renaming a category", testRenamingCategory},
+		{"reordering categories", testReorderingCategories},
+		{"reordering with an invalid order", testReorderingWithInvalidOrder},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.run(t, factory())
+			teardown()
+		})
+	}
+}
+
+func testGettingSeededCategories(t *testing.T, r *category.CategoryRepository) {
+	c, err := r.Get()
+	assert.NoError(t, err)
+
+	assert.Len(t, c.Categories, len(category.Defaults))
+	assert.Equal(t, &category.Category{Id: category.Fruit, Name: "Fruit"}, c.Categories[0])
+	assert.Equal(t, &category.Category{Id: category.Eggs, Name: "Eggs"}, c.Categories[len(c.Categories)-1])
+}
+
+func testCreatingCategory(t *testing.T, r *category.CategoryRepository) {
+	c, err := r.Get()
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Create("world-foods", "World Foods"))
+	assert.ErrorIs(t, c.Create("world-foods", "World Foods"), category.ErrCategoryExists)
+	assert.NoError(t, r.Save(c))
+
+	c, err = r.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, &category.Category{Id: "world-foods", Name: "World Foods"}, c.Find("world-foods"))
+	assert.Equal(t, len(category.Defaults), c.Position("world-foods"))
+}
+
+func testRenamingCategory(t *testing.T, r *category.CategoryRepository) {
+	c, err := r.Get()
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Rename(category.Dairy, "Milk, Butter and Eggs"))
+	assert.ErrorIs(t, c.Rename("nope", "Nope"), category.ErrCategoryNotFound)
+	assert.NoError(t, r.Save(c))
+
+	c, err = r.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "Milk, Butter and Eggs", c.Find(category.Dairy).Name)
+}
+
+func testReorderingCategories(t *testing.T, r *category.CategoryRepository) {
+	c, err := r.Get()
+	assert.NoError(t, err)
+
+	order := append([]category.CategoryName{category.Eggs}, category.Defaults[:len(category.Defaults)-1]...)
+	assert.NoError(t, c.Reorder(order))
+	assert.NoError(t, r.Save(c))
+
+	c, err = r.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.Position(category.Eggs))
+	assert.Equal(t, 1, c.Position(category.Fruit))
+	assert.Equal(t, -1, c.Position("nope"))
+}
+
+func testReorderingWithInvalidOrder(t *testing.T, r *category.CategoryRepository) {
+	c, err := r.Get()
+	assert.NoError(t, err)
+
+	duplicated := append([]category.CategoryName{category.Fruit}, category.Defaults[1:]...)
+	duplicated[1] = category.Fruit
+
+	assert.ErrorIs(t, c.Reorder(category.Defaults[1:]), category.ErrInvalidOrder)
+	assert.ErrorIs(t, c.Reorder(duplicated), category.ErrInvalidOrder)
+}
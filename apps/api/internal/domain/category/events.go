@@ -0,0 +1,19 @@
+package category
+
+type Seeded struct {
+	Categories []Category
+}
+
+type Created struct {
+	Id   CategoryName
+	Name string
+}
+
+type Renamed struct {
+	Id   CategoryName
+	Name string
+}
+
+type Reordered struct {
+	Ids []CategoryName
+}
@@ -1,9 +1,12 @@
 package meal
 
+import "github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+
 type Created struct {
 	Id          string
 	Name        string
 	Url         string
+	Servings    int
 	Ingredients []Ingredient
 }
 
@@ -11,8 +14,22 @@ type IngredientAdded struct {
 	Ingredient Ingredient
 }
 
+// IngredientRemoved takes the ingredient with IngredientId out of a meal.
+// Events saved before ingredients had their own ids only have Id, the product
+// removed, and take out every ingredient of that product.
 type IngredientRemoved struct {
-	Id string
+	Id           string
+	IngredientId string
+}
+
+type IngredientQuantityChanged struct {
+	Id       string
+	Quantity quantity.Quantity
+}
+
+type IngredientNoteChanged struct {
+	Id   string
+	Note string
 }
 
 type NameUpdated struct {
@@ -22,3 +39,22 @@ type NameUpdated struct {
 type UrlUpdated struct {
 	Url string
 }
+
+type ServingsUpdated struct {
+	Servings int
+}
+
+type Archived struct{}
+
+type StepsUpdated struct {
+	Steps []string
+}
+
+type TimesUpdated struct {
+	PrepTime int
+	CookTime int
+}
+
+type TagsUpdated struct {
+	Tags []string
+}
@@ -1,18 +1,30 @@
 package meal
 
 import (
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"slices"
 )
 
+var ErrMealArchived = errors.New("meal has been archived")
+var ErrIngredientNotFound = errors.New("ingredient not in meal")
+
 type Meal struct {
 	aggregate.Root
 	Id          string       `json:"id"`
 	Name        string       `json:"name"`
 	Url         string       `json:"url"`
+	Servings    int          `json:"servings"`
 	Ingredients []Ingredient `json:"ingredients"`
+	Steps       []string     `json:"steps,omitempty"`
+	PrepTime    int          `json:"prepTime,omitempty"`
+	CookTime    int          `json:"cookTime,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Archived    bool         `json:"archived,omitempty"`
 }
 
 func (m *Meal) Transition(event eventsourcing.Event) {
@@ -21,45 +33,194 @@ func (m *Meal) Transition(event eventsourcing.Event) {
 		m.Id = e.Id
 		m.Name = e.Name
 		m.Url = e.Url
-		m.Ingredients = e.Ingredients
+		m.Servings = e.Servings
+		m.Ingredients = withLegacyIngredientIds(e.Ingredients)
 	case *IngredientAdded:
-		m.Ingredients = append(m.Ingredients, e.Ingredient)
+		m.Ingredients = append(m.Ingredients, withLegacyIngredientId(m.Ingredients, e.Ingredient))
 	case *IngredientRemoved:
 		ingredients := []Ingredient{}
 		for _, ingredient := range m.Ingredients {
-			if ingredient.ProductId != e.Id {
+			if !e.removes(ingredient) {
 				ingredients = append(ingredients, ingredient)
 			}
 		}
 		m.Ingredients = ingredients
+	case *IngredientQuantityChanged:
+		ingredients := []Ingredient{}
+		for _, ingredient := range m.Ingredients {
+			if ingredient.Id == e.Id {
+				ingredient.Quantity = e.Quantity
+			}
+			ingredients = append(ingredients, ingredient)
+		}
+		m.Ingredients = ingredients
+	case *IngredientNoteChanged:
+		ingredients := []Ingredient{}
+		for _, ingredient := range m.Ingredients {
+			if ingredient.Id == e.Id {
+				ingredient.Note = e.Note
+			}
+			ingredients = append(ingredients, ingredient)
+		}
+		m.Ingredients = ingredients
 	case *NameUpdated:
 		m.Name = e.Name
 	case *UrlUpdated:
 		m.Url = e.Url
+	case *ServingsUpdated:
+		m.Servings = e.Servings
+	case *StepsUpdated:
+		m.Steps = e.Steps
+	case *TimesUpdated:
+		m.PrepTime = e.PrepTime
+		m.CookTime = e.CookTime
+	case *TagsUpdated:
+		m.Tags = e.Tags
+	case *Archived:
+		m.Archived = true
 	}
 }
 
 func (m *Meal) Register(r aggregate.RegisterFunc) {
-	r(&Created{}, &IngredientAdded{}, &IngredientRemoved{}, &NameUpdated{}, &UrlUpdated{})
+	r(&Created{}, &IngredientAdded{}, &IngredientRemoved{}, &IngredientQuantityChanged{}, &IngredientNoteChanged{}, &NameUpdated{}, &UrlUpdated{}, &ServingsUpdated{}, &StepsUpdated{}, &TimesUpdated{}, &TagsUpdated{}, &Archived{})
+}
+
+func (m *Meal) SerializeSnapshot(f aggregate.SnapshotMarshal) ([]byte, error) {
+	return f(m)
+}
+
+func (m *Meal) DeserializeSnapshot(f aggregate.SnapshotUnmarshal, d []byte) error {
+	if err := f(d, m); err != nil {
+		return err
+	}
+
+	m.Ingredients = withLegacyIngredientIds(m.Ingredients)
+
+	return nil
 }
 
-func NewMeal(id string, name string, url string, ingredients []Ingredient) (*Meal, error) {
+func NewMeal(id string, name string, url string, servings int, ingredients []Ingredient) (*Meal, error) {
 	m := &Meal{}
 	err := m.SetID(id)
 	if err != nil {
 		return nil, err
 	}
-	aggregate.TrackChange(m, &Created{Id: id, Name: name, Url: url, Ingredients: ingredients})
+	aggregate.TrackChange(m, &Created{Id: id, Name: name, Url: url, Servings: servings, Ingredients: withNewIngredientIds(ingredients)})
 
 	return m, nil
 }
 
-func (m *Meal) AddIngredient(ingredient Ingredient) {
+// AddIngredient adds an ingredient to the meal, giving it an id if it doesn't
+// have one. The same product can be added more than once.
+func (m *Meal) AddIngredient(ingredient Ingredient) Ingredient {
+	ingredient = withNewIngredientId(m.Ingredients, ingredient)
+
 	aggregate.TrackChange(m, &IngredientAdded{Ingredient: ingredient})
+
+	return ingredient
 }
 
-func (m *Meal) RemoveIngredient(id string) {
-	aggregate.TrackChange(m, &IngredientRemoved{Id: id})
+func (m *Meal) FindIngredient(id string) *Ingredient {
+	for i := range m.Ingredients {
+		if m.Ingredients[i].Id == id {
+			return &m.Ingredients[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *Meal) RemoveIngredient(id string) error {
+	if m.FindIngredient(id) == nil {
+		return ErrIngredientNotFound
+	}
+
+	aggregate.TrackChange(m, &IngredientRemoved{IngredientId: id})
+
+	return nil
+}
+
+// RemovedIngredients returns the ingredients of the meal an IngredientRemoved
+// event takes out.
+func (m *Meal) RemovedIngredients(e *IngredientRemoved) []Ingredient {
+	removed := []Ingredient{}
+
+	for _, ingredient := range m.Ingredients {
+		if e.removes(ingredient) {
+			removed = append(removed, ingredient)
+		}
+	}
+
+	return removed
+}
+
+func (e *IngredientRemoved) removes(i Ingredient) bool {
+	if e.IngredientId != "" {
+		return i.Id == e.IngredientId
+	}
+
+	return i.ProductId == e.Id
+}
+
+// ChangeIngredientQuantity sets how much of an ingredient the meal needs.
+func (m *Meal) ChangeIngredientQuantity(id string, q quantity.Quantity) error {
+	i := m.FindIngredient(id)
+
+	if i == nil {
+		return ErrIngredientNotFound
+	}
+
+	if i.Quantity != q {
+		aggregate.TrackChange(m, &IngredientQuantityChanged{Id: id, Quantity: q})
+	}
+
+	return nil
+}
+
+func (m *Meal) ChangeIngredientNote(id string, note string) error {
+	i := m.FindIngredient(id)
+
+	if i == nil {
+		return ErrIngredientNotFound
+	}
+
+	if i.Note != note {
+		aggregate.TrackChange(m, &IngredientNoteChanged{Id: id, Note: note})
+	}
+
+	return nil
+}
+
+// ReplaceIngredients changes the meal's ingredients to the given ones,
+// removing and adding only those that differ. Ingredients are paired up by
+// product in order, and paired ingredients keep their ids and notes, with
+// just their quantity changed if need be.
+func (m *Meal) ReplaceIngredients(ingredients []Ingredient) (added []Ingredient, removed []Ingredient, changed []Ingredient) {
+	remaining := slices.Clone(ingredients)
+
+	for _, existing := range slices.Clone(m.Ingredients) {
+		i := slices.IndexFunc(remaining, func(r Ingredient) bool { return r.ProductId == existing.ProductId })
+
+		if i == -1 {
+			m.RemoveIngredient(existing.Id)
+			removed = append(removed, existing)
+			continue
+		}
+
+		if remaining[i].Quantity != existing.Quantity {
+			aggregate.TrackChange(m, &IngredientQuantityChanged{Id: existing.Id, Quantity: remaining[i].Quantity})
+			existing.Quantity = remaining[i].Quantity
+			changed = append(changed, existing)
+		}
+
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+
+	for _, r := range remaining {
+		added = append(added, m.AddIngredient(Ingredient{ProductId: r.ProductId, Quantity: r.Quantity, Note: r.Note}))
+	}
+
+	return added, removed, changed
 }
 
 func (m *Meal) UpdateName(name string) {
@@ -70,30 +231,173 @@ func (m *Meal) UpdateUrl(url string) {
 	aggregate.TrackChange(m, &UrlUpdated{Url: url})
 }
 
+func (m *Meal) UpdateServings(servings int) {
+	aggregate.TrackChange(m, &ServingsUpdated{Servings: servings})
+}
+
+// UpdateSteps replaces the meal's method with the given steps, in order.
+func (m *Meal) UpdateSteps(steps []string) {
+	if !slices.Equal(m.Steps, steps) {
+		aggregate.TrackChange(m, &StepsUpdated{Steps: steps})
+	}
+}
+
+// UpdateTimes sets how many minutes the meal takes to prepare and to cook.
+// Zero means the time isn't known.
+func (m *Meal) UpdateTimes(prepTime int, cookTime int) {
+	if m.PrepTime != prepTime || m.CookTime != cookTime {
+		aggregate.TrackChange(m, &TimesUpdated{PrepTime: prepTime, CookTime: cookTime})
+	}
+}
+
+func (m *Meal) UpdateTags(tags []string) {
+	if !slices.Equal(m.Tags, tags) {
+		aggregate.TrackChange(m, &TagsUpdated{Tags: tags})
+	}
+}
+
+// TotalTime is how many minutes the meal takes to prepare and cook, or zero
+// when neither is known.
+func (m *Meal) TotalTime() int {
+	return m.PrepTime + m.CookTime
+}
+
+func (m *Meal) HasTag(tag string) bool {
+	return slices.Contains(m.Tags, tag)
+}
+
+// Archive hides the meal from the list of meals and stops it being added to
+// shops. Shops it was already added to keep it.
+func (m *Meal) Archive() {
+	if !m.Archived {
+		aggregate.TrackChange(m, &Archived{})
+	}
+}
+
+// ScaleFactor is the ratio to multiply ingredient quantities by when cooking
+// the meal for the given number of servings. Meals without a known number of
+// servings, or requests without one, are not scaled.
+func (m *Meal) ScaleFactor(servings int) quantity.Amount {
+	if m.Servings <= 0 || servings <= 0 {
+		return quantity.NewAmount(1)
+	}
+
+	return quantity.NewFraction(int64(servings), int64(m.Servings))
+}
+
+// Ingredient is a product used in a meal. Ingredients have their own id, so
+// a meal can use the same product more than once, say "butter, for the roux"
+// and "butter, for greasing". The product id keeps its original "id" key so
+// stored events still read.
 type Ingredient struct {
 	ProductId string            `json:"id"`
+	Id        string            `json:"ingredientId"`
 	Quantity  quantity.Quantity `json:"quantity"`
+	Note      string            `json:"note,omitempty"`
 }
 
 func NewIngredient(id string) *Ingredient {
-	return &Ingredient{ProductId: id, Quantity: quantity.Quantity{1, quantity.Number}}
+	return &Ingredient{ProductId: id, Quantity: quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Number}}
 }
 
-func (m *Ingredient) WithQuantity(amount int, unit quantity.Unit) *Ingredient {
-	m.Quantity = quantity.Quantity{amount, unit}
+func (m *Ingredient) WithQuantity(amount quantity.Amount, unit quantity.Unit) *Ingredient {
+	m.Quantity = quantity.Quantity{Amount: amount, Unit: unit}
 
 	return m
 }
 
+func (m *Ingredient) WithNote(note string) *Ingredient {
+	m.Note = note
+
+	return m
+}
+
+func (m *Ingredient) WithId(id string) *Ingredient {
+	m.Id = id
+
+	return m
+}
+
+// legacyIngredientId is the id given to an ingredient recorded before
+// ingredients had ids: the product's id, suffixed with a count when the meal
+// already uses it. It depends only on the ingredients before it, so replaying
+// old events always gives the same ids.
+func legacyIngredientId(ingredients []Ingredient, productId string) string {
+	id := productId
+
+	for n := 2; hasIngredientId(ingredients, id); n++ {
+		id = fmt.Sprintf("%s-%d", productId, n)
+	}
+
+	return id
+}
+
+// withLegacyIngredientId gives an ingredient read from an old event an id
+// unless it already has one that isn't used by the other ingredients.
+func withLegacyIngredientId(ingredients []Ingredient, i Ingredient) Ingredient {
+	if i.Id == "" || hasIngredientId(ingredients, i.Id) {
+		i.Id = legacyIngredientId(ingredients, i.ProductId)
+	}
+
+	return i
+}
+
+func withLegacyIngredientIds(ingredients []Ingredient) []Ingredient {
+	if ingredients == nil {
+		return nil
+	}
+
+	withIds := make([]Ingredient, 0, len(ingredients))
+
+	for _, i := range ingredients {
+		withIds = append(withIds, withLegacyIngredientId(withIds, i))
+	}
+
+	return withIds
+}
+
+// withNewIngredientId gives an ingredient being added to a meal a new, unique
+// id unless it already has one that isn't used by the other ingredients.
+func withNewIngredientId(ingredients []Ingredient, i Ingredient) Ingredient {
+	if i.Id == "" || hasIngredientId(ingredients, i.Id) {
+		i.Id = uuid.NewString()
+	}
+
+	return i
+}
+
+func withNewIngredientIds(ingredients []Ingredient) []Ingredient {
+	if ingredients == nil {
+		return nil
+	}
+
+	withIds := make([]Ingredient, 0, len(ingredients))
+
+	for _, i := range ingredients {
+		withIds = append(withIds, withNewIngredientId(withIds, i))
+	}
+
+	return withIds
+}
+
+func hasIngredientId(ingredients []Ingredient, id string) bool {
+	return slices.ContainsFunc(ingredients, func(i Ingredient) bool { return i.Id == id })
+}
+
 type MealBuilder struct {
 	id          string
 	name        string
 	url         string
+	servings    int
 	Ingredients []Ingredient
+	steps       []string
+	prepTime    int
+	cookTime    int
+	tags        []string
 }
 
 func NewMealBuilder() *MealBuilder {
-	return &MealBuilder{"", "", "", []Ingredient{}}
+	return &MealBuilder{Ingredients: []Ingredient{}}
 }
 
 func (b *MealBuilder) WithName(name string) *MealBuilder {
@@ -106,6 +410,27 @@ func (b *MealBuilder) WithUrl(url string) *MealBuilder {
 	return b
 }
 
+func (b *MealBuilder) WithServings(servings int) *MealBuilder {
+	b.servings = servings
+	return b
+}
+
+func (b *MealBuilder) WithSteps(steps ...string) *MealBuilder {
+	b.steps = steps
+	return b
+}
+
+func (b *MealBuilder) WithTimes(prepTime int, cookTime int) *MealBuilder {
+	b.prepTime = prepTime
+	b.cookTime = cookTime
+	return b
+}
+
+func (b *MealBuilder) WithTags(tags ...string) *MealBuilder {
+	b.tags = tags
+	return b
+}
+
 func (b *MealBuilder) AddIngredient(i Ingredient) *MealBuilder {
 	b.Ingredients = append(b.Ingredients, i)
 	return b
@@ -125,11 +450,15 @@ func (b *MealBuilder) Build() *Meal {
 		id = b.id
 	}
 
-	meal, err := NewMeal(id, b.name, b.url, b.Ingredients)
+	meal, err := NewMeal(id, b.name, b.url, b.servings, b.Ingredients)
 	if err != nil {
 		return nil
 	}
 
+	meal.UpdateSteps(b.steps)
+	meal.UpdateTimes(b.prepTime, b.cookTime)
+	meal.UpdateTags(b.tags)
+
 	return meal
 }
 
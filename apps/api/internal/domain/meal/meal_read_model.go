@@ -0,0 +1,140 @@
+package meal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/readmodel"
+)
+
+// mealReadModel keeps the latest state of every meal in a table, so meals can
+// be listed and looked up by name without replaying the event log.
+type mealReadModel struct {
+	db     *sql.DB
+	runner *readmodel.Runner
+}
+
+func newMealReadModel(db *sql.DB, es *sqlStore.SQLite) (*mealReadModel, error) {
+	runner, err := readmodel.NewRunner(db, es, readmodel.Projection{
+		Name: "meals",
+		Tables: []string{
+			`CREATE TABLE IF NOT EXISTS meal_read_model (
+				id   VARCHAR PRIMARY KEY,
+				name VARCHAR NOT NULL,
+				data TEXT NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS meal_read_model_name ON meal_read_model (name);`,
+		},
+		Handle: handleMealEvent,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &mealReadModel{db: db, runner: runner}, nil
+}
+
+func handleMealEvent(tx *sql.Tx, ev eventsourcing.Event) error {
+	if ev.AggregateType() != "Meal" {
+		return nil
+	}
+
+	m := &Meal{Ingredients: []Ingredient{}}
+
+	var data []byte
+	err := tx.QueryRow(`SELECT data FROM meal_read_model WHERE id = ?`, ev.AggregateID()).Scan(&data)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, m); err != nil {
+			return err
+		}
+	}
+
+	m.Transition(ev)
+
+	data, err = json.Marshal(m)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO meal_read_model (id, name, data) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, data = excluded.data`,
+		ev.AggregateID(), m.Name, data,
+	)
+
+	return err
+}
+
+func (r *mealReadModel) all() ([]*Meal, error) {
+	if err := r.runner.CatchUp(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT data FROM meal_read_model ORDER BY name`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	meals := []*Meal{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		m := &Meal{}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, err
+		}
+
+		if !m.Archived {
+			meals = append(meals, m)
+		}
+	}
+
+	return meals, rows.Err()
+}
+
+func (r *mealReadModel) findByName(name string) (*Meal, error) {
+	if err := r.runner.CatchUp(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT data FROM meal_read_model WHERE name = ?`, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		m := &Meal{}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, err
+		}
+
+		if !m.Archived {
+			return m, nil
+		}
+	}
+
+	return nil, rows.Err()
+}
@@ -3,11 +3,15 @@ package meal
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/eventstore/memory"
-	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	snapshotStore "github.com/hallgren/eventsourcing/snapshotstore/memory"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/snapshot"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	_ "github.com/mattn/go-sqlite3"
 	"sort"
 )
@@ -17,40 +21,87 @@ type MealRepository interface {
 	Find(id string) (*Meal, error)
 	Save(m *Meal) error
 	FindByName(name string) (*Meal, error)
+	In(unit *unitofwork.Unit) MealRepository
 }
 
 type EventSourcedMealRepository struct {
-	es  core.EventStore
-	all func() (core.Iterator, error)
+	es        core.EventStore
+	all       func() (core.Iterator, error)
+	readModel *mealReadModel
+	snapshots *snapshot.Snapshotter
 }
 
 func NewMealRepository(es core.EventStore, all func() (core.Iterator, error)) *EventSourcedMealRepository {
 	aggregate.Register(&Meal{})
-	r := &EventSourcedMealRepository{es, all}
+	r := &EventSourcedMealRepository{es: es, all: all}
 	return r
 }
 
 func NewSqliteMealRepository(db *sql.DB) (*EventSourcedMealRepository, error) {
-	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	es, err := database.NewEventStore(db)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return NewMealRepository(es, func() (core.Iterator, error) {
+	r := NewMealRepository(es, func() (core.Iterator, error) {
 		return es.All(0)()
-	}), nil
+	})
+
+	r.readModel, err = newMealReadModel(db, es.SQLite)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.snapshots, err = snapshot.NewSqliteSnapshotter(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 func NewFakeMealRepository() *EventSourcedMealRepository {
 	es := memory.Create()
 
-	return NewMealRepository(es, func() (core.Iterator, error) {
+	r := NewMealRepository(es, func() (core.Iterator, error) {
 		return es.All(0, 10000)()
 	})
+
+	r.snapshots = &snapshot.Snapshotter{Store: snapshotStore.Create(), Interval: snapshot.DefaultInterval}
+
+	return r
+}
+
+// WithSnapshotInterval returns a copy of the repository sharing its stores but
+// snapshotting meals at the given interval. Zero turns snapshots off.
+func (r EventSourcedMealRepository) WithSnapshotInterval(interval int) *EventSourcedMealRepository {
+	r.snapshots = r.snapshots.WithInterval(interval)
+	return &r
+}
+
+// SnapshotStore returns the store the repository keeps meal snapshots in.
+func (r EventSourcedMealRepository) SnapshotStore() core.SnapshotStore {
+	if r.snapshots == nil {
+		return nil
+	}
+
+	return r.snapshots.Store
+}
+
+// In returns a copy of the repository that saves meals as part of the unit.
+func (r EventSourcedMealRepository) In(unit *unitofwork.Unit) MealRepository {
+	r.es = unit.Store(r.es)
+	return &r
 }
 
 func (r EventSourcedMealRepository) Get() ([]*Meal, error) {
+	if r.readModel != nil {
+		return r.readModel.all()
+	}
+
 	mealMap := map[string]*Meal{}
 
 	p := eventsourcing.NewProjection(
@@ -81,7 +132,9 @@ func (r EventSourcedMealRepository) Get() ([]*Meal, error) {
 
 	meals := make([]*Meal, 0, len(mealMap))
 	for _, m := range mealMap {
-		meals = append(meals, m)
+		if !m.Archived {
+			meals = append(meals, m)
+		}
 	}
 
 	sort.Slice(meals, func(i, j int) bool {
@@ -93,6 +146,14 @@ func (r EventSourcedMealRepository) Get() ([]*Meal, error) {
 
 func (r EventSourcedMealRepository) Find(id string) (*Meal, error) {
 	m := &Meal{}
+
+	if r.snapshots.Enabled() {
+		err := aggregate.LoadSnapshot(context.Background(), r.snapshots.Store, id, m)
+		if err != nil && !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+			return nil, err
+		}
+	}
+
 	err := aggregate.Load(context.Background(), r.es, id, m)
 	if err != nil {
 		return nil, err
@@ -102,10 +163,32 @@ func (r EventSourcedMealRepository) Find(id string) (*Meal, error) {
 }
 
 func (r EventSourcedMealRepository) Save(m *Meal) error {
-	return aggregate.Save(r.es, m)
+	saved := m.Version() - eventsourcing.Version(len(m.Events()))
+
+	err := aggregate.Save(r.es, m)
+	if err != nil {
+		return err
+	}
+
+	if !r.snapshots.Due(saved, m.Version()) {
+		return nil
+	}
+
+	return unitofwork.AfterCommit(r.es, func() error {
+		loaded, err := r.Find(m.Id)
+		if err != nil {
+			return err
+		}
+
+		return aggregate.SaveSnapshot(r.snapshots.Store, loaded)
+	})
 }
 
 func (r EventSourcedMealRepository) FindByName(name string) (*Meal, error) {
+	if r.readModel != nil {
+		return r.readModel.findByName(name)
+	}
+
 	meals, err := r.Get()
 
 	if err != nil {
@@ -1,11 +1,19 @@
 package meal_test
 
 import (
+	"context"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
 	"github.com/joe-reed/meal-planner/apps/api/internal/database"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestFakeMealRepository(t *testing.T) {
@@ -38,6 +46,13 @@ func runSuite(t *testing.T, factory func() *meal.EventSourcedMealRepository, tea
 		{"finding a meal", testFindingMeal},
 		{"saving a meal", testSavingMeal},
 		{"updating a meal's url", testUpdatingMealUrl},
+		{"updating a meal's servings", testUpdatingMealServings},
+		{"changing an ingredient's quantity", testChangingIngredientQuantity},
+		{"updating a meal's recipe", testUpdatingMealRecipe},
+		{"using a product twice", testUsingProductTwice},
+		{"not reusing ingredient ids", testNotReusingIngredientIds},
+		{"loading a meal from a snapshot", testLoadingMealFromSnapshot},
+		{"hiding archived meals", testHidingArchivedMeals},
 	}
 
 	for _, test := range tests {
@@ -116,14 +131,14 @@ func testSavingMeal(t *testing.T, r *meal.EventSourcedMealRepository) {
 	err := r.Save(m)
 	assert.NoError(t, err)
 
-	m.AddIngredient(*meal.NewIngredient("b"))
+	added := m.AddIngredient(*meal.NewIngredient("b"))
 	err = r.Save(m)
 	assert.NoError(t, err)
 
 	found, err := r.Find(m.Id)
 	assert.NoError(t, err)
 	assert.EqualExportedValues(t, m, found)
-	assert.Equal(t, []meal.Ingredient{*meal.NewIngredient("a"), *meal.NewIngredient("b")}, found.Ingredients)
+	assert.Equal(t, []meal.Ingredient{*meal.NewIngredient("a").WithId(m.Ingredients[0].Id), *meal.NewIngredient("b").WithId(added.Id)}, found.Ingredients)
 }
 
 func testUpdatingMealUrl(t *testing.T, r *meal.EventSourcedMealRepository) {
@@ -140,3 +155,226 @@ func testUpdatingMealUrl(t *testing.T, r *meal.EventSourcedMealRepository) {
 	assert.NoError(t, err)
 	assert.EqualExportedValues(t, m, found)
 }
+
+func testUpdatingMealServings(t *testing.T, r *meal.EventSourcedMealRepository) {
+	m := meal.NewMealBuilder().WithName("a").WithServings(4).Build()
+	err := r.Save(m)
+	assert.NoError(t, err)
+
+	m.UpdateServings(6)
+
+	err = r.Save(m)
+	assert.NoError(t, err)
+
+	found, err := r.Find(m.Id)
+	assert.NoError(t, err)
+	assert.EqualExportedValues(t, m, found)
+	assert.Equal(t, 6, found.Servings)
+}
+
+func testChangingIngredientQuantity(t *testing.T, r *meal.EventSourcedMealRepository) {
+	m := meal.NewMealBuilder().WithName("a").AddIngredient(*meal.NewIngredient("b").WithId("b-1")).Build()
+	err := r.Save(m)
+	assert.NoError(t, err)
+
+	err = m.ChangeIngredientQuantity("b-1", quantity.Quantity{Amount: quantity.NewAmount(200), Unit: quantity.Gram})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, m.ChangeIngredientQuantity("c", quantity.Quantity{}), meal.ErrIngredientNotFound)
+
+	err = r.Save(m)
+	assert.NoError(t, err)
+
+	found, err := r.Find(m.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, []meal.Ingredient{*meal.NewIngredient("b").WithId("b-1").WithQuantity(quantity.NewAmount(200), quantity.Gram)}, found.Ingredients)
+}
+
+func testUsingProductTwice(t *testing.T, r *meal.EventSourcedMealRepository) {
+	m := meal.NewMealBuilder().WithName("a").AddIngredient(*meal.NewIngredient("butter").WithNote("for the roux")).Build()
+	err := r.Save(m)
+	assert.NoError(t, err)
+
+	roux := m.Ingredients[0]
+	greasing := m.AddIngredient(*meal.NewIngredient("butter"))
+	assert.NotEqual(t, roux.Id, greasing.Id)
+	assert.NoError(t, m.ChangeIngredientNote(greasing.Id, "for greasing"))
+	flour := m.AddIngredient(*meal.NewIngredient("flour"))
+	assert.NoError(t, m.RemoveIngredient(roux.Id))
+
+	err = r.Save(m)
+	assert.NoError(t, err)
+
+	found, err := r.Find(m.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, []meal.Ingredient{
+		*meal.NewIngredient("butter").WithId(greasing.Id).WithNote("for greasing"),
+		*meal.NewIngredient("flour").WithId(flour.Id),
+	}, found.Ingredients)
+}
+
+func testNotReusingIngredientIds(t *testing.T, r *meal.EventSourcedMealRepository) {
+	m := meal.NewMealBuilder().WithName("a").AddIngredient(*meal.NewIngredient("butter")).Build()
+	removed := m.Ingredients[0]
+	assert.NoError(t, m.RemoveIngredient(removed.Id))
+	assert.ErrorIs(t, m.RemoveIngredient(removed.Id), meal.ErrIngredientNotFound)
+	added := m.AddIngredient(*meal.NewIngredient("butter"))
+
+	err := r.Save(m)
+	assert.NoError(t, err)
+
+	found, err := r.Find(m.Id)
+	assert.NoError(t, err)
+	assert.NotEqual(t, removed.Id, added.Id)
+	assert.Equal(t, []meal.Ingredient{*meal.NewIngredient("butter").WithId(added.Id)}, found.Ingredients)
+}
+
+func testLoadingMealFromSnapshot(t *testing.T, r *meal.EventSourcedMealRepository) {
+	r = r.WithSnapshotInterval(3)
+
+	m := meal.NewMealBuilder().WithName("a").WithServings(2).Build()
+	err := r.Save(m)
+	assert.NoError(t, err)
+
+	var second meal.Ingredient
+	for i := 1; i <= 7; i++ {
+		added := m.AddIngredient(*meal.NewIngredient(strconv.Itoa(i)).WithQuantity(quantity.NewFraction(int64(i), 3), quantity.Gram))
+		m.UpdateServings(i)
+
+		if i == 2 {
+			second = added
+		}
+
+		err = r.Save(m)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, m.RemoveIngredient(second.Id))
+	err = r.Save(m)
+	assert.NoError(t, err)
+
+	snapshotted := &meal.Meal{}
+	err = aggregate.LoadSnapshot(context.Background(), r.SnapshotStore(), m.Id, snapshotted)
+	assert.NoError(t, err)
+	assert.Equal(t, eventsourcing.Version(15), snapshotted.Version())
+
+	found, err := r.Find(m.Id)
+	assert.NoError(t, err)
+
+	replayed, err := r.WithSnapshotInterval(0).Find(m.Id)
+	assert.NoError(t, err)
+
+	assert.EqualExportedValues(t, replayed, found)
+	assert.EqualExportedValues(t, m, found)
+	assert.Equal(t, replayed.Version(), found.Version())
+	assert.Equal(t, replayed.GlobalVersion(), found.GlobalVersion())
+}
+
+func TestLoadingMealSavedWithIntegerAmounts(t *testing.T) {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(t, err)
+
+	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	assert.NoError(t, err)
+
+	r, err := meal.NewSqliteMealRepository(db)
+	assert.NoError(t, err)
+
+	err = es.Save([]core.Event{{
+		AggregateID:   "123",
+		Version:       1,
+		AggregateType: "Meal",
+		Timestamp:     time.Now(),
+		Reason:        "Created",
+		Data:          []byte(`{"Id":"123","Name":"a","Url":"","Ingredients":[{"id":"a","quantity":{"amount":300,"unit":"Gram"}}]}`),
+	}})
+	assert.NoError(t, err)
+
+	found, err := r.Find("123")
+	assert.NoError(t, err)
+	assert.Equal(t, []meal.Ingredient{*meal.NewIngredient("a").WithId("a").WithQuantity(quantity.NewAmount(300), quantity.Gram)}, found.Ingredients)
+}
+
+func TestLoadingMealWithIngredientsSavedBeforeTheyHadIds(t *testing.T) {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(t, err)
+
+	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	assert.NoError(t, err)
+
+	r, err := meal.NewSqliteMealRepository(db)
+	assert.NoError(t, err)
+
+	events := []struct {
+		reason string
+		data   string
+	}{
+		{"Created", `{"Id":"123","Name":"a","Url":"","Ingredients":[{"id":"butter","quantity":{"amount":1,"unit":"Number"}},{"id":"flour","quantity":{"amount":1,"unit":"Number"}}]}`},
+		{"IngredientAdded", `{"Ingredient":{"id":"butter","quantity":{"amount":2,"unit":"Number"}}}`},
+		{"IngredientQuantityChanged", `{"Id":"flour","Quantity":{"amount":200,"unit":"Gram"}}`},
+		{"IngredientRemoved", `{"Id":"butter"}`},
+	}
+
+	for i, ev := range events {
+		err = es.Save([]core.Event{{
+			AggregateID:   "123",
+			Version:       core.Version(i + 1),
+			AggregateType: "Meal",
+			Timestamp:     time.Now(),
+			Reason:        ev.reason,
+			Data:          []byte(ev.data),
+		}})
+		assert.NoError(t, err)
+	}
+
+	found, err := r.Find("123")
+	assert.NoError(t, err)
+	assert.Equal(t, []meal.Ingredient{*meal.NewIngredient("flour").WithId("flour").WithQuantity(quantity.NewAmount(200), quantity.Gram)}, found.Ingredients)
+}
+
+func testHidingArchivedMeals(t *testing.T, r *meal.EventSourcedMealRepository) {
+	a := meal.NewMealBuilder().WithName("a").Build()
+	b := meal.NewMealBuilder().WithName("b").Build()
+	assert.NoError(t, r.Save(a))
+	assert.NoError(t, r.Save(b))
+
+	a.Archive()
+	assert.NoError(t, r.Save(a))
+
+	meals, err := r.Get()
+	assert.NoError(t, err)
+	assert.Len(t, meals, 1)
+	assert.Equal(t, b.Id, meals[0].Id)
+
+	found, err := r.FindByName("a")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	archived, err := r.Find(a.Id)
+	assert.NoError(t, err)
+	assert.True(t, archived.Archived)
+}
+
+func testUpdatingMealRecipe(t *testing.T, r *meal.EventSourcedMealRepository) {
+	m := meal.NewMealBuilder().WithName("a").WithSteps("Boil the pasta").Build()
+	err := r.Save(m)
+	assert.NoError(t, err)
+
+	m.UpdateSteps([]string{"Boil the pasta", "Stir in the pesto"})
+	m.UpdateTimes(5, 10)
+	m.UpdateTags([]string{"vegetarian", "italian"})
+	err = r.Save(m)
+	assert.NoError(t, err)
+
+	found, err := r.Find(m.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Boil the pasta", "Stir in the pesto"}, found.Steps)
+	assert.Equal(t, 5, found.PrepTime)
+	assert.Equal(t, 10, found.CookTime)
+	assert.Equal(t, []string{"vegetarian", "italian"}, found.Tags)
+
+	meals, err := r.Get()
+	assert.NoError(t, err)
+	assert.Len(t, meals, 1)
+	assert.Equal(t, 15, meals[0].TotalTime())
+	assert.Equal(t, []string{"vegetarian", "italian"}, meals[0].Tags)
+}
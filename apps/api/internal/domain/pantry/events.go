@@ -0,0 +1,19 @@
+package pantry
+
+import "github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+
+type Created struct{}
+
+type StockSet struct {
+	ProductId string
+	Quantity  quantity.Quantity
+}
+
+type StockAdjusted struct {
+	ProductId string
+	Quantity  quantity.Quantity
+}
+
+type StockCleared struct {
+	ProductId string
+}
@@ -0,0 +1,108 @@
+package pantry
+
+import (
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+)
+
+// Id identifies the household's single pantry.
+const Id = "pantry"
+
+type Pantry struct {
+	aggregate.Root
+	Items []*Item `json:"items"`
+}
+
+type Item struct {
+	ProductId string            `json:"productId"`
+	Quantity  quantity.Quantity `json:"quantity"`
+}
+
+func (p *Pantry) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Created:
+		p.Items = []*Item{}
+	case *StockSet:
+		p.setStock(e.ProductId, e.Quantity)
+	case *StockAdjusted:
+		current := p.Stock(e.ProductId)
+		if current == nil {
+			p.setStock(e.ProductId, e.Quantity)
+			break
+		}
+		converted, _ := quantity.Convert(e.Quantity, current.Unit)
+		p.setStock(e.ProductId, quantity.Quantity{Amount: current.Amount.Add(converted.Amount), Unit: current.Unit})
+	case *StockCleared:
+		p.setStock(e.ProductId, quantity.Quantity{})
+	}
+}
+
+func (p *Pantry) Register(r aggregate.RegisterFunc) {
+	r(&Created{}, &StockSet{}, &StockAdjusted{}, &StockCleared{})
+}
+
+func NewPantry() (*Pantry, error) {
+	p := &Pantry{}
+
+	err := p.SetID(Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate.TrackChange(p, &Created{})
+
+	return p, nil
+}
+
+func (p *Pantry) Stock(productId string) *quantity.Quantity {
+	for _, i := range p.Items {
+		if i.ProductId == productId {
+			return &i.Quantity
+		}
+	}
+
+	return nil
+}
+
+func (p *Pantry) SetStock(productId string, q quantity.Quantity) *Pantry {
+	aggregate.TrackChange(p, &StockSet{ProductId: productId, Quantity: q})
+	return p
+}
+
+// AdjustStock adds to (or, with a negative amount, takes from) the stock of a
+// product. The adjustment must be in a unit compatible with the current stock.
+func (p *Pantry) AdjustStock(productId string, q quantity.Quantity) error {
+	if current := p.Stock(productId); current != nil {
+		if _, err := quantity.Convert(q, current.Unit); err != nil {
+			return err
+		}
+	}
+
+	aggregate.TrackChange(p, &StockAdjusted{ProductId: productId, Quantity: q})
+
+	return nil
+}
+
+func (p *Pantry) ClearStock(productId string) *Pantry {
+	aggregate.TrackChange(p, &StockCleared{ProductId: productId})
+	return p
+}
+
+// setStock replaces the stock of a product, dropping it from the pantry once
+// none is left.
+func (p *Pantry) setStock(productId string, q quantity.Quantity) {
+	items := []*Item{}
+	for _, i := range p.Items {
+		if i.ProductId != productId {
+			items = append(items, i)
+		}
+	}
+
+	if q.Amount.Cmp(quantity.NewAmount(0)) > 0 {
+		items = append(items, &Item{ProductId: productId, Quantity: q})
+	}
+
+	p.Items = items
+}
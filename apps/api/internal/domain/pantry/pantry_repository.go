@@ -0,0 +1,64 @@
+package pantry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type PantryRepository struct {
+	es core.EventStore
+}
+
+func NewPantryRepository(es core.EventStore) *PantryRepository {
+	aggregate.Register(&Pantry{})
+	return &PantryRepository{es}
+}
+
+func NewSqlitePantryRepository(db *sql.DB) (*PantryRepository, error) {
+	es, err := database.NewEventStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPantryRepository(es), nil
+}
+
+func NewFakePantryRepository() *PantryRepository {
+	return NewPantryRepository(memory.Create())
+}
+
+// In returns a copy of the repository that saves the pantry as part of the
+// unit.
+func (r PantryRepository) In(unit *unitofwork.Unit) *PantryRepository {
+	r.es = unit.Store(r.es)
+	return &r
+}
+
+// Get loads the pantry, starting an empty one if nothing has been stocked yet.
+func (r PantryRepository) Get() (*Pantry, error) {
+	p := &Pantry{}
+	err := aggregate.Load(context.Background(), r.es, Id, p)
+
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return NewPantry()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (r PantryRepository) Save(p *Pantry) error {
+	return aggregate.Save(r.es, p)
+}
@@ -0,0 +1,135 @@
+package pantry_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestFakePantryRepository(t *testing.T) {
+	runSuite(t, func() *pantry.PantryRepository {
+		return pantry.NewFakePantryRepository()
+	}, func() {})
+}
+
+func TestSqlitePantryRepository(t *testing.T) {
+	runSuite(t, func() *pantry.PantryRepository {
+		db, err := database.CreateDatabase("test.db")
+		assert.NoError(t, err)
+		r, err := pantry.NewSqlitePantryRepository(db)
+		assert.NoError(t, err)
+		return r
+	}, func() {
+		err := os.Remove("test.db")
+		assert.NoError(t, err)
+	})
+}
+
+func runSuite(t *testing.T, factory func() *pantry.PantryRepository, teardown func()) {
+	tests := []struct {
+		title string
+		run   func(t *testing.T, r *pantry.PantryRepository)
+	}{
+		{"getting empty pantry", testGettingEmptyPantry},
+		{"setting stock", testSettingStock},
+		{"adjusting stock", testAdjustingStock},
+		{"adjusting stock with incompatible unit", testAdjustingStockWithIncompatibleUnit},
+		{"using up stock", testUsingUpStock},
+		{"clearing stock", testClearingStock},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.run(t, factory())
+			teardown()
+		})
+	}
+}
+
+func testGettingEmptyPantry(t *testing.T, r *pantry.PantryRepository) {
+	p, err := r.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []*pantry.Item{}, p.Items)
+}
+
+func testSettingStock(t *testing.T, r *pantry.PantryRepository) {
+	p, err := r.Get()
+	assert.NoError(t, err)
+
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+	p.SetStock("eggs", quantity.Quantity{Amount: quantity.NewAmount(6), Unit: quantity.Number})
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewAmount(500), Unit: quantity.Gram})
+
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	found, err := r.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []*pantry.Item{
+		{ProductId: "eggs", Quantity: quantity.Quantity{Amount: quantity.NewAmount(6), Unit: quantity.Number}},
+		{ProductId: "rice", Quantity: quantity.Quantity{Amount: quantity.NewAmount(500), Unit: quantity.Gram}},
+	}, found.Items)
+}
+
+func testAdjustingStock(t *testing.T, r *pantry.PantryRepository) {
+	p, err := r.Get()
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.AdjustStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg}))
+	assert.NoError(t, p.AdjustStock("rice", quantity.Quantity{Amount: quantity.NewAmount(-250), Unit: quantity.Gram}))
+
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	found, err := r.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, &quantity.Quantity{Amount: quantity.NewFraction(3, 4), Unit: quantity.Kg}, found.Stock("rice"))
+}
+
+func testAdjustingStockWithIncompatibleUnit(t *testing.T, r *pantry.PantryRepository) {
+	p, err := r.Get()
+	assert.NoError(t, err)
+
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+
+	err = p.AdjustStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Cup})
+	assert.Error(t, err)
+	assert.Equal(t, &quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg}, p.Stock("rice"))
+}
+
+func testUsingUpStock(t *testing.T, r *pantry.PantryRepository) {
+	p, err := r.Get()
+	assert.NoError(t, err)
+
+	p.SetStock("milk", quantity.Quantity{Amount: quantity.NewAmount(500), Unit: quantity.Ml})
+	assert.NoError(t, p.AdjustStock("milk", quantity.Quantity{Amount: quantity.NewAmount(-1), Unit: quantity.Litre}))
+
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	found, err := r.Get()
+	assert.NoError(t, err)
+	assert.Nil(t, found.Stock("milk"))
+	assert.Equal(t, []*pantry.Item{}, found.Items)
+}
+
+func testClearingStock(t *testing.T, r *pantry.PantryRepository) {
+	p, err := r.Get()
+	assert.NoError(t, err)
+
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+	p.SetStock("eggs", quantity.Quantity{Amount: quantity.NewAmount(6), Unit: quantity.Number})
+	p.ClearStock("rice")
+
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	found, err := r.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []*pantry.Item{
+		{ProductId: "eggs", Quantity: quantity.Quantity{Amount: quantity.NewAmount(6), Unit: quantity.Number}},
+	}, found.Items)
+}
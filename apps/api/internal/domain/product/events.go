@@ -1,9 +1,34 @@
 package product
 
-import "github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+)
 
 type Created struct {
 	Id       string
 	Name     string
 	Category category.CategoryName
 }
+
+type Renamed struct {
+	Name string
+}
+
+type CategoryChanged struct {
+	Category category.CategoryName
+}
+
+// DefaultQuantitySet records how much of the product is usually used when
+// it's added to a meal.
+type DefaultQuantitySet struct {
+	Quantity quantity.Quantity
+}
+
+type Archived struct{}
+
+// Merged is recorded against a duplicate product once it has been folded
+// into another one.
+type Merged struct {
+	IntoProductId string
+}
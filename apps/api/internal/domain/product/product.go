@@ -6,8 +6,11 @@ import (
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 )
 
+var ErrProductArchived = errors.New("product has been archived")
+
 type ProductName string
 
 func (i ProductName) String() string {
@@ -23,9 +26,12 @@ func NewProductName(name string) (ProductName, error) {
 
 type Product struct {
 	aggregate.Root
-	Id       string                `json:"id"`
-	Name     ProductName           `json:"name"`
-	Category category.CategoryName `json:"category"`
+	Id              string                `json:"id"`
+	Name            ProductName           `json:"name"`
+	Category        category.CategoryName `json:"category"`
+	DefaultQuantity *quantity.Quantity    `json:"defaultQuantity,omitempty"`
+	Archived        bool                  `json:"archived,omitempty"`
+	MergedInto      string                `json:"mergedInto,omitempty"`
 }
 
 func (m *Product) Transition(event eventsourcing.Event) {
@@ -34,11 +40,31 @@ func (m *Product) Transition(event eventsourcing.Event) {
 		m.Id = e.Id
 		m.Name = ProductName(e.Name)
 		m.Category = e.Category
+	case *Renamed:
+		m.Name = ProductName(e.Name)
+	case *CategoryChanged:
+		m.Category = e.Category
+	case *DefaultQuantitySet:
+		q := e.Quantity
+		m.DefaultQuantity = &q
+	case *Archived:
+		m.Archived = true
+	case *Merged:
+		m.Archived = true
+		m.MergedInto = e.IntoProductId
 	}
 }
 
 func (m *Product) Register(r aggregate.RegisterFunc) {
-	r(&Created{})
+	r(&Created{}, &Renamed{}, &CategoryChanged{}, &DefaultQuantitySet{}, &Archived{}, &Merged{})
+}
+
+func (m *Product) SerializeSnapshot(f aggregate.SnapshotMarshal) ([]byte, error) {
+	return f(m)
+}
+
+func (m *Product) DeserializeSnapshot(f aggregate.SnapshotUnmarshal, d []byte) error {
+	return f(d, m)
 }
 
 func NewProduct(id string, name ProductName, category category.CategoryName) (*Product, error) {
@@ -52,6 +78,69 @@ func NewProduct(id string, name ProductName, category category.CategoryName) (*P
 	return i, nil
 }
 
+func (m *Product) Rename(name ProductName) error {
+	if m.Archived {
+		return ErrProductArchived
+	}
+
+	if name != m.Name {
+		aggregate.TrackChange(m, &Renamed{Name: name.String()})
+	}
+
+	return nil
+}
+
+func (m *Product) ChangeCategory(c category.CategoryName) error {
+	if m.Archived {
+		return ErrProductArchived
+	}
+
+	if c != m.Category {
+		aggregate.TrackChange(m, &CategoryChanged{Category: c})
+	}
+
+	return nil
+}
+
+func (m *Product) SetDefaultQuantity(q quantity.Quantity) error {
+	if m.Archived {
+		return ErrProductArchived
+	}
+
+	if m.DefaultQuantity == nil || *m.DefaultQuantity != q {
+		aggregate.TrackChange(m, &DefaultQuantitySet{Quantity: q})
+	}
+
+	return nil
+}
+
+// IngredientQuantity is how much of the product a meal gets when it's added
+// without a quantity: its default, or a single one when it has none.
+func (m *Product) IngredientQuantity() quantity.Quantity {
+	if m.DefaultQuantity != nil {
+		return *m.DefaultQuantity
+	}
+
+	return quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Number}
+}
+
+func (m *Product) Archive() {
+	if !m.Archived {
+		aggregate.TrackChange(m, &Archived{})
+	}
+}
+
+// MergeInto marks the product as a duplicate of another, archiving it.
+func (m *Product) MergeInto(productId string) error {
+	if m.Archived {
+		return ErrProductArchived
+	}
+
+	aggregate.TrackChange(m, &Merged{IntoProductId: productId})
+
+	return nil
+}
+
 type ProductBuilder struct {
 	id       string
 	name     ProductName
@@ -0,0 +1,141 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/readmodel"
+)
+
+// productReadModel keeps the latest state of every product in a table, so
+// products can be listed and looked up by name without replaying the event
+// log.
+type productReadModel struct {
+	db     *sql.DB
+	runner *readmodel.Runner
+}
+
+func newProductReadModel(db *sql.DB, es *sqlStore.SQLite) (*productReadModel, error) {
+	runner, err := readmodel.NewRunner(db, es, readmodel.Projection{
+		Name: "products",
+		Tables: []string{
+			`CREATE TABLE IF NOT EXISTS product_read_model (
+				id   VARCHAR PRIMARY KEY,
+				name VARCHAR NOT NULL,
+				data TEXT NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS product_read_model_name ON product_read_model (name);`,
+		},
+		Handle: handleProductEvent,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &productReadModel{db: db, runner: runner}, nil
+}
+
+func handleProductEvent(tx *sql.Tx, ev eventsourcing.Event) error {
+	if ev.AggregateType() != "Product" {
+		return nil
+	}
+
+	p := &Product{}
+
+	var data []byte
+	err := tx.QueryRow(`SELECT data FROM product_read_model WHERE id = ?`, ev.AggregateID()).Scan(&data)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, p); err != nil {
+			return err
+		}
+	}
+
+	p.Transition(ev)
+
+	data, err = json.Marshal(p)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO product_read_model (id, name, data) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, data = excluded.data`,
+		ev.AggregateID(), p.Name.String(), data,
+	)
+
+	return err
+}
+
+func (r *productReadModel) all() ([]*Product, error) {
+	if err := r.runner.CatchUp(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT data FROM product_read_model ORDER BY name`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	products := []*Product{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		p := &Product{}
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, err
+		}
+
+		if !p.Archived {
+			products = append(products, p)
+		}
+	}
+
+	return products, rows.Err()
+}
+
+func (r *productReadModel) findByName(name ProductName) (*Product, error) {
+	if err := r.runner.CatchUp(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT data FROM product_read_model WHERE name = ?`, name.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		p := &Product{}
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, err
+		}
+
+		if !p.Archived {
+			return p, nil
+		}
+	}
+
+	return nil, rows.Err()
+}
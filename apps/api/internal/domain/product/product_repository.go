@@ -1,13 +1,18 @@
 package product
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/eventstore/memory"
-	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	snapshotStore "github.com/hallgren/eventsourcing/snapshotstore/memory"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/snapshot"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"sort"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -15,46 +20,135 @@ import (
 
 type ProductRepository interface {
 	Add(i *Product) error
+	Save(p *Product) error
+	Find(id string) (*Product, error)
 	Get() ([]*Product, error)
 	GetByName(name ProductName) (*Product, error)
 	FindByName(name ProductName) (*Product, error)
+	In(unit *unitofwork.Unit) ProductRepository
 }
 
 type EventSourcedProductRepository struct {
-	es  core.EventStore
-	all func() (core.Iterator, error)
+	es        core.EventStore
+	all       func() (core.Iterator, error)
+	readModel *productReadModel
+	snapshots *snapshot.Snapshotter
 }
 
 func NewProductRepository(es core.EventStore, all func() (core.Iterator, error)) *EventSourcedProductRepository {
 	aggregate.Register(&Product{})
-	return &EventSourcedProductRepository{es, all}
+	return &EventSourcedProductRepository{es: es, all: all}
 }
 
 func NewSqliteProductRepository(db *sql.DB) (*EventSourcedProductRepository, error) {
-	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	es, err := database.NewEventStore(db)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return NewProductRepository(es, func() (core.Iterator, error) {
+	r := NewProductRepository(es, func() (core.Iterator, error) {
 		return es.All(0)()
-	}), nil
+	})
+
+	r.readModel, err = newProductReadModel(db, es.SQLite)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.snapshots, err = snapshot.NewSqliteSnapshotter(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 func NewFakeProductRepository() *EventSourcedProductRepository {
 	es := memory.Create()
 
-	return NewProductRepository(es, func() (core.Iterator, error) {
+	r := NewProductRepository(es, func() (core.Iterator, error) {
 		return es.All(0, 10000)()
 	})
+
+	r.snapshots = &snapshot.Snapshotter{Store: snapshotStore.Create(), Interval: snapshot.DefaultInterval}
+
+	return r
+}
+
+// WithSnapshotInterval returns a copy of the repository sharing its stores but
+// snapshotting products at the given interval. Zero turns snapshots off.
+func (r EventSourcedProductRepository) WithSnapshotInterval(interval int) *EventSourcedProductRepository {
+	r.snapshots = r.snapshots.WithInterval(interval)
+	return &r
+}
+
+// SnapshotStore returns the store the repository keeps product snapshots in.
+func (r EventSourcedProductRepository) SnapshotStore() core.SnapshotStore {
+	if r.snapshots == nil {
+		return nil
+	}
+
+	return r.snapshots.Store
+}
+
+// In returns a copy of the repository that saves products as part of the unit.
+func (r EventSourcedProductRepository) In(unit *unitofwork.Unit) ProductRepository {
+	r.es = unit.Store(r.es)
+	return &r
 }
 
 func (r EventSourcedProductRepository) Add(i *Product) error {
-	return aggregate.Save(r.es, i)
+	return r.Save(i)
+}
+
+func (r EventSourcedProductRepository) Save(i *Product) error {
+	saved := i.Version() - eventsourcing.Version(len(i.Events()))
+
+	err := aggregate.Save(r.es, i)
+	if err != nil {
+		return err
+	}
+
+	if !r.snapshots.Due(saved, i.Version()) {
+		return nil
+	}
+
+	return unitofwork.AfterCommit(r.es, func() error {
+		loaded, err := r.Find(i.Id)
+		if err != nil {
+			return err
+		}
+
+		return aggregate.SaveSnapshot(r.snapshots.Store, loaded)
+	})
+}
+
+func (r EventSourcedProductRepository) Find(id string) (*Product, error) {
+	p := &Product{}
+
+	if r.snapshots.Enabled() {
+		err := aggregate.LoadSnapshot(context.Background(), r.snapshots.Store, id, p)
+		if err != nil && !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+			return nil, err
+		}
+	}
+
+	err := aggregate.Load(context.Background(), r.es, id, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
 }
 
 func (r EventSourcedProductRepository) Get() ([]*Product, error) {
+	if r.readModel != nil {
+		return r.readModel.all()
+	}
+
 	productMap := map[string]*Product{}
 
 	p := eventsourcing.NewProjection(
@@ -84,7 +178,9 @@ func (r EventSourcedProductRepository) Get() ([]*Product, error) {
 
 	products := make([]*Product, 0, len(productMap))
 	for _, in := range productMap {
-		products = append(products, in)
+		if !in.Archived {
+			products = append(products, in)
+		}
 	}
 
 	sort.Slice(products, func(i, j int) bool {
@@ -109,6 +205,10 @@ func (r EventSourcedProductRepository) GetByName(name ProductName) (*Product, er
 }
 
 func (r EventSourcedProductRepository) FindByName(name ProductName) (*Product, error) {
+	if r.readModel != nil {
+		return r.readModel.findByName(name)
+	}
+
 	products, err := r.Get()
 
 	if err != nil {
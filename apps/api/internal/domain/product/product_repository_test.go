@@ -1,6 +1,9 @@
 package product_test
 
 import (
+	"context"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/joe-reed/meal-planner/apps/api/internal/database"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
@@ -38,6 +41,9 @@ func runSuite(t *testing.T, factory func() *product.EventSourcedProductRepositor
 		{"getting empty list of products", testGettingZeroProducts},
 		{"getting product by name", testGettingProductByName},
 		{"finding product by name", testFindingProductByName},
+		{"loading product from a snapshot", testLoadingProductFromSnapshot},
+		{"renaming a product", testRenamingProduct},
+		{"hiding archived products", testHidingArchivedProducts},
 	}
 
 	for _, test := range tests {
@@ -117,3 +123,68 @@ func testFindingProductByName(t *testing.T, r *product.EventSourcedProductReposi
 	assert.NoError(t, err)
 	assert.EqualExportedValues(t, found, i)
 }
+
+func testLoadingProductFromSnapshot(t *testing.T, r *product.EventSourcedProductRepository) {
+	r = r.WithSnapshotInterval(1)
+
+	p := product.NewProductBuilder().WithName("a").WithCategory(category.Vegetables).Build()
+	err := r.Add(p)
+	assert.NoError(t, err)
+
+	snapshotted := &product.Product{}
+	err = aggregate.LoadSnapshot(context.Background(), r.SnapshotStore(), p.Id, snapshotted)
+	assert.NoError(t, err)
+	assert.Equal(t, eventsourcing.Version(1), snapshotted.Version())
+
+	found, err := r.Find(p.Id)
+	assert.NoError(t, err)
+
+	replayed, err := r.WithSnapshotInterval(0).Find(p.Id)
+	assert.NoError(t, err)
+
+	assert.EqualExportedValues(t, replayed, found)
+	assert.EqualExportedValues(t, p, found)
+	assert.Equal(t, replayed.Version(), found.Version())
+	assert.Equal(t, replayed.GlobalVersion(), found.GlobalVersion())
+}
+
+func testRenamingProduct(t *testing.T, r *product.EventSourcedProductRepository) {
+	p := product.NewProductBuilder().WithName("Tomatos").WithCategory(category.Fruit).Build()
+	err := r.Add(p)
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Rename("Tomatoes"))
+	assert.NoError(t, p.ChangeCategory(category.Vegetables))
+	assert.NoError(t, r.Save(p))
+
+	found, err := r.FindByName("Tomatoes")
+	assert.NoError(t, err)
+	assert.EqualExportedValues(t, p, found)
+
+	found, err = r.FindByName("Tomatos")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func testHidingArchivedProducts(t *testing.T, r *product.EventSourcedProductRepository) {
+	a := product.NewProductBuilder().WithName("a").Build()
+	b := product.NewProductBuilder().WithName("b").Build()
+	assert.NoError(t, r.Add(a))
+	assert.NoError(t, r.Add(b))
+
+	a.Archive()
+	assert.NoError(t, r.Save(a))
+
+	products, err := r.Get()
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	assert.EqualExportedValues(t, b, products[0])
+
+	found, err := r.FindByName("a")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	archived, err := r.Find(a.Id)
+	assert.NoError(t, err)
+	assert.True(t, archived.Archived)
+}
@@ -0,0 +1,207 @@
+package quantity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Amount is an exact rational number, so that amounts like 1/3 Cup survive
+// arithmetic without rounding. Amounts are always kept in lowest terms, with
+// zero represented by the zero value so that amounts can be compared with ==.
+type Amount struct {
+	Numerator   int64
+	Denominator int64
+}
+
+func NewAmount(n int64) Amount {
+	if n == 0 {
+		return Amount{}
+	}
+
+	return Amount{Numerator: n, Denominator: 1}
+}
+
+func NewFraction(numerator int64, denominator int64) Amount {
+	return fromRat(big.NewRat(numerator, denominator))
+}
+
+// ParseAmount accepts whole numbers ("3"), decimals ("1.5"), fractions ("1/2")
+// and mixed numbers ("1 1/2").
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return Amount{}, errors.New("amount cannot be empty")
+	}
+
+	parts := strings.Fields(s)
+
+	if len(parts) > 2 || (len(parts) == 2 && (!isWhole(parts[0]) || !strings.Contains(parts[1], "/"))) {
+		return Amount{}, fmt.Errorf("invalid amount: %s", s)
+	}
+
+	total := new(big.Rat)
+
+	for _, part := range parts {
+		r, ok := new(big.Rat).SetString(part)
+		if !ok || (len(parts) == 2 && r.Sign() < 0) {
+			return Amount{}, fmt.Errorf("invalid amount: %s", s)
+		}
+
+		total.Add(total, r)
+	}
+
+	if !total.Num().IsInt64() || !total.Denom().IsInt64() {
+		return Amount{}, fmt.Errorf("amount out of range: %s", s)
+	}
+
+	return fromRat(total), nil
+}
+
+func isWhole(s string) bool {
+	r, ok := new(big.Rat).SetString(s)
+	return ok && r.IsInt()
+}
+
+// fromRat converts r to an amount. Arithmetic can produce terms too big for
+// an int64, so rather than let them wrap, fromRat saturates amounts beyond the
+// int64 range and rounds finer fractions towards zero until they fit.
+func fromRat(r *big.Rat) Amount {
+	if !r.Num().IsInt64() || !r.Denom().IsInt64() {
+		r = fitInt64(r)
+	}
+
+	if r.Sign() == 0 {
+		return Amount{}
+	}
+
+	return Amount{Numerator: r.Num().Int64(), Denominator: r.Denom().Int64()}
+}
+
+func fitInt64(r *big.Rat) *big.Rat {
+	limit := big.NewInt(math.MaxInt64)
+	whole := new(big.Int).Quo(r.Num(), r.Denom())
+
+	if new(big.Int).Abs(whole).Cmp(limit) >= 0 {
+		return new(big.Rat).SetInt(limit.Mul(limit, big.NewInt(int64(r.Sign()))))
+	}
+
+	// The largest denominator leaving room for the whole part in the numerator.
+	denominator := new(big.Int).Quo(limit, new(big.Int).Add(new(big.Int).Abs(whole), big.NewInt(1)))
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(denominator))
+
+	return new(big.Rat).SetFrac(new(big.Int).Quo(scaled.Num(), scaled.Denom()), denominator)
+}
+
+func (a Amount) rat() *big.Rat {
+	if a.Denominator == 0 {
+		return new(big.Rat)
+	}
+
+	return big.NewRat(a.Numerator, a.Denominator)
+}
+
+func (a Amount) Add(b Amount) Amount {
+	return fromRat(new(big.Rat).Add(a.rat(), b.rat()))
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	return fromRat(new(big.Rat).Sub(a.rat(), b.rat()))
+}
+
+func (a Amount) Mul(b Amount) Amount {
+	return fromRat(new(big.Rat).Mul(a.rat(), b.rat()))
+}
+
+func (a Amount) Div(b Amount) Amount {
+	return fromRat(new(big.Rat).Quo(a.rat(), b.rat()))
+}
+
+func (a Amount) Cmp(b Amount) int {
+	return a.rat().Cmp(b.rat())
+}
+
+func (a Amount) IsZero() bool {
+	return a.Numerator == 0
+}
+
+func (a Amount) Float64() float64 {
+	f, _ := a.rat().Float64()
+	return f
+}
+
+func (a Amount) String() string {
+	r := a.rat()
+
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	if digits, ok := decimalDigits(r.Denom()); ok {
+		return r.FloatString(digits)
+	}
+
+	return r.String()
+}
+
+// decimalDigits reports how many decimal places are needed to write a
+// fraction with the given denominator exactly, if it terminates at all.
+func decimalDigits(denominator *big.Int) (int, bool) {
+	d := new(big.Int).Set(denominator)
+	twos, fives := 0, 0
+
+	two, five, zero := big.NewInt(2), big.NewInt(5), big.NewInt(0)
+	m := new(big.Int)
+
+	for m.Mod(d, two).Cmp(zero) == 0 {
+		d.Div(d, two)
+		twos++
+	}
+
+	for m.Mod(d, five).Cmp(zero) == 0 {
+		d.Div(d, five)
+		fives++
+	}
+
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+
+	return max(twos, fives), true
+}
+
+// MarshalJSON writes terminating amounts as JSON numbers and anything else
+// (e.g. 1/3) as a fraction string.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if _, ok := decimalDigits(a.rat().Denom()); ok {
+		return []byte(a.String()), nil
+	}
+
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts both numbers and fraction strings. Events stored
+// before amounts were rational hold plain integers, which decode here as whole
+// amounts.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
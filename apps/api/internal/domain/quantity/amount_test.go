@@ -0,0 +1,98 @@
+package quantity_test
+
+import (
+	"encoding/json"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestParsingAmounts(t *testing.T) {
+	tests := map[string]quantity.Amount{
+		"3":     quantity.NewAmount(3),
+		" 3 ":   quantity.NewAmount(3),
+		"0.5":   quantity.NewFraction(1, 2),
+		"1.5":   quantity.NewFraction(3, 2),
+		"1/2":   quantity.NewFraction(1, 2),
+		"2/4":   quantity.NewFraction(1, 2),
+		"1 1/2": quantity.NewFraction(3, 2),
+		"1/3":   quantity.NewFraction(1, 3),
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			actual, err := quantity.ParseAmount(input)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestParsingInvalidAmounts(t *testing.T) {
+	for _, input := range []string{"", "abc", "1/0", "1 2", "1/2 1/2", "1 -1/2", "1 1/2 1/2"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := quantity.ParseAmount(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	half := quantity.NewFraction(1, 2)
+	third := quantity.NewFraction(1, 3)
+
+	assert.Equal(t, quantity.NewFraction(5, 6), half.Add(third))
+	assert.Equal(t, quantity.NewFraction(1, 6), half.Sub(third))
+	assert.Equal(t, quantity.NewFraction(1, 6), half.Mul(third))
+	assert.Equal(t, quantity.NewFraction(3, 2), half.Div(third))
+	assert.Equal(t, 1, half.Cmp(third))
+	assert.True(t, half.Sub(half).IsZero())
+}
+
+func TestAmountArithmeticOutOfRange(t *testing.T) {
+	largest := quantity.NewAmount(math.MaxInt64)
+
+	assert.Equal(t, largest, largest.Add(quantity.NewAmount(1)))
+	assert.Equal(t, quantity.NewAmount(-math.MaxInt64), quantity.NewAmount(-math.MaxInt64).Sub(quantity.NewAmount(2)))
+	assert.Equal(t, largest, largest.Mul(largest))
+
+	tiny := quantity.NewFraction(1, math.MaxInt64)
+	assert.True(t, tiny.Mul(quantity.NewFraction(1, 3)).IsZero())
+
+	third := quantity.NewFraction(1, math.MaxInt64-1).Add(quantity.NewFraction(1, 3))
+	assert.InDelta(t, 1.0/3, third.Float64(), 1e-15)
+	assert.Positive(t, third.Denominator)
+}
+
+func TestMarshallingAmounts(t *testing.T) {
+	tests := map[string]struct {
+		amount   quantity.Amount
+		expected string
+	}{
+		"whole":    {quantity.NewAmount(300), `300`},
+		"decimal":  {quantity.NewFraction(3, 2), `1.5`},
+		"fraction": {quantity.NewFraction(1, 3), `"1/3"`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := json.Marshal(test.amount)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, string(actual))
+
+			var roundTripped quantity.Amount
+			assert.NoError(t, json.Unmarshal(actual, &roundTripped))
+			assert.Equal(t, test.amount, roundTripped)
+		})
+	}
+}
+
+func TestUnmarshallingIntegerAmountQuantities(t *testing.T) {
+	var q quantity.Quantity
+
+	err := json.Unmarshal([]byte(`{"amount":300,"unit":"Gram"}`), &q)
+
+	assert.NoError(t, err)
+	assert.Equal(t, quantity.Quantity{Amount: quantity.NewAmount(300), Unit: quantity.Gram}, q)
+}
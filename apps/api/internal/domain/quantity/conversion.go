@@ -0,0 +1,150 @@
+package quantity
+
+import (
+	"fmt"
+)
+
+type Dimension int
+
+const (
+	Mass Dimension = iota
+	Volume
+	Count
+)
+
+type conversion struct {
+	dimension Dimension
+	// factor converts an amount in the unit to the dimension's base unit (Gram, Ml or Number)
+	factor Amount
+}
+
+var conversions = map[Unit]conversion{
+	Gram:   {Mass, NewAmount(1)},
+	Kg:     {Mass, NewAmount(1000)},
+	Oz:     {Mass, NewFraction(28349523125, 1000000000)},
+	Lb:     {Mass, NewFraction(45359237, 100000)},
+	Ml:     {Volume, NewAmount(1)},
+	Litre:  {Volume, NewAmount(1000)},
+	Tsp:    {Volume, NewAmount(5)},
+	Tbsp:   {Volume, NewAmount(15)},
+	Cup:    {Volume, NewAmount(250)},
+	Number: {Count, NewAmount(1)},
+}
+
+var baseUnits = map[Dimension]Unit{
+	Mass:   Gram,
+	Volume: Ml,
+	Count:  Number,
+}
+
+func (u Unit) Dimension() (Dimension, bool) {
+	c, ok := conversions[u]
+	return c.dimension, ok
+}
+
+func (q Quantity) CompatibleWith(other Quantity) bool {
+	if q.Unit == other.Unit {
+		return true
+	}
+
+	d1, ok1 := q.Unit.Dimension()
+	d2, ok2 := other.Unit.Dimension()
+
+	return ok1 && ok2 && d1 == d2
+}
+
+func Convert(q Quantity, to Unit) (Quantity, error) {
+	if q.Unit == to {
+		return q, nil
+	}
+
+	if !q.CompatibleWith(Quantity{Unit: to}) {
+		return Quantity{}, fmt.Errorf("cannot convert %s to %s", _UnitValueToName[q.Unit], _UnitValueToName[to])
+	}
+
+	amount := q.Amount.Mul(conversions[q.Unit].factor).Div(conversions[to].factor)
+
+	return Quantity{Amount: amount, Unit: to}, nil
+}
+
+// Sum adds together compatible quantities, keeping quantities in units that
+// cannot be converted between (e.g. Bunch and Tin) as separate entries.
+// Entries are returned in the order their unit first appears.
+func Sum(quantities []Quantity) []Quantity {
+	var groups [][]Quantity
+
+	for _, q := range quantities {
+		found := false
+		for i, group := range groups {
+			if group[0].CompatibleWith(q) {
+				groups[i] = append(group, q)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			groups = append(groups, []Quantity{q})
+		}
+	}
+
+	total := make([]Quantity, 0, len(groups))
+	for _, group := range groups {
+		total = append(total, sumGroup(group))
+	}
+
+	return total
+}
+
+func sumGroup(quantities []Quantity) Quantity {
+	unit := quantities[0].Unit
+
+	sameUnit := true
+	for _, q := range quantities {
+		if q.Unit != unit {
+			sameUnit = false
+			break
+		}
+	}
+
+	if !sameUnit {
+		d, _ := unit.Dimension()
+		unit = baseUnits[d]
+	}
+
+	sum := Quantity{Amount: NewAmount(0), Unit: unit}
+	for _, q := range quantities {
+		converted, _ := Convert(q, unit)
+		sum.Amount = sum.Amount.Add(converted.Amount)
+	}
+
+	if sameUnit {
+		return sum
+	}
+
+	return Normalise(sum)
+}
+
+// Normalise expresses a quantity in metric units, using Kg and Litre rather
+// than Gram and Ml once the amount reaches one of them.
+func Normalise(q Quantity) Quantity {
+	d, ok := q.Unit.Dimension()
+	if !ok {
+		return q
+	}
+
+	base, _ := Convert(q, baseUnits[d])
+
+	switch d {
+	case Mass:
+		if base.Amount.Cmp(conversions[Kg].factor) >= 0 {
+			return Quantity{Amount: base.Amount.Div(conversions[Kg].factor), Unit: Kg}
+		}
+	case Volume:
+		if base.Amount.Cmp(conversions[Litre].factor) >= 0 {
+			return Quantity{Amount: base.Amount.Div(conversions[Litre].factor), Unit: Litre}
+		}
+	}
+
+	return base
+}
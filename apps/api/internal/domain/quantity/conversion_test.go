@@ -0,0 +1,80 @@
+package quantity_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConvertingQuantities(t *testing.T) {
+	tests := map[string]struct {
+		from     quantity.Quantity
+		to       quantity.Unit
+		expected quantity.Quantity
+	}{
+		"kg to gram":       {quantity.Quantity{Amount: quantity.NewAmount(2), Unit: quantity.Kg}, quantity.Gram, quantity.Quantity{Amount: quantity.NewAmount(2000), Unit: quantity.Gram}},
+		"lb to gram":       {quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Lb}, quantity.Gram, quantity.Quantity{Amount: quantity.NewFraction(45359237, 100000), Unit: quantity.Gram}},
+		"tbsp to tsp":      {quantity.Quantity{Amount: quantity.NewAmount(2), Unit: quantity.Tbsp}, quantity.Tsp, quantity.Quantity{Amount: quantity.NewAmount(6), Unit: quantity.Tsp}},
+		"litre to cup":     {quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Litre}, quantity.Cup, quantity.Quantity{Amount: quantity.NewAmount(4), Unit: quantity.Cup}},
+		"same unit":        {quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Tin}, quantity.Tin, quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Tin}},
+		"number to number": {quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Number}, quantity.Number, quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Number}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := quantity.Convert(test.from, test.to)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestConvertingIncompatibleQuantities(t *testing.T) {
+	_, err := quantity.Convert(quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg}, quantity.Ml)
+	assert.Error(t, err)
+
+	_, err = quantity.Convert(quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Bunch}, quantity.Handful)
+	assert.Error(t, err)
+}
+
+func TestSummingQuantities(t *testing.T) {
+	tests := map[string]struct {
+		quantities []quantity.Quantity
+		expected   []quantity.Quantity
+	}{
+		"no quantities": {
+			[]quantity.Quantity{},
+			[]quantity.Quantity{},
+		},
+		"same unit": {
+			[]quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Tbsp}, {Amount: quantity.NewAmount(1), Unit: quantity.Tbsp}},
+			[]quantity.Quantity{{Amount: quantity.NewAmount(3), Unit: quantity.Tbsp}},
+		},
+		"compatible units": {
+			[]quantity.Quantity{{Amount: quantity.NewAmount(200), Unit: quantity.Gram}, {Amount: quantity.NewAmount(1), Unit: quantity.Kg}},
+			[]quantity.Quantity{{Amount: quantity.NewFraction(6, 5), Unit: quantity.Kg}},
+		},
+		"compatible units below a kilogram": {
+			[]quantity.Quantity{{Amount: quantity.NewAmount(200), Unit: quantity.Gram}, {Amount: quantity.NewFraction(1, 2), Unit: quantity.Lb}},
+			[]quantity.Quantity{{Amount: quantity.NewFraction(85359237, 200000), Unit: quantity.Gram}},
+		},
+		"fractional amounts": {
+			[]quantity.Quantity{{Amount: quantity.NewFraction(1, 3), Unit: quantity.Cup}, {Amount: quantity.NewFraction(2, 3), Unit: quantity.Cup}},
+			[]quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Cup}},
+		},
+		"compatible units normalised": {
+			[]quantity.Quantity{{Amount: quantity.NewAmount(500), Unit: quantity.Ml}, {Amount: quantity.NewAmount(2), Unit: quantity.Cup}},
+			[]quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Litre}},
+		},
+		"incompatible units": {
+			[]quantity.Quantity{{Amount: quantity.NewAmount(200), Unit: quantity.Gram}, {Amount: quantity.NewAmount(1), Unit: quantity.Kg}, {Amount: quantity.NewAmount(2), Unit: quantity.Tbsp}, {Amount: quantity.NewAmount(1), Unit: quantity.Bunch}, {Amount: quantity.NewAmount(1), Unit: quantity.Tin}, {Amount: quantity.NewAmount(2), Unit: quantity.Bunch}},
+			[]quantity.Quantity{{Amount: quantity.NewFraction(6, 5), Unit: quantity.Kg}, {Amount: quantity.NewAmount(2), Unit: quantity.Tbsp}, {Amount: quantity.NewAmount(3), Unit: quantity.Bunch}, {Amount: quantity.NewAmount(1), Unit: quantity.Tin}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, quantity.Sum(test.quantities))
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package quantity
 
 type Quantity struct {
-	Amount int  `json:"amount"`
-	Unit   Unit `json:"unit"`
+	Amount Amount `json:"amount"`
+	Unit   Unit   `json:"unit"`
+}
+
+func (q Quantity) Scale(factor Amount) Quantity {
+	return Quantity{Amount: q.Amount.Mul(factor), Unit: q.Unit}
 }
@@ -25,3 +25,8 @@ func UnitFromString(s string) (Unit, bool) {
 	v, ok := _UnitNameToValue[s]
 	return v, ok
 }
+
+// Name is the unit as it's written in JSON and CSV, like "Tbsp".
+func (u Unit) Name() string {
+	return _UnitValueToName[u]
+}
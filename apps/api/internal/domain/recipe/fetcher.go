@@ -0,0 +1,116 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	neturl "net/url"
+	"syscall"
+	"time"
+)
+
+const maxPageSize = 5 << 20
+
+// ErrAddressNotAllowed is returned for pages on loopback, private or
+// link-local addresses, so that importing a recipe can't be used to reach the
+// server's own network.
+var ErrAddressNotAllowed = errors.New("address not allowed")
+
+// Fetcher gets the HTML of a recipe's page.
+type Fetcher interface {
+	Fetch(url string) (io.ReadCloser, error)
+}
+
+type HttpFetcher struct {
+	Client *http.Client
+	// AllowPrivate skips the check made on a page's host before it's fetched,
+	// for fetching from local servers in tests.
+	AllowPrivate bool
+}
+
+// NewHttpFetcher creates a fetcher that refuses to connect to loopback,
+// private and link-local addresses, whether a page's host resolves to one
+// when it's fetched or it redirects to one.
+func NewHttpFetcher() *HttpFetcher {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: checkDial}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Pages are fetched directly, so that the addresses checked are theirs
+	// rather than a proxy's.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HttpFetcher{Client: &http.Client{Timeout: 10 * time.Second, Transport: transport}}
+}
+
+func (f *HttpFetcher) Fetch(url string) (io.ReadCloser, error) {
+	if !f.AllowPrivate {
+		if err := checkHost(url); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := f.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", url, res.Status)
+	}
+
+	return http.MaxBytesReader(nil, res.Body, maxPageSize), nil
+}
+
+// checkHost resolves the url's host and refuses it if any of its addresses
+// aren't public.
+func checkHost(url string) error {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(context.Background(), "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return fmt.Errorf("%w: %s", ErrAddressNotAllowed, u.Hostname())
+		}
+	}
+
+	return nil
+}
+
+// checkDial refuses connections to addresses that aren't public. It runs for
+// every connection, including those made to follow redirects, once the
+// address has been resolved.
+func checkDial(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addrPort.Addr())
+	}
+
+	return nil
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return !addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsUnspecified()
+}
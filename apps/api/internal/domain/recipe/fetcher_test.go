@@ -0,0 +1,33 @@
+package recipe_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/recipe"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRefusingToFetchFromPrivateAddresses(t *testing.T) {
+	for _, url := range []string{"http://127.0.0.1/soup", "http://localhost/soup", "http://10.0.0.1/soup", "http://169.254.169.254/latest", "http://[::1]/soup", "http://[::ffff:192.168.0.1]/soup"} {
+		t.Run(url, func(t *testing.T) {
+			_, err := recipe.NewHttpFetcher().Fetch(url)
+
+			assert.ErrorIs(t, err, recipe.ErrAddressNotAllowed)
+		})
+	}
+}
+
+func TestRefusingToConnectToPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	f := recipe.NewHttpFetcher()
+	f.AllowPrivate = true
+
+	_, err := f.Fetch(server.URL)
+
+	assert.ErrorIs(t, err, recipe.ErrAddressNotAllowed)
+}
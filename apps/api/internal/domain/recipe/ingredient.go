@@ -0,0 +1,91 @@
+package recipe
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"regexp"
+	"strings"
+)
+
+// Line is an ingredient line of a recipe, like "2 large onions, finely
+// chopped", split into how much of what product it needs. Quantity is nil
+// when the line doesn't say, as in "salt, to taste".
+type Line struct {
+	Text     string
+	Quantity *quantity.Quantity
+	Product  string
+	Note     string
+}
+
+var vulgarFractions = strings.NewReplacer(
+	"½", " 1/2", "⅓", " 1/3", "⅔", " 2/3", "¼", " 1/4", "¾", " 3/4",
+	"⅕", " 1/5", "⅛", " 1/8", "⅜", " 3/8", "⅝", " 5/8", "⅞", " 7/8",
+	"⁄", "/",
+)
+
+var units = map[string]quantity.Unit{
+	"tsp": quantity.Tsp, "tsps": quantity.Tsp, "teaspoon": quantity.Tsp, "teaspoons": quantity.Tsp,
+	"tbsp": quantity.Tbsp, "tbsps": quantity.Tbsp, "tbs": quantity.Tbsp, "tablespoon": quantity.Tbsp, "tablespoons": quantity.Tbsp,
+	"cup": quantity.Cup, "cups": quantity.Cup,
+	"oz": quantity.Oz, "ounce": quantity.Oz, "ounces": quantity.Oz,
+	"lb": quantity.Lb, "lbs": quantity.Lb, "pound": quantity.Lb, "pounds": quantity.Lb,
+	"g": quantity.Gram, "gram": quantity.Gram, "grams": quantity.Gram,
+	"kg": quantity.Kg, "kgs": quantity.Kg, "kilogram": quantity.Kg, "kilograms": quantity.Kg,
+	"ml": quantity.Ml, "millilitre": quantity.Ml, "millilitres": quantity.Ml, "milliliter": quantity.Ml, "milliliters": quantity.Ml,
+	"l": quantity.Litre, "litre": quantity.Litre, "litres": quantity.Litre, "liter": quantity.Litre, "liters": quantity.Litre,
+	"pinch": quantity.Pinch, "pinches": quantity.Pinch,
+	"bunch": quantity.Bunch, "bunches": quantity.Bunch,
+	"pack": quantity.Pack, "packs": quantity.Pack, "packet": quantity.Pack, "packets": quantity.Pack,
+	"tin": quantity.Tin, "tins": quantity.Tin, "can": quantity.Tin, "cans": quantity.Tin,
+	"handful": quantity.Handful, "handfuls": quantity.Handful,
+}
+
+var (
+	parenthetical = regexp.MustCompile(`\(([^)]*)\)`)
+	amountPrefix  = regexp.MustCompile(`^(\d+(?:\s+\d+/\d+|/\d+|\.\d+)?)(?:\s*(?:-|–|to)\s*\d+(?:/\d+|\.\d+)?)?\s*`)
+)
+
+// ParseLine reads an ingredient line. Ranges like "2-3" take the smaller
+// amount, and anything in brackets or after the first comma becomes the
+// note.
+func ParseLine(s string) Line {
+	line := Line{Text: s}
+
+	s = strings.Join(strings.Fields(vulgarFractions.Replace(s)), " ")
+
+	notes := []string{}
+	for _, match := range parenthetical.FindAllStringSubmatch(s, -1) {
+		if note := strings.TrimSpace(match[1]); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	s = strings.Join(strings.Fields(parenthetical.ReplaceAllString(s, " ")), " ")
+
+	s, note, _ := strings.Cut(s, ",")
+	if note = strings.TrimSpace(note); note != "" {
+		notes = append(notes, note)
+	}
+	line.Note = strings.Join(notes, ", ")
+
+	if match := amountPrefix.FindStringSubmatch(s); match != nil {
+		if amount, err := quantity.ParseAmount(match[1]); err == nil {
+			line.Quantity = &quantity.Quantity{Amount: amount, Unit: quantity.Number}
+			s = s[len(match[0]):]
+
+			if word, rest, _ := strings.Cut(s, " "); word != "" {
+				if unit, ok := units[strings.TrimSuffix(strings.ToLower(word), ".")]; ok {
+					line.Quantity.Unit = unit
+					s = rest
+				}
+			}
+		}
+	}
+
+	s = strings.TrimSpace(s)
+	if len(s) > 3 && strings.EqualFold(s[:3], "of ") {
+		s = s[3:]
+	}
+
+	line.Product = s
+
+	return line
+}
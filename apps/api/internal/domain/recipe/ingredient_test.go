@@ -0,0 +1,35 @@
+package recipe_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/recipe"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParsingIngredientLines(t *testing.T) {
+	q := func(amount quantity.Amount, unit quantity.Unit) *quantity.Quantity {
+		return &quantity.Quantity{Amount: amount, Unit: unit}
+	}
+
+	tests := map[string]recipe.Line{
+		"2 large onions, finely chopped":  {Quantity: q(quantity.NewAmount(2), quantity.Number), Product: "large onions", Note: "finely chopped"},
+		"200g plain flour":                {Quantity: q(quantity.NewAmount(200), quantity.Gram), Product: "plain flour"},
+		"1 1/2 cups milk":                 {Quantity: q(quantity.NewFraction(3, 2), quantity.Cup), Product: "milk"},
+		"½ tsp salt":                      {Quantity: q(quantity.NewFraction(1, 2), quantity.Tsp), Product: "salt"},
+		"1½ Tbsp. olive oil":              {Quantity: q(quantity.NewFraction(3, 2), quantity.Tbsp), Product: "olive oil"},
+		"2-3 cloves garlic":               {Quantity: q(quantity.NewAmount(2), quantity.Number), Product: "cloves garlic"},
+		"1 (400g) can chopped tomatoes":   {Quantity: q(quantity.NewAmount(1), quantity.Tin), Product: "chopped tomatoes", Note: "400g"},
+		"a pinch of salt":                 {Product: "a pinch of salt"},
+		"1 pinch of salt":                 {Quantity: q(quantity.NewAmount(1), quantity.Pinch), Product: "salt"},
+		"Salt and pepper, to taste":       {Product: "Salt and pepper", Note: "to taste"},
+		"0.5 kg potatoes (peeled), diced": {Quantity: q(quantity.NewFraction(1, 2), quantity.Kg), Product: "potatoes", Note: "peeled, diced"},
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			expected.Text = input
+			assert.Equal(t, expected, recipe.ParseLine(input))
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package recipe
+
+import (
+	"encoding/json"
+	"errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrRecipeNotFound = errors.New("no recipe found")
+
+// Recipe is what we read of a schema.org Recipe embedded in a page as
+// JSON-LD. Times are in minutes, with zero meaning they aren't given.
+type Recipe struct {
+	Name        string
+	Url         string
+	Servings    int
+	Ingredients []string
+	Steps       []string
+	PrepTime    int
+	CookTime    int
+	Tags        []string
+}
+
+// Extract finds the first Recipe in a page's JSON-LD scripts. Scripts that
+// aren't valid JSON are skipped, as pages often carry broken ones alongside
+// the recipe.
+func Extract(src io.Reader) (*Recipe, error) {
+	scripts, err := jsonLdScripts(src)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, script := range scripts {
+		var v any
+		if err := json.Unmarshal([]byte(script), &v); err != nil {
+			continue
+		}
+
+		if node := findRecipe(v); node != nil {
+			return fromNode(node), nil
+		}
+	}
+
+	return nil, ErrRecipeNotFound
+}
+
+func jsonLdScripts(src io.Reader) ([]string, error) {
+	scripts := []string{}
+	z := html.NewTokenizer(src)
+	inScript := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return scripts, nil
+			}
+			return nil, z.Err()
+		case html.StartTagToken:
+			t := z.Token()
+			inScript = t.DataAtom == atom.Script && isJsonLd(t)
+		case html.TextToken:
+			if inScript {
+				scripts = append(scripts, string(z.Text()))
+			}
+		case html.EndTagToken:
+			inScript = false
+		}
+	}
+}
+
+func isJsonLd(t html.Token) bool {
+	for _, a := range t.Attr {
+		if a.Key == "type" {
+			mediaType, _, _ := strings.Cut(a.Val, ";")
+			return strings.EqualFold(strings.TrimSpace(mediaType), "application/ld+json")
+		}
+	}
+
+	return false
+}
+
+// findRecipe looks through a JSON-LD document for a Recipe node. Recipes may
+// be the document itself, one of a list, in an @graph or nested in another
+// node, like a WebPage's mainEntity.
+func findRecipe(v any) map[string]any {
+	switch v := v.(type) {
+	case map[string]any:
+		if hasType(v["@type"], "Recipe") {
+			return v
+		}
+
+		for _, child := range v {
+			if node := findRecipe(child); node != nil {
+				return node
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if node := findRecipe(child); node != nil {
+				return node
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasType(v any, t string) bool {
+	switch v := v.(type) {
+	case string:
+		return v == t
+	case []any:
+		for _, s := range v {
+			if s == t {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func fromNode(node map[string]any) *Recipe {
+	r := &Recipe{
+		Name:        text(node["name"]),
+		Url:         text(node["url"]),
+		Servings:    servings(node["recipeYield"]),
+		Ingredients: texts(node["recipeIngredient"]),
+		Steps:       steps(node["recipeInstructions"]),
+		PrepTime:    minutes(text(node["prepTime"])),
+		CookTime:    minutes(text(node["cookTime"])),
+		Tags:        tags(node),
+	}
+
+	if len(r.Ingredients) == 0 {
+		r.Ingredients = texts(node["ingredients"])
+	}
+
+	if total := minutes(text(node["totalTime"])); r.CookTime == 0 && total > r.PrepTime {
+		r.CookTime = total - r.PrepTime
+	}
+
+	return r
+}
+
+// text reads a JSON-LD value as plain text, unescaping any HTML entities and
+// collapsing whitespace.
+func text(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.Join(strings.Fields(html.UnescapeString(v)), " ")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		if len(v) > 0 {
+			return text(v[0])
+		}
+	case map[string]any:
+		return text(v["@id"])
+	}
+
+	return ""
+}
+
+func texts(v any) []string {
+	values := []string{}
+
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if s := text(item); s != "" {
+				values = append(values, s)
+			}
+		}
+	default:
+		if s := text(v); s != "" {
+			values = append(values, s)
+		}
+	}
+
+	return values
+}
+
+var firstNumber = regexp.MustCompile(`\d+`)
+
+// servings reads a recipeYield, which may be a number, text like "Serves 4"
+// or a list of both.
+func servings(v any) int {
+	switch v := v.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(firstNumber.FindString(v))
+		return n
+	case []any:
+		for _, item := range v {
+			if n := servings(item); n > 0 {
+				return n
+			}
+		}
+	}
+
+	return 0
+}
+
+// steps reads recipeInstructions, which may be a block of text, a list of
+// text, HowToSteps, or HowToSections of HowToSteps.
+func steps(v any) []string {
+	s := []string{}
+
+	switch v := v.(type) {
+	case string:
+		for _, line := range strings.Split(html.UnescapeString(v), "\n") {
+			if line = text(line); line != "" {
+				s = append(s, line)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			s = append(s, steps(item)...)
+		}
+	case map[string]any:
+		if hasType(v["@type"], "HowToSection") {
+			return steps(v["itemListElement"])
+		}
+
+		step := text(v["text"])
+		if step == "" {
+			step = text(v["name"])
+		}
+
+		if step != "" {
+			s = append(s, step)
+		}
+	}
+
+	return s
+}
+
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// minutes reads an ISO 8601 duration like "PT1H30M", rounding seconds up to
+// the minute.
+func minutes(s string) int {
+	match := isoDuration.FindStringSubmatch(strings.ToUpper(s))
+	if match == nil {
+		return 0
+	}
+
+	part := func(i int) int {
+		n, _ := strconv.Atoi(match[i])
+		return n
+	}
+
+	m := part(1)*24*60 + part(2)*60 + part(3)
+
+	if seconds, _ := strconv.ParseFloat(match[4], 64); seconds > 0 {
+		m += int(seconds+59) / 60
+	}
+
+	return m
+}
+
+// tags gathers a recipe's cuisines, categories, keywords and diets.
+func tags(node map[string]any) []string {
+	t := []string{}
+
+	for _, key := range []string{"recipeCuisine", "recipeCategory", "keywords"} {
+		for _, value := range texts(node[key]) {
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					t = append(t, tag)
+				}
+			}
+		}
+	}
+
+	for _, diet := range texts(node["suitableForDiet"]) {
+		t = append(t, dietTag(diet))
+	}
+
+	return t
+}
+
+// dietTag turns a schema.org RestrictedDiet, like
+// "https://schema.org/GlutenFreeDiet", into a tag like "gluten-free".
+func dietTag(diet string) string {
+	diet = diet[strings.LastIndexAny(diet, "/:")+1:]
+	diet = strings.TrimSuffix(diet, "Diet")
+
+	var b strings.Builder
+	for i, r := range diet {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune('-')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package recipe_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/recipe"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestExtractingRecipe(t *testing.T) {
+	page := `<html><head>
+		<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Organization", "name": "Recipes Ltd"}</script>
+		<script type="application/ld+json">{
+			"@context": "https://schema.org",
+			"@graph": [
+				{"@type": "WebPage", "name": "Pasta &amp; pesto"},
+				{
+					"@type": ["Recipe", "NewsArticle"],
+					"name": "Pasta &amp; pesto",
+					"url": "https://recipes.localhost/pesto",
+					"recipeYield": ["4", "4 servings"],
+					"prepTime": "PT5M",
+					"totalTime": "PT1H15M",
+					"recipeCuisine": "Italian",
+					"keywords": "quick, weeknight",
+					"suitableForDiet": "https://schema.org/VegetarianDiet",
+					"recipeIngredient": ["300g pasta", " 1 jar  pesto "],
+					"recipeInstructions": [
+						{"@type": "HowToSection", "name": "Pasta", "itemListElement": [{"@type": "HowToStep", "text": "Boil the pasta."}]},
+						{"@type": "HowToStep", "text": "Stir in the pesto."}
+					]
+				}
+			]
+		}</script>
+	</head><body></body></html>`
+
+	r, err := recipe.Extract(strings.NewReader(page))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &recipe.Recipe{
+		Name:        "Pasta & pesto",
+		Url:         "https://recipes.localhost/pesto",
+		Servings:    4,
+		Ingredients: []string{"300g pasta", "1 jar pesto"},
+		Steps:       []string{"Boil the pasta.", "Stir in the pesto."},
+		PrepTime:    5,
+		CookTime:    70,
+		Tags:        []string{"Italian", "quick", "weeknight", "vegetarian"},
+	}, r)
+}
+
+func TestExtractingRecipeWithTextInstructions(t *testing.T) {
+	page := `<script type="application/ld+json; charset=utf-8">[{"@type": "Recipe", "name": "Toast", "recipeYield": "Serves 2", "cookTime": "PT3M", "recipeInstructions": "Toast the bread.\nButter it."}]</script>`
+
+	r, err := recipe.Extract(strings.NewReader(page))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.Servings)
+	assert.Equal(t, 3, r.CookTime)
+	assert.Equal(t, []string{"Toast the bread.", "Butter it."}, r.Steps)
+}
+
+func TestExtractingRecipeFromPageWithoutOne(t *testing.T) {
+	page := `<script type="application/ld+json">{not json</script><script>var recipe = {"@type": "Recipe"}</script>`
+
+	_, err := recipe.Extract(strings.NewReader(page))
+
+	assert.ErrorIs(t, err, recipe.ErrRecipeNotFound)
+}
@@ -0,0 +1,83 @@
+package shop
+
+import (
+	"errors"
+	"time"
+)
+
+const DateFormat = time.DateOnly
+
+type Week struct {
+	Start       string      `json:"start"`
+	Days        []*Day      `json:"days"`
+	Unscheduled []*ShopMeal `json:"unscheduled"`
+}
+
+type Day struct {
+	Date      string      `json:"date"`
+	Breakfast []*ShopMeal `json:"breakfast"`
+	Lunch     []*ShopMeal `json:"lunch"`
+	Dinner    []*ShopMeal `json:"dinner"`
+}
+
+func ParseDate(date string) (time.Time, error) {
+	t, err := time.Parse(DateFormat, date)
+
+	if err != nil {
+		return time.Time{}, errors.New("date must be in the format YYYY-MM-DD")
+	}
+
+	return t, nil
+}
+
+// StartOfWeek returns the Monday of the week containing the given date.
+func StartOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	y, m, d := date.AddDate(0, 0, -offset).Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Week lays out the shop's meals in a Monday to Sunday grid for the week
+// containing the given date. Meals without a schedule are listed separately;
+// meals scheduled outside the week are left out.
+func (s *Shop) Week(date time.Time) *Week {
+	start := StartOfWeek(date)
+
+	w := &Week{Start: start.Format(DateFormat), Days: make([]*Day, 0, 7), Unscheduled: []*ShopMeal{}}
+	days := map[string]*Day{}
+
+	for i := 0; i < 7; i++ {
+		d := &Day{
+			Date:      start.AddDate(0, 0, i).Format(DateFormat),
+			Breakfast: []*ShopMeal{},
+			Lunch:     []*ShopMeal{},
+			Dinner:    []*ShopMeal{},
+		}
+		w.Days = append(w.Days, d)
+		days[d.Date] = d
+	}
+
+	for _, meal := range s.Meals {
+		if meal.Schedule == nil {
+			w.Unscheduled = append(w.Unscheduled, meal)
+			continue
+		}
+
+		d, ok := days[meal.Schedule.Date]
+		if !ok {
+			continue
+		}
+
+		switch meal.Schedule.Slot {
+		case Breakfast:
+			d.Breakfast = append(d.Breakfast, meal)
+		case Lunch:
+			d.Lunch = append(d.Lunch, meal)
+		case Dinner:
+			d.Dinner = append(d.Dinner, meal)
+		}
+	}
+
+	return w
+}
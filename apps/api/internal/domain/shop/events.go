@@ -1,7 +1,8 @@
 package shop
 
 type Created struct {
-	Id int
+	Id      int
+	StoreId string
 }
 
 type MealAdded struct {
@@ -23,3 +24,19 @@ type ItemAdded struct {
 type ItemRemoved struct {
 	ProductId string
 }
+
+type MealScheduled struct {
+	MealId string
+	Date   string
+	Slot   Slot
+}
+
+type MealUnscheduled struct {
+	MealId string
+}
+
+type Completed struct {
+	Bought    []string
+	Abandoned []string
+	Covered   []string
+}
@@ -1,21 +1,45 @@
 package shop
 
 import (
+	"errors"
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"strconv"
+	"time"
 )
 
+var ErrShopCompleted = errors.New("shop has been completed")
+
 type Shop struct {
 	aggregate.Root
-	Id    int         `json:"id"`
-	Meals []*ShopMeal `json:"meals"`
-	Items []*Item     `json:"items"`
+	Id         int         `json:"id"`
+	StoreId    string      `json:"storeId,omitempty"`
+	Meals      []*ShopMeal `json:"meals"`
+	Items      []*Item     `json:"items"`
+	Completion *Completion `json:"completion,omitempty"`
+}
+
+// Completion records what happened to the shopping list once a shop was
+// finished: the products that made it into the basket, those left behind and
+// those the pantry already covered. Shops completed before covered products
+// were recorded have none.
+type Completion struct {
+	CompletedAt time.Time `json:"completedAt"`
+	Bought      []string  `json:"bought"`
+	Abandoned   []string  `json:"abandoned"`
+	Covered     []string  `json:"covered"`
 }
 
 type ShopMeal struct {
-	MealId string `json:"id"`
+	MealId   string    `json:"id"`
+	Servings int       `json:"servings,omitempty"`
+	Schedule *Schedule `json:"schedule,omitempty"`
+}
+
+type Schedule struct {
+	Date string `json:"date"`
+	Slot Slot   `json:"slot"`
 }
 
 type Item struct {
@@ -27,10 +51,11 @@ func (s *Shop) Transition(event eventsourcing.Event) {
 	switch e := event.Data().(type) {
 	case *Created:
 		s.Id = e.Id
+		s.StoreId = e.StoreId
 		s.Meals = []*ShopMeal{}
 		s.Items = []*Item{}
 	case *MealAdded:
-		s.Meals = append(s.Meals, &ShopMeal{MealId: e.Meal.MealId})
+		s.Meals = append(s.Meals, &ShopMeal{MealId: e.Meal.MealId, Servings: e.Meal.Servings, Schedule: e.Meal.Schedule})
 	case *MealRemoved:
 		meals := []*ShopMeal{}
 		for _, meal := range s.Meals {
@@ -42,9 +67,21 @@ func (s *Shop) Transition(event eventsourcing.Event) {
 	case *MealsSet:
 		var meals []*ShopMeal
 		for _, meal := range e.Meals {
-			meals = append(meals, &ShopMeal{MealId: meal.MealId})
+			meals = append(meals, &ShopMeal{MealId: meal.MealId, Servings: meal.Servings, Schedule: meal.Schedule})
 		}
 		s.Meals = meals
+	case *MealScheduled:
+		for _, meal := range s.Meals {
+			if meal.MealId == e.MealId {
+				meal.Schedule = &Schedule{Date: e.Date, Slot: e.Slot}
+			}
+		}
+	case *MealUnscheduled:
+		for _, meal := range s.Meals {
+			if meal.MealId == e.MealId {
+				meal.Schedule = nil
+			}
+		}
 	case *ItemAdded:
 		s.Items = append(s.Items, e.Item)
 	case *ItemRemoved:
@@ -55,14 +92,34 @@ func (s *Shop) Transition(event eventsourcing.Event) {
 			}
 		}
 		s.Items = items
+	case *Completed:
+		covered := e.Covered
+		if covered == nil {
+			covered = []string{}
+		}
+		s.Completion = &Completion{CompletedAt: event.Timestamp(), Bought: e.Bought, Abandoned: e.Abandoned, Covered: covered}
 	}
 }
 
 func (s *Shop) Register(r aggregate.RegisterFunc) {
-	r(&Created{}, &MealAdded{}, &MealRemoved{}, &MealsSet{}, &ItemAdded{}, &ItemRemoved{})
+	r(&Created{}, &MealAdded{}, &MealRemoved{}, &MealsSet{}, &ItemAdded{}, &ItemRemoved{}, &MealScheduled{}, &MealUnscheduled{}, &Completed{})
+}
+
+func (s *Shop) SerializeSnapshot(f aggregate.SnapshotMarshal) ([]byte, error) {
+	return f(s)
+}
+
+func (s *Shop) DeserializeSnapshot(f aggregate.SnapshotUnmarshal, d []byte) error {
+	return f(d, s)
 }
 
 func NewShop(id int) (*Shop, error) {
+	return NewShopAtStore(id, "")
+}
+
+// NewShopAtStore starts a shop at one of the stores, whose layout the
+// shopping list is then arranged by.
+func NewShopAtStore(id int, storeId string) (*Shop, error) {
 	s := &Shop{}
 
 	err := s.SetID(strconv.Itoa(id))
@@ -71,29 +128,94 @@ func NewShop(id int) (*Shop, error) {
 		return nil, err
 	}
 
-	aggregate.TrackChange(s, &Created{Id: id})
+	aggregate.TrackChange(s, &Created{Id: id, StoreId: storeId})
 
 	return s, nil
 }
 
-func (s *Shop) AddMeal(m *ShopMeal) *Shop {
+func (s *Shop) AddMeal(m *ShopMeal) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
 	aggregate.TrackChange(s, &MealAdded{Meal: *m})
-	return s
+	return nil
 }
 
-func (s *Shop) SetMeals(m []*ShopMeal) *Shop {
+func (s *Shop) SetMeals(m []*ShopMeal) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
 	aggregate.TrackChange(s, &MealsSet{Meals: m})
-	return s
+	return nil
 }
 
-func (s *Shop) RemoveMeal(id string) {
+func (s *Shop) RemoveMeal(id string) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
 	aggregate.TrackChange(s, &MealRemoved{Id: id})
+	return nil
 }
 
-func (s *Shop) AddItem(item *Item) {
+func (s *Shop) HasMeal(mealId string) bool {
+	for _, meal := range s.Meals {
+		if meal.MealId == mealId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Shop) ScheduleMeal(mealId string, schedule Schedule) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
+	aggregate.TrackChange(s, &MealScheduled{MealId: mealId, Date: schedule.Date, Slot: schedule.Slot})
+	return nil
+}
+
+func (s *Shop) UnscheduleMeal(mealId string) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
+	aggregate.TrackChange(s, &MealUnscheduled{MealId: mealId})
+	return nil
+}
+
+func (s *Shop) AddItem(item *Item) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
 	aggregate.TrackChange(s, &ItemAdded{Item: item})
+	return nil
 }
 
-func (s *Shop) RemoveItem(productId string) {
+func (s *Shop) RemoveItem(productId string) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
 	aggregate.TrackChange(s, &ItemRemoved{ProductId: productId})
+	return nil
+}
+
+// Complete finishes the shop, after which it can no longer be changed.
+func (s *Shop) Complete(bought []string, abandoned []string, covered []string) error {
+	if s.IsCompleted() {
+		return ErrShopCompleted
+	}
+
+	aggregate.TrackChange(s, &Completed{Bought: bought, Abandoned: abandoned, Covered: covered})
+	return nil
+}
+
+func (s *Shop) IsCompleted() bool {
+	return s.Completion != nil
 }
@@ -0,0 +1,153 @@
+package shop
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/readmodel"
+	"strconv"
+	"time"
+)
+
+// shopReadModel indexes shops by id with their dates and counts, so the
+// current shop and shop history can be read without replaying the event log.
+type shopReadModel struct {
+	db     *sql.DB
+	runner *readmodel.Runner
+}
+
+func newShopReadModel(db *sql.DB, es *sqlStore.SQLite) (*shopReadModel, error) {
+	runner, err := readmodel.NewRunner(db, es, readmodel.Projection{
+		Name: "shops",
+		Tables: []string{
+			`CREATE TABLE IF NOT EXISTS shop_read_model (
+				id           INTEGER PRIMARY KEY,
+				started_at   VARCHAR NOT NULL,
+				completed_at VARCHAR,
+				meal_count   INTEGER NOT NULL,
+				item_count   INTEGER NOT NULL,
+				data         TEXT NOT NULL
+			);`,
+		},
+		Handle: handleShopEvent,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &shopReadModel{db: db, runner: runner}, nil
+}
+
+func handleShopEvent(tx *sql.Tx, ev eventsourcing.Event) error {
+	if ev.AggregateType() != "Shop" {
+		return nil
+	}
+
+	id, err := strconv.Atoi(ev.AggregateID())
+
+	if err != nil {
+		return err
+	}
+
+	s := &Shop{}
+	startedAt := ev.Timestamp().Format(time.RFC3339Nano)
+
+	var data []byte
+	err = tx.QueryRow(`SELECT started_at, data FROM shop_read_model WHERE id = ?`, id).Scan(&startedAt, &data)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return err
+		}
+	}
+
+	s.Transition(ev)
+
+	data, err = json.Marshal(s)
+
+	if err != nil {
+		return err
+	}
+
+	var completedAt *string
+	if s.Completion != nil {
+		c := s.Completion.CompletedAt.Format(time.RFC3339Nano)
+		completedAt = &c
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO shop_read_model (id, started_at, completed_at, meal_count, item_count, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET completed_at = excluded.completed_at, meal_count = excluded.meal_count, item_count = excluded.item_count, data = excluded.data`,
+		id, startedAt, completedAt, len(s.Meals), len(s.Items), data,
+	)
+
+	return err
+}
+
+// currentId returns the id of the most recent shop, or 0 if there are none.
+func (r *shopReadModel) currentId() (int, error) {
+	if err := r.runner.CatchUp(); err != nil {
+		return 0, err
+	}
+
+	var id sql.NullInt64
+	err := r.db.QueryRow(`SELECT MAX(id) FROM shop_read_model`).Scan(&id)
+
+	return int(id.Int64), err
+}
+
+func (r *shopReadModel) list(offset int, limit int) ([]*Summary, int, error) {
+	if err := r.runner.CatchUp(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM shop_read_model`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, started_at, completed_at, meal_count, item_count FROM shop_read_model ORDER BY id DESC LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	summaries := []*Summary{}
+	for rows.Next() {
+		summary := &Summary{}
+		var startedAt string
+		var completedAt sql.NullString
+
+		if err := rows.Scan(&summary.Id, &startedAt, &completedAt, &summary.MealCount, &summary.ItemCount); err != nil {
+			return nil, 0, err
+		}
+
+		if summary.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+			return nil, 0, err
+		}
+
+		if completedAt.Valid {
+			t, err := time.Parse(time.RFC3339Nano, completedAt.String)
+			if err != nil {
+				return nil, 0, err
+			}
+			summary.CompletedAt = &t
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, total, rows.Err()
+}
@@ -3,45 +3,113 @@ package shop
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/eventstore/memory"
-	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	snapshotStore "github.com/hallgren/eventsourcing/snapshotstore/memory"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/snapshot"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	_ "github.com/mattn/go-sqlite3"
+	"sort"
 	"strconv"
+	"time"
 )
 
+type Summary struct {
+	Id          int        `json:"id"`
+	StartedAt   time.Time  `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	MealCount   int        `json:"mealCount"`
+	ItemCount   int        `json:"itemCount"`
+}
+
 type ShopRepository struct {
-	es  core.EventStore
-	all func() (core.Iterator, error)
+	es        core.EventStore
+	all       func() (core.Iterator, error)
+	readModel *shopReadModel
+	snapshots *snapshot.Snapshotter
 }
 
 func NewShopRepository(es core.EventStore, all func() (core.Iterator, error)) *ShopRepository {
 	aggregate.Register(&Shop{})
-	r := &ShopRepository{es, all}
+	r := &ShopRepository{es: es, all: all}
 	return r
 }
 
 func NewSqliteShopRepository(db *sql.DB) (*ShopRepository, error) {
-	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	es, err := database.NewEventStore(db)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return NewShopRepository(es, func() (core.Iterator, error) {
+	r := NewShopRepository(es, func() (core.Iterator, error) {
 		return es.All(0)()
-	}), nil
+	})
+
+	r.readModel, err = newShopReadModel(db, es.SQLite)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.snapshots, err = snapshot.NewSqliteSnapshotter(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 func NewFakeShopRepository() *ShopRepository {
 	es := memory.Create()
 
-	return NewShopRepository(es, es.All(0, 100000))
+	r := NewShopRepository(es, func() (core.Iterator, error) {
+		return es.All(0, 100000)()
+	})
+
+	r.snapshots = &snapshot.Snapshotter{Store: snapshotStore.Create(), Interval: snapshot.DefaultInterval}
+
+	return r
+}
+
+// WithSnapshotInterval returns a copy of the repository sharing its stores but
+// snapshotting shops at the given interval. Zero turns snapshots off.
+func (r ShopRepository) WithSnapshotInterval(interval int) *ShopRepository {
+	r.snapshots = r.snapshots.WithInterval(interval)
+	return &r
+}
+
+// SnapshotStore returns the store the repository keeps shop snapshots in.
+func (r ShopRepository) SnapshotStore() core.SnapshotStore {
+	if r.snapshots == nil {
+		return nil
+	}
+
+	return r.snapshots.Store
+}
+
+// In returns a copy of the repository that saves shops as part of the unit.
+func (r ShopRepository) In(unit *unitofwork.Unit) *ShopRepository {
+	r.es = unit.Store(r.es)
+	return &r
 }
 
 func (r ShopRepository) Current() (*Shop, error) {
+	if r.readModel != nil {
+		id, err := r.readModel.currentId()
+
+		if err != nil || id == 0 {
+			return nil, err
+		}
+
+		return r.Find(id)
+	}
+
 	currentId := 0
 
 	p := eventsourcing.NewProjection(
@@ -77,8 +145,79 @@ func (r ShopRepository) Current() (*Shop, error) {
 	return r.Find(currentId)
 }
 
+// List returns a page of shops, most recent first, along with the total
+// number of shops.
+func (r ShopRepository) List(offset int, limit int) ([]*Summary, int, error) {
+	if r.readModel != nil {
+		return r.readModel.list(offset, limit)
+	}
+
+	shops := map[int]*Shop{}
+	startedAt := map[int]time.Time{}
+
+	p := eventsourcing.NewProjection(
+		r.all,
+		func(e eventsourcing.Event) error {
+			if e.AggregateType() != "Shop" {
+				return nil
+			}
+
+			id, err := strconv.Atoi(e.AggregateID())
+			if err != nil {
+				return err
+			}
+
+			s, ok := shops[id]
+			if !ok {
+				s = &Shop{}
+				shops[id] = s
+				startedAt[id] = e.Timestamp()
+			}
+
+			s.Transition(e)
+
+			return nil
+		})
+
+	(*p).Strict = false
+	_, result := p.RunOnce()
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	summaries := make([]*Summary, 0, len(shops))
+	for id, s := range shops {
+		summary := &Summary{Id: id, StartedAt: startedAt[id], MealCount: len(s.Meals), ItemCount: len(s.Items)}
+		if s.Completion != nil {
+			summary.CompletedAt = &s.Completion.CompletedAt
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Id > summaries[j].Id
+	})
+
+	total := len(summaries)
+
+	if offset >= total {
+		return []*Summary{}, total, nil
+	}
+
+	return summaries[offset:min(offset+limit, total)], total, nil
+}
+
 func (r ShopRepository) Find(id int) (*Shop, error) {
 	s := &Shop{}
+
+	if r.snapshots.Enabled() {
+		err := aggregate.LoadSnapshot(context.Background(), r.snapshots.Store, strconv.Itoa(id), s)
+		if err != nil && !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+			return nil, err
+		}
+	}
+
 	err := aggregate.Load(context.Background(), r.es, strconv.Itoa(id), s)
 	if err != nil {
 		return nil, err
@@ -88,5 +227,25 @@ func (r ShopRepository) Find(id int) (*Shop, error) {
 }
 
 func (r ShopRepository) Save(s *Shop) error {
-	return aggregate.Save(r.es, s)
+	saved := s.Version() - eventsourcing.Version(len(s.Events()))
+
+	err := aggregate.Save(r.es, s)
+	if err != nil {
+		return err
+	}
+
+	if !r.snapshots.Due(saved, s.Version()) {
+		return nil
+	}
+
+	return unitofwork.AfterCommit(r.es, func() error {
+		// Snapshot the shop as it was stored rather than as held in memory, so
+		// values the store rounds, like event timestamps, match a full replay.
+		loaded, err := r.Find(s.Id)
+		if err != nil {
+			return err
+		}
+
+		return aggregate.SaveSnapshot(r.snapshots.Store, loaded)
+	})
 }
@@ -1,10 +1,14 @@
 package shop_test
 
 import (
+	"context"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
 	"github.com/joe-reed/meal-planner/apps/api/internal/database"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"strconv"
 	"testing"
 )
 
@@ -38,6 +42,11 @@ func runSuite(t *testing.T, factory func() *shop.ShopRepository, teardown func()
 		{"finding shop", testFindingShop},
 		{"finding shop with no meals", testFindingShopWithNoMeals},
 		{"saving shop", testSavingShop},
+		{"scheduling meal", testSchedulingMeal},
+		{"completing shop", testCompletingShop},
+		{"listing shops", testListingShops},
+		{"listing shops beyond the last page", testListingShopsBeyondLastPage},
+		{"loading shop from a snapshot", testLoadingShopFromSnapshot},
 	}
 
 	for _, test := range tests {
@@ -56,7 +65,8 @@ func testGettingCurrentShop(t *testing.T, r *shop.ShopRepository) {
 	s3, err := shop.NewShop(3)
 	assert.NoError(t, err)
 
-	s3.AddMeal(&shop.ShopMeal{MealId: "123"}).AddMeal(&shop.ShopMeal{MealId: "456"})
+	s3.AddMeal(&shop.ShopMeal{MealId: "123"})
+	s3.AddMeal(&shop.ShopMeal{MealId: "456"})
 
 	err = r.Save(s1)
 	assert.NoError(t, err)
@@ -122,7 +132,8 @@ func testSavingShop(t *testing.T, r *shop.ShopRepository) {
 	err = r.Save(s)
 	assert.NoError(t, err)
 
-	s = s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+	err = s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+	assert.NoError(t, err)
 
 	err = r.Save(s)
 	assert.NoError(t, err)
@@ -131,3 +142,142 @@ func testSavingShop(t *testing.T, r *shop.ShopRepository) {
 	assert.NoError(t, err)
 	assert.EqualExportedValues(t, s, found)
 }
+
+func testSchedulingMeal(t *testing.T, r *shop.ShopRepository) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+	s.AddMeal(&shop.ShopMeal{MealId: "def"})
+	s.ScheduleMeal("abc", shop.Schedule{Date: "2026-10-13", Slot: shop.Lunch})
+	s.ScheduleMeal("def", shop.Schedule{Date: "2026-10-14", Slot: shop.Dinner})
+	s.UnscheduleMeal("def")
+
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	found, err := r.Find(1)
+	assert.NoError(t, err)
+	assert.EqualExportedValues(t, s, found)
+	assert.Equal(t, &shop.Schedule{Date: "2026-10-13", Slot: shop.Lunch}, found.Meals[0].Schedule)
+	assert.Nil(t, found.Meals[1].Schedule)
+}
+
+func testCompletingShop(t *testing.T, r *shop.ShopRepository) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+	assert.NoError(t, err)
+	err = s.Complete([]string{"rice"}, []string{"eggs"}, []string{"salt"})
+	assert.NoError(t, err)
+
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	found, err := r.Find(1)
+	assert.NoError(t, err)
+	assert.True(t, found.IsCompleted())
+	assert.Equal(t, []string{"rice"}, found.Completion.Bought)
+	assert.Equal(t, []string{"eggs"}, found.Completion.Abandoned)
+	assert.Equal(t, []string{"salt"}, found.Completion.Covered)
+	assert.False(t, found.Completion.CompletedAt.IsZero())
+
+	assert.ErrorIs(t, found.AddMeal(&shop.ShopMeal{MealId: "def"}), shop.ErrShopCompleted)
+	assert.ErrorIs(t, found.RemoveMeal("abc"), shop.ErrShopCompleted)
+	assert.ErrorIs(t, found.AddItem(&shop.Item{ProductId: "rice"}), shop.ErrShopCompleted)
+	assert.ErrorIs(t, found.Complete([]string{}, []string{}, []string{}), shop.ErrShopCompleted)
+	assert.Len(t, found.Meals, 1)
+}
+
+func testListingShops(t *testing.T, r *shop.ShopRepository) {
+	for id := 1; id <= 3; id++ {
+		s, err := shop.NewShop(id)
+		assert.NoError(t, err)
+
+		for i := 0; i < id; i++ {
+			err = s.AddMeal(&shop.ShopMeal{MealId: strconv.Itoa(i)})
+			assert.NoError(t, err)
+		}
+
+		if id == 1 {
+			err = s.Complete([]string{}, []string{}, []string{})
+			assert.NoError(t, err)
+		}
+
+		err = r.Save(s)
+		assert.NoError(t, err)
+	}
+
+	shops, total, err := r.List(0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, shops, 2)
+	assert.Equal(t, 3, shops[0].Id)
+	assert.Equal(t, 3, shops[0].MealCount)
+	assert.Equal(t, 2, shops[1].Id)
+	assert.Nil(t, shops[1].CompletedAt)
+	assert.False(t, shops[1].StartedAt.IsZero())
+
+	shops, total, err = r.List(2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, shops, 1)
+	assert.Equal(t, 1, shops[0].Id)
+	assert.Equal(t, 1, shops[0].MealCount)
+	assert.NotNil(t, shops[0].CompletedAt)
+}
+
+func testListingShopsBeyondLastPage(t *testing.T, r *shop.ShopRepository) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	shops, total, err := r.List(20, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Empty(t, shops)
+}
+
+func testLoadingShopFromSnapshot(t *testing.T, r *shop.ShopRepository) {
+	r = r.WithSnapshotInterval(3)
+
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	for i := 1; i <= 5; i++ {
+		err = s.AddMeal(&shop.ShopMeal{MealId: strconv.Itoa(i), Servings: i})
+		assert.NoError(t, err)
+		err = s.AddItem(&shop.Item{ProductId: strconv.Itoa(i)})
+		assert.NoError(t, err)
+
+		err = r.Save(s)
+		assert.NoError(t, err)
+	}
+
+	err = s.ScheduleMeal("2", shop.Schedule{Date: "2024-01-02", Slot: shop.Dinner})
+	assert.NoError(t, err)
+	err = s.RemoveMeal("3")
+	assert.NoError(t, err)
+	err = s.Complete([]string{"1", "2"}, []string{"4", "5"}, []string{})
+	assert.NoError(t, err)
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	snapshotted := &shop.Shop{}
+	err = aggregate.LoadSnapshot(context.Background(), r.SnapshotStore(), s.ID(), snapshotted)
+	assert.NoError(t, err)
+	assert.Equal(t, eventsourcing.Version(14), snapshotted.Version())
+
+	found, err := r.Find(1)
+	assert.NoError(t, err)
+
+	replayed, err := r.WithSnapshotInterval(0).Find(1)
+	assert.NoError(t, err)
+
+	assert.EqualExportedValues(t, replayed, found)
+	assert.True(t, replayed.Completion.CompletedAt.Equal(found.Completion.CompletedAt))
+	assert.Equal(t, replayed.Version(), found.Version())
+	assert.Equal(t, replayed.GlobalVersion(), found.GlobalVersion())
+}
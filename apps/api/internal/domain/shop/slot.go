@@ -0,0 +1,12 @@
+package shop
+
+// Slot is stored by name, so its values can change. They start at one so
+// that a schedule without a slot isn't taken to be breakfast.
+type Slot int
+
+//go:generate go run github.com/campoy/jsonenums -type=Slot
+const (
+	Breakfast Slot = iota + 1
+	Lunch
+	Dinner
+)
@@ -0,0 +1,59 @@
+// Code generated by jsonenums -type=Slot; DO NOT EDIT.
+
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	_SlotNameToValue = map[string]Slot{
+		"Breakfast": Breakfast,
+		"Lunch":     Lunch,
+		"Dinner":    Dinner,
+	}
+
+	_SlotValueToName = map[Slot]string{
+		Breakfast: "Breakfast",
+		Lunch:     "Lunch",
+		Dinner:    "Dinner",
+	}
+)
+
+func init() {
+	var v Slot
+	if _, ok := interface{}(v).(fmt.Stringer); ok {
+		_SlotNameToValue = map[string]Slot{
+			interface{}(Breakfast).(fmt.Stringer).String(): Breakfast,
+			interface{}(Lunch).(fmt.Stringer).String():     Lunch,
+			interface{}(Dinner).(fmt.Stringer).String():    Dinner,
+		}
+	}
+}
+
+// MarshalJSON is generated so Slot satisfies json.Marshaler.
+func (r Slot) MarshalJSON() ([]byte, error) {
+	if s, ok := interface{}(r).(fmt.Stringer); ok {
+		return json.Marshal(s.String())
+	}
+	s, ok := _SlotValueToName[r]
+	if !ok {
+		return nil, fmt.Errorf("invalid Slot: %d", r)
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON is generated so Slot satisfies json.Unmarshaler.
+func (r *Slot) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Slot should be a string, got %s", data)
+	}
+	v, ok := _SlotNameToValue[s]
+	if !ok {
+		return fmt.Errorf("invalid Slot %q", s)
+	}
+	*r = v
+	return nil
+}
@@ -0,0 +1,83 @@
+package shoppinglist
+
+import (
+	"cmp"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"slices"
+)
+
+// Aisle holds the items on a shopping list in one category.
+type Aisle struct {
+	Category category.Category  `json:"category"`
+	Items    []ShoppingListItem `json:"items"`
+}
+
+type GroupedShoppingList struct {
+	ShopId  int     `json:"shopId"`
+	StoreId string  `json:"storeId,omitempty"`
+	Aisles  []Aisle `json:"aisles"`
+}
+
+// Group splits the list into aisles, in the order the categories are walked.
+// Items within an aisle are sorted by name. Items in categories that aren't
+// configured come last.
+func (l ShoppingList) Group(categories *category.Categories) GroupedShoppingList {
+	byCategory := map[category.CategoryName][]ShoppingListItem{}
+
+	for _, item := range l.ShoppingList {
+		byCategory[item.Category] = append(byCategory[item.Category], item)
+	}
+
+	ids := make([]category.CategoryName, 0, len(byCategory))
+	for id := range byCategory {
+		ids = append(ids, id)
+	}
+
+	slices.SortFunc(ids, func(a, b category.CategoryName) int {
+		pa, pb := categories.Position(a), categories.Position(b)
+
+		if pa == -1 || pb == -1 {
+			if pa != pb {
+				return cmp.Compare(pb, pa)
+			}
+
+			return cmp.Compare(a, b)
+		}
+
+		return cmp.Compare(pa, pb)
+	})
+
+	aisles := make([]Aisle, 0, len(ids))
+	for _, id := range ids {
+		c := category.Category{Id: id, Name: string(id)}
+		if configured := categories.Find(id); configured != nil {
+			c = *configured
+		}
+
+		items := byCategory[id]
+		slices.SortFunc(items, func(a, b ShoppingListItem) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Id, b.Id))
+		})
+
+		aisles = append(aisles, Aisle{Category: c, Items: items})
+	}
+
+	return GroupedShoppingList{ShopId: l.ShopId, Aisles: aisles}
+}
+
+// GroupAtStore splits the list into aisles as they're laid out in a store,
+// moving any products the store shelves elsewhere into their aisle there.
+func (l ShoppingList) GroupAtStore(categories *category.Categories, s *store.Store) GroupedShoppingList {
+	located := ShoppingList{ShopId: l.ShopId, ShoppingList: make(map[string]ShoppingListItem, len(l.ShoppingList))}
+
+	for id, item := range l.ShoppingList {
+		item.Category = s.Aisle(item.Id, item.Category)
+		located.ShoppingList[id] = item
+	}
+
+	grouped := located.Group(s.Arrange(categories))
+	grouped.StoreId = s.Id
+
+	return grouped
+}
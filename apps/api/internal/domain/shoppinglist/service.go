@@ -0,0 +1,124 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// ShoppingList is a copy of the current shop's list, safe to hold on to while
+// the projection moves on.
+type ShoppingList struct {
+	ShopId       int                         `json:"shopId"`
+	ShoppingList map[string]ShoppingListItem `json:"shoppingList"`
+}
+
+// Diff holds the items that were added or changed between two versions of a
+// shopping list, and the ids of those that were removed.
+type Diff struct {
+	ShopId  int                         `json:"shopId"`
+	Changed map[string]ShoppingListItem `json:"changed"`
+	Removed []string                    `json:"removed"`
+}
+
+// Diff returns the changes taking l to next, and whether there were any.
+func (l ShoppingList) Diff(next ShoppingList) (Diff, bool) {
+	d := Diff{ShopId: next.ShopId, Changed: map[string]ShoppingListItem{}, Removed: []string{}}
+
+	for id, item := range next.ShoppingList {
+		if previous, ok := l.ShoppingList[id]; !ok || !reflect.DeepEqual(previous, item) {
+			d.Changed[id] = item
+		}
+	}
+
+	for id := range l.ShoppingList {
+		if _, ok := next.ShoppingList[id]; !ok {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+
+	slices.Sort(d.Removed)
+
+	return d, l.ShopId != next.ShopId || len(d.Changed) > 0 || len(d.Removed) > 0
+}
+
+type Health struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Service owns the shopping list projection. Runs of the projection are
+// serialised, and callers only ever see copies of its output.
+type Service struct {
+	lock       sync.Mutex
+	projection *eventsourcing.Projection
+	output     ShoppingListProjectionOutput
+	err        error
+}
+
+func NewService(es *sqlStore.SQLite) *Service {
+	p, output := CreateShoppingListProjection(es)
+
+	return &Service{projection: p, output: output}
+}
+
+// Current brings the projection up to date and returns the list as it stands.
+func (s *Service) Current() (ShoppingList, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.err = s.run()
+
+	if s.err != nil {
+		return ShoppingList{}, s.err
+	}
+
+	return ShoppingList{ShopId: *s.output.ShopId, ShoppingList: copyShoppingList(*s.output.ShoppingList)}, nil
+}
+
+// run brings the projection up to date. A panic while applying an event is
+// returned as an error, so that it's reported like any other failure.
+func (s *Service) run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("shopping list projection panicked: %v", r)
+		}
+	}()
+
+	return s.projection.RunToEnd(context.Background()).Error
+}
+
+// Health reports whether the last run of the projection succeeded.
+func (s *Service) Health() Health {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.err != nil {
+		return Health{Healthy: false, Error: s.err.Error()}
+	}
+
+	return Health{Healthy: true}
+}
+
+func copyShoppingList(shoppingList map[string]ShoppingListItem) map[string]ShoppingListItem {
+	c := maps.Clone(shoppingList)
+
+	for id, item := range c {
+		item.Quantities = slices.Clone(item.Quantities)
+		item.Total = slices.Clone(item.Total)
+
+		if item.InPantry != nil {
+			inPantry := *item.InPantry
+			item.InPantry = &inPantry
+		}
+
+		c[id] = item
+	}
+
+	return c
+}
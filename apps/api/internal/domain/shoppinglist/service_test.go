@@ -0,0 +1,110 @@
+package shoppinglist_test
+
+import (
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/stretchr/testify/assert"
+	"sync"
+)
+
+func (suite *ShoppingListSuite) TestServiceReturnsCurrentShoppingList() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Bakery)
+	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+	s, _ := suite.addShop()
+	suite.addMealToShop(s, m)
+
+	l, err := shoppinglist.NewService(suite.es).Current()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), s.Id, l.ShopId)
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+		},
+		l.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestServiceHandsOutCopies() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Bakery)
+	productB := suite.addProduct("ing-b", "Ing B", category.Dairy)
+	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+	s, _ := suite.addShop()
+	suite.addMealToShop(s, m)
+
+	service := shoppinglist.NewService(suite.es)
+
+	before, err := service.Current()
+	assert.NoError(suite.T(), err)
+
+	before.ShoppingList[productA.Id].Total[0] = quantity.Quantity{Amount: quantity.NewAmount(5), Unit: quantity.Gram}
+	delete(before.ShoppingList, productA.Id)
+
+	suite.addIngredientToMeal(m, productB)
+
+	after, err := service.Current()
+	assert.NoError(suite.T(), err)
+
+	assert.Len(suite.T(), before.ShoppingList, 0)
+	assert.Len(suite.T(), after.ShoppingList, 2)
+	assert.Equal(suite.T(), []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, after.ShoppingList[productA.Id].Total)
+}
+
+func (suite *ShoppingListSuite) TestServiceRunsProjectionOnceAtATime() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Bakery)
+	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+	s, _ := suite.addShop()
+	suite.addMealToShop(s, m)
+
+	service := shoppinglist.NewService(suite.es)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			l, err := service.Current()
+			assert.NoError(suite.T(), err)
+			assert.Equal(suite.T(), 1, l.ShoppingList[productA.Id].MealCount)
+		}()
+	}
+	wg.Wait()
+}
+
+func (suite *ShoppingListSuite) TestServiceReportsProjectionFailure() {
+	m := suite.addMeal([]meal.Ingredient{})
+	service := shoppinglist.NewService(suite.es)
+
+	_, err := service.Current()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), shoppinglist.Health{Healthy: true}, service.Health())
+
+	aggregate.TrackChange(m, &meal.IngredientRemoved{IngredientId: "missing"})
+	err = suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	_, err = service.Current()
+	assert.EqualError(suite.T(), err, "ingredient not found")
+	assert.Equal(suite.T(), shoppinglist.Health{Healthy: false, Error: "ingredient not found"}, service.Health())
+}
+
+func (suite *ShoppingListSuite) TestServiceReportsProjectionPanic() {
+	service := shoppinglist.NewService(suite.es)
+
+	m := &meal.Meal{}
+	m.UpdateServings(2)
+	err := suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	_, err = service.Current()
+	assert.ErrorContains(suite.T(), err, "shopping list projection panicked")
+	assert.False(suite.T(), service.Health().Healthy)
+	assert.Contains(suite.T(), service.Health().Error, "shopping list projection panicked")
+
+	_, err = service.Current()
+	assert.ErrorContains(suite.T(), err, "shopping list projection panicked")
+}
@@ -7,9 +7,12 @@ import (
 	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"maps"
+	"strconv"
 )
 
 type ShoppingListItem struct {
@@ -17,6 +20,50 @@ type ShoppingListItem struct {
 	MealCount  int                 `json:"mealCount"`
 	IsInBasket bool                `json:"isInBasket"`
 	Quantities []quantity.Quantity `json:"quantities"`
+	Total      []quantity.Quantity `json:"total"`
+	InPantry   *quantity.Quantity  `json:"inPantry,omitempty"`
+	Covered    bool                `json:"covered,omitempty"`
+
+	// lines counts the quantities each meal adds, keyed by meal id, so that a
+	// meal using the product more than once is still counted as one meal.
+	lines map[string]int
+}
+
+// shopItem is the key under which items added to the shop itself, rather than
+// through a meal, are counted.
+const shopItem = ""
+
+// add puts a quantity needed by a meal, or by the shop itself, on the item.
+func (i ShoppingListItem) add(mealId string, q quantity.Quantity) ShoppingListItem {
+	lines := maps.Clone(i.lines)
+	if lines == nil {
+		lines = map[string]int{}
+	}
+	lines[mealId]++
+
+	i.lines = lines
+	i.MealCount = len(lines)
+	return i.withQuantities(append(i.Quantities, q))
+}
+
+// remove takes a quantity added by add back off the item.
+func (i ShoppingListItem) remove(mealId string, q quantity.Quantity) ShoppingListItem {
+	lines := maps.Clone(i.lines)
+	if lines[mealId] > 1 {
+		lines[mealId]--
+	} else {
+		delete(lines, mealId)
+	}
+
+	i.lines = lines
+	i.MealCount = len(lines)
+	return i.withQuantities(removeQuantity(i.Quantities, q))
+}
+
+func (i ShoppingListItem) withQuantities(quantities []quantity.Quantity) ShoppingListItem {
+	i.Quantities = quantities
+	i.Total = quantity.Sum(quantities)
+	return i
 }
 
 type ShoppingListProjectionOutput struct {
@@ -24,88 +71,184 @@ type ShoppingListProjectionOutput struct {
 	ShoppingList *map[string]ShoppingListItem `json:"shoppingList"`
 }
 
+// CreateShoppingListProjection follows the latest shop, starting a fresh list
+// whenever a new shop is created.
 func CreateShoppingListProjection(es *sqlStore.SQLite) (*eventsourcing.Projection, ShoppingListProjectionOutput) {
+	return createShoppingListProjection(es, nil)
+}
+
+// CreateShopShoppingListProjection builds the list for a single shop. Once
+// the shop has been completed its list is left as it was at completion.
+func CreateShopShoppingListProjection(es *sqlStore.SQLite, shopId int) (*eventsourcing.Projection, ShoppingListProjectionOutput) {
+	return createShoppingListProjection(es, &shopId)
+}
+
+func createShoppingListProjection(es *sqlStore.SQLite, target *int) (*eventsourcing.Projection, ShoppingListProjectionOutput) {
 	shoppingList := map[string]ShoppingListItem{}
-	s := map[string]string{}
+	output := map[string]ShoppingListItem{}
+	stock := &pantry.Pantry{}
+	s := map[string]shop.ShopMeal{}
 	prods := map[string]product.Product{}
 	ms := map[string]*meal.Meal{}
 	shopId := new(int)
 	items := make(map[string]*shop.Item)
+	completed := false
 
 	start := core.Version(0)
 
-	p := eventsourcing.NewProjection(func() (core.Iterator, error) {
-		return es.All(start)()
-	}, func(ev eventsourcing.Event) error {
+	// Changes to the list go through set and remove, which note the products
+	// touched so that only those have stock taken off again.
+	touched := map[string]struct{}{}
+
+	set := func(id string, item ShoppingListItem) {
+		shoppingList[id] = item
+		touched[id] = struct{}{}
+	}
+
+	remove := func(id string) {
+		delete(shoppingList, id)
+		touched[id] = struct{}{}
+	}
+
+	add := func(id string, mealId string, q quantity.Quantity) {
+		shoppingListItem, ok := shoppingList[id]
+		if !ok {
+			shoppingListItem = ShoppingListItem{Product: prods[id]}
+		}
+		set(id, shoppingListItem.add(mealId, q))
+	}
+
+	// take removes a meal's quantity from a product, dropping the product from
+	// the list once no meal needs it.
+	take := func(id string, mealId string, q quantity.Quantity) {
+		shoppingListItem, ok := shoppingList[id]
+		if !ok {
+			return
+		}
+
+		shoppingListItem = shoppingListItem.remove(mealId, q)
+		if shoppingListItem.MealCount == 0 {
+			remove(id)
+		} else {
+			set(id, shoppingListItem)
+		}
+	}
+
+	removeMeal := func(mealId string) {
+		m := ms[mealId]
+		factor := m.ScaleFactor(s[mealId].Servings)
+		delete(s, mealId)
+		for _, i := range m.Ingredients {
+			take(i.ProductId, mealId, i.Quantity.Scale(factor))
+		}
+	}
+
+	apply := func(ev eventsourcing.Event) error {
+		if completed {
+			return nil
+		}
+
+		if belongsToShop(ev) && ev.AggregateID() != strconv.Itoa(*shopId) {
+			if _, ok := ev.Data().(*shop.Created); !ok {
+				return nil
+			}
+		}
+
 		switch event := ev.Data().(type) {
 		case *product.Created:
 			prod := product.Product{}
 			prod.Transition(ev)
 			prods[event.Id] = prod
+		case *product.Renamed, *product.CategoryChanged:
+			prod := prods[ev.AggregateID()]
+			prod.Transition(ev)
+			prods[ev.AggregateID()] = prod
+
+			if shoppingListItem, ok := shoppingList[ev.AggregateID()]; ok {
+				shoppingListItem.Product = prod
+				set(ev.AggregateID(), shoppingListItem)
+			}
 		case *meal.Created:
 			m := meal.Meal{}
 			m.Transition(ev)
 			ms[event.Id] = &m
 		case *shop.Created:
+			if target != nil && event.Id != *target {
+				break
+			}
 			shoppingList = map[string]ShoppingListItem{}
-			s = map[string]string{}
+			clear(output)
+			clear(touched)
+			s = map[string]shop.ShopMeal{}
+			items = make(map[string]*shop.Item)
 			*shopId = event.Id
+		case *shop.Completed:
+			completed = target != nil
 		case *basket.ItemAdded:
 			shoppingListItem, ok := shoppingList[event.Item.IngredientId]
 			if ok {
 				shoppingListItem.IsInBasket = true
-				shoppingList[event.Item.IngredientId] = shoppingListItem
+				set(event.Item.IngredientId, shoppingListItem)
 			}
 		case *basket.ItemRemoved:
 			shoppingListItem, ok := shoppingList[event.IngredientId]
 			if ok {
 				shoppingListItem.IsInBasket = false
-				shoppingList[event.IngredientId] = shoppingListItem
+				set(event.IngredientId, shoppingListItem)
 			}
 		case *shop.MealAdded:
-			s[event.Meal.MealId] = event.Meal.MealId
-			for _, i := range ms[event.Meal.MealId].Ingredients {
-				shoppingListItem, ok := shoppingList[i.ProductId]
-				if ok {
-					shoppingListItem.MealCount++
-					shoppingListItem.Quantities = append(shoppingListItem.Quantities, i.Quantity)
-					shoppingList[i.ProductId] = shoppingListItem
-				} else {
-					shoppingList[i.ProductId] = ShoppingListItem{Product: prods[i.ProductId], MealCount: 1, Quantities: []quantity.Quantity{i.Quantity}}
-				}
+			m, ok := ms[event.Meal.MealId]
+			if !ok || m.Archived {
+				break
+			}
+			s[event.Meal.MealId] = event.Meal
+			factor := m.ScaleFactor(event.Meal.Servings)
+			for _, i := range m.Ingredients {
+				add(i.ProductId, event.Meal.MealId, i.Quantity.Scale(factor))
 			}
 		case *shop.MealRemoved:
-			delete(s, event.Id)
-			for _, i := range ms[event.Id].Ingredients {
-				shoppingListItem, ok := shoppingList[i.ProductId]
-				if ok {
-					shoppingListItem.MealCount--
-					shoppingListItem.Quantities = removeQuantity(shoppingListItem.Quantities, i.Quantity)
-					shoppingList[i.ProductId] = shoppingListItem
-				}
+			if _, ok := s[event.Id]; ok {
+				removeMeal(event.Id)
+			}
+		case *meal.Archived:
+			ms[ev.AggregateID()].Transition(ev)
 
-				if shoppingListItem.MealCount == 0 {
-					delete(shoppingList, i.ProductId)
-				}
+			// An archived meal leaves the list, even though the shop still
+			// refers to it.
+			if _, ok := s[ev.AggregateID()]; ok {
+				removeMeal(ev.AggregateID())
 			}
 		case *meal.IngredientAdded:
 			m := ms[ev.AggregateID()]
 			m.Transition(ev)
-			if _, ok := s[ev.AggregateID()]; !ok {
+			shopMeal, ok := s[ev.AggregateID()]
+			if !ok {
 				break
 			}
-			shoppingListItem, ok := shoppingList[event.Ingredient.ProductId]
-			if ok {
-				shoppingListItem.MealCount++
-				shoppingListItem.Quantities = append(shoppingListItem.Quantities, event.Ingredient.Quantity)
-				shoppingList[event.Ingredient.ProductId] = shoppingListItem
-			} else {
-				shoppingList[event.Ingredient.ProductId] = ShoppingListItem{Product: prods[event.Ingredient.ProductId], MealCount: 1, Quantities: []quantity.Quantity{event.Ingredient.Quantity}}
-			}
+			add(event.Ingredient.ProductId, ev.AggregateID(), event.Ingredient.Quantity.Scale(m.ScaleFactor(shopMeal.Servings)))
 		case *meal.IngredientRemoved:
 			m := ms[ev.AggregateID()]
 
-			q, err := findQuantity(m.Ingredients, event.Id)
+			removed := m.RemovedIngredients(event)
+
+			if len(removed) == 0 {
+				return errors.New("ingredient not found")
+			}
+
+			m.Transition(ev)
+
+			shopMeal, ok := s[ev.AggregateID()]
+			if !ok {
+				break
+			}
+
+			for _, i := range removed {
+				take(i.ProductId, ev.AggregateID(), i.Quantity.Scale(m.ScaleFactor(shopMeal.Servings)))
+			}
+		case *meal.IngredientQuantityChanged:
+			m := ms[ev.AggregateID()]
+
+			i, err := findIngredient(m, event.Id)
 
 			if err != nil {
 				return err
@@ -113,58 +256,138 @@ func CreateShoppingListProjection(es *sqlStore.SQLite) (*eventsourcing.Projectio
 
 			m.Transition(ev)
 
-			if _, ok := s[ev.AggregateID()]; !ok {
+			shopMeal, ok := s[ev.AggregateID()]
+			if !ok {
 				break
 			}
 
-			shoppingListItem, ok := shoppingList[event.Id]
+			factor := m.ScaleFactor(shopMeal.Servings)
 
-			if ok {
-				shoppingListItem.MealCount--
-				shoppingListItem.Quantities = removeQuantity(shoppingListItem.Quantities, *q)
-				shoppingList[event.Id] = shoppingListItem
+			if shoppingListItem, ok := shoppingList[i.ProductId]; ok {
+				quantities := removeQuantity(shoppingListItem.Quantities, i.Quantity.Scale(factor))
+				set(i.ProductId, shoppingListItem.withQuantities(append(quantities, event.Quantity.Scale(factor))))
+			}
+		case *meal.ServingsUpdated:
+			m := ms[ev.AggregateID()]
+			shopMeal, ok := s[ev.AggregateID()]
+			if !ok {
+				m.Transition(ev)
+				break
 			}
 
-			if shoppingListItem.MealCount == 0 {
-				delete(shoppingList, event.Id)
+			oldFactor := m.ScaleFactor(shopMeal.Servings)
+			m.Transition(ev)
+			newFactor := m.ScaleFactor(shopMeal.Servings)
+
+			for _, i := range m.Ingredients {
+				shoppingListItem, ok := shoppingList[i.ProductId]
+				if !ok {
+					continue
+				}
+				quantities := removeQuantity(shoppingListItem.Quantities, i.Quantity.Scale(oldFactor))
+				set(i.ProductId, shoppingListItem.withQuantities(append(quantities, i.Quantity.Scale(newFactor))))
 			}
 		case *shop.ItemAdded:
-			shoppingListItem, ok := shoppingList[event.Item.ProductId]
-			if ok {
-				shoppingListItem.MealCount++
-				shoppingListItem.Quantities = append(shoppingListItem.Quantities, event.Item.Quantity)
-				shoppingList[event.Item.ProductId] = shoppingListItem
-				items[event.Item.ProductId] = event.Item
-			} else {
-				shoppingList[event.Item.ProductId] = ShoppingListItem{Product: prods[event.Item.ProductId], MealCount: 1, Quantities: []quantity.Quantity{event.Item.Quantity}}
-				items[event.Item.ProductId] = event.Item
-			}
+			add(event.Item.ProductId, shopItem, event.Item.Quantity)
+			items[event.Item.ProductId] = event.Item
+		case *pantry.Created:
+			stock.Transition(ev)
+		case *pantry.StockSet:
+			stock.Transition(ev)
+			touched[event.ProductId] = struct{}{}
+		case *pantry.StockAdjusted:
+			stock.Transition(ev)
+			touched[event.ProductId] = struct{}{}
+		case *pantry.StockCleared:
+			stock.Transition(ev)
+			touched[event.ProductId] = struct{}{}
 		case *shop.ItemRemoved:
 			shoppingListItem, ok := shoppingList[event.ProductId]
 			if ok {
-				shoppingListItem.MealCount--
-				shoppingListItem.Quantities = removeQuantity(shoppingListItem.Quantities, items[event.ProductId].Quantity)
-				shoppingList[event.ProductId] = shoppingListItem
+				set(event.ProductId, shoppingListItem.remove(shopItem, items[event.ProductId].Quantity))
 				delete(items, event.ProductId)
 			}
 		}
 
+		return nil
+	}
+
+	p := eventsourcing.NewProjection(func() (core.Iterator, error) {
+		return es.All(start)()
+	}, func(ev eventsourcing.Event) error {
+		if err := apply(ev); err != nil {
+			return err
+		}
+
+		for id := range touched {
+			subtractStock(id, shoppingList, stock, output)
+		}
+		clear(touched)
+
 		start = core.Version(ev.GlobalVersion() + 1)
 
 		return nil
 	})
 
-	return p, ShoppingListProjectionOutput{shopId, &shoppingList}
+	return p, ShoppingListProjectionOutput{shopId, &output}
+}
+
+// belongsToShop reports whether an event comes from a shop or its basket, both
+// of which share the shop's id.
+func belongsToShop(ev eventsourcing.Event) bool {
+	return ev.AggregateType() == "Shop" || ev.AggregateType() == "Basket"
 }
 
-func findQuantity(ingredients []meal.Ingredient, ingredientId string) (*quantity.Quantity, error) {
-	for _, i := range ingredients {
-		if i.ProductId == ingredientId {
-			return &i.Quantity, nil
+// subtractStock updates a product's entry in output with what still needs
+// buying once stock already in the pantry is taken off its total. Products the
+// pantry fully covers stay on the list, marked as covered, with an empty total.
+func subtractStock(id string, shoppingList map[string]ShoppingListItem, stock *pantry.Pantry, output map[string]ShoppingListItem) {
+	item, ok := shoppingList[id]
+	if !ok {
+		delete(output, id)
+		return
+	}
+
+	inPantry := stock.Stock(id)
+	if inPantry == nil {
+		output[id] = item
+		return
+	}
+
+	remaining := *inPantry
+	total := []quantity.Quantity{}
+	covered := true
+	for _, q := range item.Total {
+		s, err := quantity.Convert(remaining, q.Unit)
+		if err != nil {
+			total = append(total, q)
+			covered = false
+			continue
+		}
+
+		if q.Amount.Cmp(s.Amount) > 0 {
+			total = append(total, quantity.Quantity{Amount: q.Amount.Sub(s.Amount), Unit: q.Unit})
+			remaining = quantity.Quantity{Unit: q.Unit}
+			covered = false
+		} else {
+			remaining = quantity.Quantity{Amount: s.Amount.Sub(q.Amount), Unit: q.Unit}
 		}
 	}
 
-	return nil, errors.New("ingredient not found")
+	item.Total = total
+	item.InPantry = inPantry
+	item.Covered = covered
+	output[id] = item
+}
+
+func findIngredient(m *meal.Meal, ingredientId string) (meal.Ingredient, error) {
+	i := m.FindIngredient(ingredientId)
+
+	if i == nil {
+		return meal.Ingredient{}, errors.New("ingredient not found")
+	}
+
+	return *i, nil
 }
 
 func removeQuantity(quantities []quantity.Quantity, quantity quantity.Quantity) []quantity.Quantity {
@@ -4,18 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/hallgren/eventsourcing/aggregate"
 	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
 	"github.com/joe-reed/meal-planner/apps/api/internal/database"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
-	"strconv"
+	"slices"
 	"testing"
 )
 
@@ -25,6 +28,7 @@ type ShoppingListSuite struct {
 	shopRepository    *shop.ShopRepository
 	mealRepository    *meal.EventSourcedMealRepository
 	basketRepository  *basket.BasketRepository
+	pantryRepository  *pantry.PantryRepository
 	db                *sql.DB
 	es                *sqlStore.SQLite
 }
@@ -62,24 +66,195 @@ func (suite *ShoppingListSuite) TestAddingMealToShop() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
 }
 
+func (suite *ShoppingListSuite) TestSkippingUnknownMealAddedToShop() {
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, meal.NewMealBuilder().WithId("does-not-exist").Build())
+
+	output := suite.runProjection()
+
+	assert.Empty(suite.T(), *output.ShoppingList)
+}
+
 func (suite *ShoppingListSuite) TestAddingTwoMealsWithSameIngredient() {
 	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
 	productB := suite.addProduct("ing-b", "Ing B", category.Bakery)
 	productC := suite.addProduct("ing-c", "Ing C", category.Dairy)
 
 	meal1 := suite.addMeal([]meal.Ingredient{
-		*meal.NewIngredient(productA.Id).WithQuantity(2, quantity.Tbsp),
-		*meal.NewIngredient(productB.Id).WithQuantity(100, quantity.Ml),
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(2), quantity.Tbsp),
+		*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(100), quantity.Ml),
+	})
+	meal2 := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(50), quantity.Gram),
+		*meal.NewIngredient(productC.Id).WithQuantity(quantity.NewAmount(1), quantity.Litre),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, meal1)
+	suite.addMealToShop(s, meal2)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Tbsp}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Tbsp}}},
+			productB.Id: {Product: *productB, MealCount: 2, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Ml}, {Amount: quantity.NewAmount(50), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Ml}, {Amount: quantity.NewAmount(50), Unit: quantity.Gram}}},
+			productC.Id: {Product: *productC, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Litre}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Litre}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestTotallingCompatibleQuantities() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Vegetables)
+
+	meal1 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(200), quantity.Gram)})
+	meal2 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(2), quantity.Bunch)})
+	meal3 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(1), quantity.Kg)})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, meal1)
+	suite.addMealToShop(s, meal2)
+	suite.addMealToShop(s, meal3)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {
+				Product:    *productA,
+				MealCount:  3,
+				IsInBasket: false,
+				Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(200), Unit: quantity.Gram}, {Amount: quantity.NewAmount(2), Unit: quantity.Bunch}, {Amount: quantity.NewAmount(1), Unit: quantity.Kg}},
+				Total:      []quantity.Quantity{{Amount: quantity.NewFraction(6, 5), Unit: quantity.Kg}, {Amount: quantity.NewAmount(2), Unit: quantity.Bunch}},
+			},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestScalingMealForServings() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Vegetables)
+	productB := suite.addProduct("ing-b", "Ing B", category.Meat)
+
+	m := suite.addMealWithServings(4, []meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(2), quantity.Number),
+		*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(500), quantity.Gram),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShopWithServings(s, m, 6)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(3), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(3), Unit: quantity.Number}}},
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(750), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(750), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestScalingIngredientChangesForMealInShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Vegetables)
+	productB := suite.addProduct("ing-b", "Ing B", category.Meat)
+
+	m := suite.addMealWithServings(2, []meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(1), quantity.Tin),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShopWithServings(s, m, 4)
+
+	m.AddIngredient(*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(100), quantity.Gram))
+	err := suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	suite.removeIngredientFromMeal(m, productA)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(200), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(200), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestUpdatingServingsOfMealInShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Vegetables)
+
+	m := suite.addMealWithServings(4, []meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(400), quantity.Gram),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShopWithServings(s, m, 2)
+
+	m.UpdateServings(8)
+	err := suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestUsingProductTwiceInMeal() {
+	butter := suite.addProduct("butter", "Butter", category.Dairy)
+
+	m := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(50), quantity.Gram).WithNote("for the roux"),
+		*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(10), quantity.Gram).WithNote("for greasing"),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, m)
+
+	assert.NoError(suite.T(), m.RemoveIngredient(m.Ingredients[1].Id))
+	m.AddIngredient(*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(20), quantity.Gram).WithNote("for greasing"))
+	err := suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			butter.Id: {Product: *butter, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(50), Unit: quantity.Gram}, {Amount: quantity.NewAmount(20), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(70), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestCountingMealsUsingProductTwice() {
+	butter := suite.addProduct("butter", "Butter", category.Dairy)
+
+	meal1 := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(50), quantity.Gram).WithNote("for the roux"),
+		*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(10), quantity.Gram).WithNote("for greasing"),
 	})
 	meal2 := suite.addMeal([]meal.Ingredient{
-		*meal.NewIngredient(productB.Id).WithQuantity(50, quantity.Gram),
-		*meal.NewIngredient(productC.Id).WithQuantity(1, quantity.Litre),
+		*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(25), quantity.Gram),
 	})
 
 	s, _ := suite.addShop()
@@ -91,9 +266,130 @@ func (suite *ShoppingListSuite) TestAddingTwoMealsWithSameIngredient() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 2, Unit: quantity.Tbsp}}},
-			productB.Id: {Product: *productB, MealCount: 2, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 100, Unit: quantity.Ml}, {Amount: 50, Unit: quantity.Gram}}},
-			productC.Id: {Product: *productC, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Litre}}},
+			butter.Id: {Product: *butter, MealCount: 2, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(50), Unit: quantity.Gram}, {Amount: quantity.NewAmount(10), Unit: quantity.Gram}, {Amount: quantity.NewAmount(25), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(85), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+
+	suite.removeMealFromShop(s, meal2)
+
+	output = suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			butter.Id: {Product: *butter, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(50), Unit: quantity.Gram}, {Amount: quantity.NewAmount(10), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(60), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+
+	assert.NoError(suite.T(), meal1.RemoveIngredient(meal1.Ingredients[1].Id))
+	err := suite.mealRepository.Save(meal1)
+	assert.NoError(suite.T(), err)
+
+	output = suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			butter.Id: {Product: *butter, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(50), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(50), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestRemovingProductSavedBeforeIngredientsHadIds() {
+	butter := suite.addProduct("butter", "Butter", category.Dairy)
+	flour := suite.addProduct("flour", "Flour", category.Bakery)
+
+	m := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(50), quantity.Gram),
+		*meal.NewIngredient(flour.Id).WithQuantity(quantity.NewAmount(100), quantity.Gram),
+		*meal.NewIngredient(butter.Id).WithQuantity(quantity.NewAmount(10), quantity.Gram),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, m)
+
+	aggregate.TrackChange(m, &meal.IngredientRemoved{Id: butter.Id})
+	err := suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			flour.Id: {Product: *flour, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestChangingIngredientQuantityOfMealInShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Vegetables)
+
+	m := suite.addMealWithServings(4, []meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(400), quantity.Gram),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShopWithServings(s, m, 2)
+
+	err := m.ChangeIngredientQuantity(m.Ingredients[0].Id, quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+	assert.NoError(suite.T(), err)
+	err = suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewFraction(1, 2), Unit: quantity.Kg}}, Total: []quantity.Quantity{{Amount: quantity.NewFraction(1, 2), Unit: quantity.Kg}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestRemovingScaledMeal() {
+	productA := suite.addProduct("ing-a", "Ing A", category.Vegetables)
+
+	meal1 := suite.addMealWithServings(4, []meal.Ingredient{*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(400), quantity.Gram)})
+	meal2 := suite.addMealWithServings(2, []meal.Ingredient{*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(100), quantity.Gram)})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShopWithServings(s, meal1, 3)
+	suite.addMealToShop(s, meal2)
+	suite.removeMealFromShop(s, meal1)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(100), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestSchedulingMealsInShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+
+	meal1 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+
+	s, _ := suite.addShop()
+
+	s.AddMeal(&shop.ShopMeal{MealId: meal1.Id, Schedule: &shop.Schedule{Date: "2026-10-13", Slot: shop.Dinner}})
+	s.ScheduleMeal(meal1.Id, shop.Schedule{Date: "2026-10-15", Slot: shop.Lunch})
+	s.UnscheduleMeal(meal1.Id)
+	err := suite.shopRepository.Save(s)
+	assert.NoError(suite.T(), err)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -117,8 +413,36 @@ func (suite *ShoppingListSuite) TestRemovingMeal() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
-			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestArchivingMealInShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+	productB := suite.addProduct("ing-b", "Ing B", category.Bakery)
+
+	meal1 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id), *meal.NewIngredient(productB.Id)})
+	meal2 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productB.Id)})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, meal1)
+	suite.addMealToShop(s, meal2)
+
+	meal1.Archive()
+	err := suite.mealRepository.Save(meal1)
+	assert.NoError(suite.T(), err)
+
+	suite.removeMealFromShop(s, meal1)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -141,8 +465,8 @@ func (suite *ShoppingListSuite) TestAddingIngredientToMealBeforeAddingToShop() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
-			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -165,8 +489,8 @@ func (suite *ShoppingListSuite) TestAddingIngredientToMealInShop() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
-			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -192,9 +516,9 @@ func (suite *ShoppingListSuite) TestAddingIngredientToMoreThanOneMealInShop() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
-			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
-			productC.Id: {Product: *productC, MealCount: 2, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}, {Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+			productC.Id: {Product: *productC, MealCount: 2, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}, {Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -253,12 +577,12 @@ func (suite *ShoppingListSuite) TestRemovingIngredientFromMealInShop() {
 		"removing ingredients from a meal": {
 			initialMeals: [][]meal.Ingredient{
 				{
-					*meal.NewIngredient(productA.Id).WithQuantity(10, quantity.Lb),
-					*meal.NewIngredient(productB.Id).WithQuantity(5, quantity.Kg),
+					*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(10), quantity.Lb),
+					*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(5), quantity.Kg),
 				},
 				{
-					*meal.NewIngredient(productB.Id).WithQuantity(15, quantity.Bunch),
-					*meal.NewIngredient(productC.Id).WithQuantity(1, quantity.Pack),
+					*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(15), quantity.Bunch),
+					*meal.NewIngredient(productC.Id).WithQuantity(quantity.NewAmount(1), quantity.Pack),
 				},
 			},
 			removals: []struct {
@@ -269,17 +593,36 @@ func (suite *ShoppingListSuite) TestRemovingIngredientFromMealInShop() {
 				{mealIndex: 0, ingredient: productB},
 			},
 			expectedOutput: map[string]shoppinglist.ShoppingListItem{
-				productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 15, Unit: quantity.Bunch}}},
-				productC.Id: {Product: *productC, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Pack}}},
+				productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(15), Unit: quantity.Bunch}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(15), Unit: quantity.Bunch}}},
+				productC.Id: {Product: *productC, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Pack}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Pack}}},
+			},
+		},
+		"removing ingredient with fractional quantity": {
+			initialMeals: [][]meal.Ingredient{
+				{
+					*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewFraction(1, 3), quantity.Cup),
+				},
+				{
+					*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewFraction(1, 2), quantity.Cup),
+				},
+			},
+			removals: []struct {
+				mealIndex  int
+				ingredient *product.Product
+			}{
+				{mealIndex: 0, ingredient: productA},
+			},
+			expectedOutput: map[string]shoppinglist.ShoppingListItem{
+				productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewFraction(1, 2), Unit: quantity.Cup}}, Total: []quantity.Quantity{{Amount: quantity.NewFraction(1, 2), Unit: quantity.Cup}}},
 			},
 		},
 		"removing ingredient with same quantity from one of two meals": {
 			initialMeals: [][]meal.Ingredient{
 				{
-					*meal.NewIngredient(productA.Id).WithQuantity(10, quantity.Lb),
+					*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(10), quantity.Lb),
 				},
 				{
-					*meal.NewIngredient(productA.Id).WithQuantity(10, quantity.Lb),
+					*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(10), quantity.Lb),
 				},
 			},
 			removals: []struct {
@@ -289,7 +632,7 @@ func (suite *ShoppingListSuite) TestRemovingIngredientFromMealInShop() {
 				{mealIndex: 0, ingredient: productA},
 			},
 			expectedOutput: map[string]shoppinglist.ShoppingListItem{
-				productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 10, Unit: quantity.Lb}}},
+				productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(10), Unit: quantity.Lb}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(10), Unit: quantity.Lb}}},
 			},
 		},
 	}
@@ -323,7 +666,7 @@ func (suite *ShoppingListSuite) TestRemovingIngredientFromMealInShop() {
 func (suite *ShoppingListSuite) TestRemovingIngredientFromMealBeforeAddingToShop() {
 	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
 
-	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+	m := suite.addMeal([]meal.Ingredient{})
 
 	s, _ := suite.addShop()
 
@@ -356,7 +699,7 @@ func (suite *ShoppingListSuite) TestRemovingIngredientFromMealNotInShop() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -381,7 +724,7 @@ func (suite *ShoppingListSuite) TestStartingNewShop() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productB.Id: {Product: *productB, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -402,7 +745,7 @@ func (suite *ShoppingListSuite) TestAddingIngredientToBasket() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: true, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: true, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -425,7 +768,7 @@ func (suite *ShoppingListSuite) TestRemovingIngredientFromBasket() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -451,7 +794,7 @@ func (suite *ShoppingListSuite) TestAddingItemToShop() {
 	suite.addItemToShop(s, &shop.Item{
 		ProductId: productA.Id,
 		Quantity: quantity.Quantity{
-			Amount: 1,
+			Amount: quantity.NewAmount(1),
 			Unit:   quantity.Number,
 		},
 	})
@@ -463,7 +806,7 @@ func (suite *ShoppingListSuite) TestAddingItemToShop() {
 	// todo: should items in shop increase meal count?
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: true, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: true, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -479,7 +822,7 @@ func (suite *ShoppingListSuite) TestAddingItemToShopDirectlyAndViaMeal() {
 	suite.addItemToShop(s, &shop.Item{
 		ProductId: productA.Id,
 		Quantity: quantity.Quantity{
-			Amount: 1,
+			Amount: quantity.NewAmount(1),
 			Unit:   quantity.Number,
 		},
 	})
@@ -491,7 +834,7 @@ func (suite *ShoppingListSuite) TestAddingItemToShopDirectlyAndViaMeal() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 2, IsInBasket: true, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}, {Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 2, IsInBasket: true, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}, {Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -507,7 +850,7 @@ func (suite *ShoppingListSuite) TestRemovingItemFromShop() {
 	suite.addItemToShop(s, &shop.Item{
 		ProductId: productA.Id,
 		Quantity: quantity.Quantity{
-			Amount: 1,
+			Amount: quantity.NewAmount(1),
 			Unit:   quantity.Number,
 		},
 	})
@@ -519,7 +862,241 @@ func (suite *ShoppingListSuite) TestRemovingItemFromShop() {
 
 	assert.EqualExportedValues(suite.T(),
 		map[string]shoppinglist.ShoppingListItem{
-			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: 1, Unit: quantity.Number}}},
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestRenamingProductInShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+
+	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, m)
+
+	assert.NoError(suite.T(), productA.Rename("Ing B"))
+	assert.NoError(suite.T(), productA.ChangeCategory(category.Drinks))
+	assert.NoError(suite.T(), suite.productRepository.Save(productA))
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: product.Product{Id: productA.Id, Name: "Ing B", Category: category.Drinks}, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestSubtractingPantryStock() {
+	productA := suite.addProduct("ing-a", "Ing A", category.PastaRiceAndNoodles)
+	productB := suite.addProduct("ing-b", "Ing B", category.Vegetables)
+
+	meal1 := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(1), quantity.Kg),
+		*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(2), quantity.Bunch),
+	})
+	meal2 := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(500), quantity.Gram),
+		*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(100), quantity.Gram),
+	})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, meal1)
+	suite.addMealToShop(s, meal2)
+
+	suite.setPantryStock(productA.Id, quantity.Quantity{Amount: quantity.NewAmount(300), Unit: quantity.Gram})
+	suite.setPantryStock(productB.Id, quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {
+				Product:    *productA,
+				MealCount:  2,
+				Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Kg}, {Amount: quantity.NewAmount(500), Unit: quantity.Gram}},
+				Total:      []quantity.Quantity{{Amount: quantity.NewFraction(6, 5), Unit: quantity.Kg}},
+				InPantry:   &quantity.Quantity{Amount: quantity.NewAmount(300), Unit: quantity.Gram},
+			},
+			productB.Id: {
+				Product:    *productB,
+				MealCount:  2,
+				Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Bunch}, {Amount: quantity.NewAmount(100), Unit: quantity.Gram}},
+				Total:      []quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Bunch}},
+				InPantry:   &quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg},
+			},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestMarkingItemsCoveredByPantry() {
+	productA := suite.addProduct("ing-a", "Ing A", category.PastaRiceAndNoodles)
+	productB := suite.addProduct("ing-b", "Ing B", category.Vegetables)
+
+	m := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(2), quantity.Tbsp),
+		*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(1), quantity.Bunch),
+	})
+
+	suite.setPantryStock(productA.Id, quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Cup})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, m)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {
+				Product:    *productA,
+				MealCount:  1,
+				Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(2), Unit: quantity.Tbsp}},
+				Total:      []quantity.Quantity{},
+				InPantry:   &quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Cup},
+				Covered:    true,
+			},
+			productB.Id: {Product: *productB, MealCount: 1, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Bunch}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Bunch}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestClearingPantryStock() {
+	productA := suite.addProduct("ing-a", "Ing A", category.PastaRiceAndNoodles)
+
+	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(500), quantity.Gram)})
+
+	s, _ := suite.addShop()
+
+	suite.addMealToShop(s, m)
+
+	suite.setPantryStock(productA.Id, quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+
+	output := suite.runProjection()
+	assert.True(suite.T(), (*output.ShoppingList)[productA.Id].Covered)
+
+	p, err := suite.pantryRepository.Get()
+	assert.NoError(suite.T(), err)
+	p.ClearStock(productA.Id)
+	err = suite.pantryRepository.Save(p)
+	assert.NoError(suite.T(), err)
+
+	output = suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(500), Unit: quantity.Gram}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(500), Unit: quantity.Gram}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestFollowingPantryChangesAsTheyHappen() {
+	productA := suite.addProduct("ing-a", "Ing A", category.PastaRiceAndNoodles)
+	productB := suite.addProduct("ing-b", "Ing B", category.Vegetables)
+
+	m := suite.addMeal([]meal.Ingredient{
+		*meal.NewIngredient(productA.Id).WithQuantity(quantity.NewAmount(500), quantity.Gram),
+		*meal.NewIngredient(productB.Id).WithQuantity(quantity.NewAmount(1), quantity.Bunch),
+	})
+
+	s, _ := suite.addShop()
+	suite.addMealToShop(s, m)
+
+	projection, output := shoppinglist.CreateShoppingListProjection(suite.es)
+	projection.RunToEnd(context.Background())
+	assert.Len(suite.T(), *output.ShoppingList, 2)
+
+	suite.setPantryStock(productA.Id, quantity.Quantity{Amount: quantity.NewAmount(200), Unit: quantity.Gram})
+	projection.RunToEnd(context.Background())
+	assert.Equal(suite.T(), []quantity.Quantity{{Amount: quantity.NewAmount(300), Unit: quantity.Gram}}, (*output.ShoppingList)[productA.Id].Total)
+
+	suite.setPantryStock(productA.Id, quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+	projection.RunToEnd(context.Background())
+	assert.True(suite.T(), (*output.ShoppingList)[productA.Id].Covered)
+	assert.Empty(suite.T(), (*output.ShoppingList)[productA.Id].Total)
+	assert.False(suite.T(), (*output.ShoppingList)[productB.Id].Covered)
+
+	suite.removeMealFromShop(s, m)
+	projection.RunToEnd(context.Background())
+	assert.Empty(suite.T(), *output.ShoppingList)
+}
+
+func (suite *ShoppingListSuite) TestBuildingShoppingListForPastShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+	productB := suite.addProduct("ing-b", "Ing B", category.AlcoholicDrinks)
+
+	meal1 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+	meal2 := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productB.Id)})
+
+	shop1, basket1 := suite.addShop()
+	suite.addMealToShop(shop1, meal1)
+	suite.addItemToBasket(basket1, productA)
+
+	shop2, _ := suite.addShop()
+	suite.addMealToShop(shop2, meal2)
+
+	output := suite.runShopProjection(shop1.Id)
+
+	suite.Assert().Equal(shop1.Id, *output.ShopId)
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: true, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestKeepingShoppingListOfCompletedShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+	productB := suite.addProduct("ing-b", "Ing B", category.AlcoholicDrinks)
+
+	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+
+	s, _ := suite.addShop()
+	suite.addMealToShop(s, m)
+
+	err := s.Complete([]string{}, []string{productA.Id}, []string{})
+	assert.NoError(suite.T(), err)
+	err = suite.shopRepository.Save(s)
+	assert.NoError(suite.T(), err)
+
+	suite.addIngredientToMeal(m, productB)
+
+	output := suite.runShopProjection(s.Id)
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
+		},
+		*output.ShoppingList,
+	)
+}
+
+func (suite *ShoppingListSuite) TestIgnoringBasketOfPreviousShop() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+
+	m := suite.addMeal([]meal.Ingredient{*meal.NewIngredient(productA.Id)})
+
+	_, basket1 := suite.addShop()
+
+	shop2, _ := suite.addShop()
+	suite.addMealToShop(shop2, m)
+
+	suite.addItemToBasket(basket1, productA)
+
+	output := suite.runProjection()
+
+	assert.EqualExportedValues(suite.T(),
+		map[string]shoppinglist.ShoppingListItem{
+			productA.Id: {Product: *productA, MealCount: 1, IsInBasket: false, Quantities: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}, Total: []quantity.Quantity{{Amount: quantity.NewAmount(1), Unit: quantity.Number}}},
 		},
 		*output.ShoppingList,
 	)
@@ -533,6 +1110,14 @@ func (suite *ShoppingListSuite) runProjection() shoppinglist.ShoppingListProject
 	return output
 }
 
+func (suite *ShoppingListSuite) runShopProjection(shopId int) shoppinglist.ShoppingListProjectionOutput {
+	projection, output := shoppinglist.CreateShopShoppingListProjection(suite.es, shopId)
+
+	projection.RunToEnd(context.Background())
+
+	return output
+}
+
 func (suite *ShoppingListSuite) addIngredientToMeal(m *meal.Meal, product *product.Product) {
 	m.AddIngredient(*meal.NewIngredient(product.Id))
 
@@ -541,7 +1126,7 @@ func (suite *ShoppingListSuite) addIngredientToMeal(m *meal.Meal, product *produ
 }
 
 func (suite *ShoppingListSuite) addMeal(ingredients []meal.Ingredient) *meal.Meal {
-	id := strconv.Itoa(gofakeit.Number(100, 999))
+	id := uuid.NewString()
 	m := meal.NewMealBuilder().WithName("Meal " + id).WithId(id).AddIngredients(ingredients).Build()
 
 	err := suite.mealRepository.Save(m)
@@ -550,6 +1135,16 @@ func (suite *ShoppingListSuite) addMeal(ingredients []meal.Ingredient) *meal.Mea
 	return m
 }
 
+func (suite *ShoppingListSuite) addMealWithServings(servings int, ingredients []meal.Ingredient) *meal.Meal {
+	id := uuid.NewString()
+	m := meal.NewMealBuilder().WithName("Meal " + id).WithId(id).WithServings(servings).AddIngredients(ingredients).Build()
+
+	err := suite.mealRepository.Save(m)
+	assert.NoError(suite.T(), err)
+
+	return m
+}
+
 func (suite *ShoppingListSuite) addShop() (*shop.Shop, *basket.Basket) {
 	shopId := gofakeit.Number(1, 9999999)
 	s, err := shop.NewShop(shopId)
@@ -572,6 +1167,12 @@ func (suite *ShoppingListSuite) addMealToShop(s *shop.Shop, m *meal.Meal) {
 	assert.NoError(suite.T(), err)
 }
 
+func (suite *ShoppingListSuite) addMealToShopWithServings(s *shop.Shop, m *meal.Meal, servings int) {
+	s.AddMeal(&shop.ShopMeal{MealId: m.Id, Servings: servings})
+	err := suite.shopRepository.Save(s)
+	assert.NoError(suite.T(), err)
+}
+
 func (suite *ShoppingListSuite) removeMealFromShop(shop *shop.Shop, meal *meal.Meal) {
 	shop.RemoveMeal(meal.Id)
 	err := suite.shopRepository.Save(shop)
@@ -606,6 +1207,20 @@ func (suite *ShoppingListSuite) createRepositories(db *sql.DB) {
 	basketRepository, err := basket.NewSqliteBasketRepository(db)
 	assert.NoError(suite.T(), err)
 	suite.basketRepository = basketRepository
+
+	pantryRepository, err := pantry.NewSqlitePantryRepository(db)
+	assert.NoError(suite.T(), err)
+	suite.pantryRepository = pantryRepository
+}
+
+func (suite *ShoppingListSuite) setPantryStock(productId string, q quantity.Quantity) {
+	p, err := suite.pantryRepository.Get()
+	assert.NoError(suite.T(), err)
+
+	p.SetStock(productId, q)
+
+	err = suite.pantryRepository.Save(p)
+	assert.NoError(suite.T(), err)
 }
 
 func (suite *ShoppingListSuite) addProduct(id string, name product.ProductName, category category.CategoryName) *product.Product {
@@ -618,10 +1233,12 @@ func (suite *ShoppingListSuite) addProduct(id string, name product.ProductName,
 	return i
 }
 
-func (suite *ShoppingListSuite) removeIngredientFromMeal(meal *meal.Meal, product *product.Product) {
-	meal.RemoveIngredient(product.Id)
+// removeIngredientFromMeal removes the meal's first ingredient of the product.
+func (suite *ShoppingListSuite) removeIngredientFromMeal(m *meal.Meal, product *product.Product) {
+	i := slices.IndexFunc(m.Ingredients, func(i meal.Ingredient) bool { return i.ProductId == product.Id })
+	assert.NoError(suite.T(), m.RemoveIngredient(m.Ingredients[i].Id))
 
-	err := suite.mealRepository.Save(meal)
+	err := suite.mealRepository.Save(m)
 	assert.NoError(suite.T(), err)
 }
 
@@ -0,0 +1,28 @@
+package store
+
+import "github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+
+type Created struct {
+	Id     string
+	Name   string
+	Layout []category.CategoryName
+}
+
+type Renamed struct {
+	Name string
+}
+
+type LayoutChanged struct {
+	Layout []category.CategoryName
+}
+
+// ProductLocated records that a product is found in a different aisle in
+// this store to the one its category puts it in.
+type ProductLocated struct {
+	ProductId string
+	Category  category.CategoryName
+}
+
+type ProductLocationCleared struct {
+	ProductId string
+}
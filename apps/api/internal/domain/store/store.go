@@ -0,0 +1,109 @@
+package store
+
+import (
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"slices"
+)
+
+// Store is a supermarket we shop at. Its layout is the order its aisles are
+// walked in, by category; categories it leaves out follow in their usual
+// order. Locations put individual products in a different aisle to their
+// category's.
+type Store struct {
+	aggregate.Root
+	Id        string                           `json:"id"`
+	Name      string                           `json:"name"`
+	Layout    []category.CategoryName          `json:"layout"`
+	Locations map[string]category.CategoryName `json:"locations"`
+}
+
+func (s *Store) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Created:
+		s.Id = e.Id
+		s.Name = e.Name
+		s.Layout = e.Layout
+		s.Locations = map[string]category.CategoryName{}
+	case *Renamed:
+		s.Name = e.Name
+	case *LayoutChanged:
+		s.Layout = e.Layout
+	case *ProductLocated:
+		s.Locations[e.ProductId] = e.Category
+	case *ProductLocationCleared:
+		delete(s.Locations, e.ProductId)
+	}
+}
+
+func (s *Store) Register(r aggregate.RegisterFunc) {
+	r(&Created{}, &Renamed{}, &LayoutChanged{}, &ProductLocated{}, &ProductLocationCleared{})
+}
+
+func NewStore(id string, name string, layout []category.CategoryName) (*Store, error) {
+	s := &Store{}
+
+	err := s.SetID(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if layout == nil {
+		layout = []category.CategoryName{}
+	}
+
+	aggregate.TrackChange(s, &Created{Id: id, Name: name, Layout: layout})
+
+	return s, nil
+}
+
+func (s *Store) Rename(name string) {
+	if name != s.Name {
+		aggregate.TrackChange(s, &Renamed{Name: name})
+	}
+}
+
+func (s *Store) ChangeLayout(layout []category.CategoryName) {
+	aggregate.TrackChange(s, &LayoutChanged{Layout: layout})
+}
+
+func (s *Store) LocateProduct(productId string, c category.CategoryName) {
+	aggregate.TrackChange(s, &ProductLocated{ProductId: productId, Category: c})
+}
+
+func (s *Store) ClearProductLocation(productId string) {
+	if _, ok := s.Locations[productId]; ok {
+		aggregate.TrackChange(s, &ProductLocationCleared{ProductId: productId})
+	}
+}
+
+// Aisle is the category a product is shelved under in this store.
+func (s *Store) Aisle(productId string, c category.CategoryName) category.CategoryName {
+	if located, ok := s.Locations[productId]; ok {
+		return located
+	}
+
+	return c
+}
+
+// Arrange returns a copy of the categories in the order they're walked in
+// this store.
+func (s *Store) Arrange(categories *category.Categories) *category.Categories {
+	arranged := &category.Categories{Categories: slices.Clone(categories.Categories)}
+
+	slices.SortStableFunc(arranged.Categories, func(a, b *category.Category) int {
+		return s.position(a.Id) - s.position(b.Id)
+	})
+
+	return arranged
+}
+
+func (s *Store) position(id category.CategoryName) int {
+	if i := slices.Index(s.Layout, id); i != -1 {
+		return i
+	}
+
+	return len(s.Layout)
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/readmodel"
+)
+
+// storeReadModel keeps the latest state of every store in a table, so stores
+// can be listed without replaying the event log.
+type storeReadModel struct {
+	db     *sql.DB
+	runner *readmodel.Runner
+}
+
+func newStoreReadModel(db *sql.DB, es *sqlStore.SQLite) (*storeReadModel, error) {
+	runner, err := readmodel.NewRunner(db, es, readmodel.Projection{
+		Name: "stores",
+		Tables: []string{
+			`CREATE TABLE IF NOT EXISTS store_read_model (
+				id   VARCHAR PRIMARY KEY,
+				name VARCHAR NOT NULL,
+				data TEXT NOT NULL
+			);`,
+		},
+		Handle: handleStoreEvent,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &storeReadModel{db: db, runner: runner}, nil
+}
+
+func handleStoreEvent(tx *sql.Tx, ev eventsourcing.Event) error {
+	if ev.AggregateType() != "Store" {
+		return nil
+	}
+
+	s := &Store{}
+
+	var data []byte
+	err := tx.QueryRow(`SELECT data FROM store_read_model WHERE id = ?`, ev.AggregateID()).Scan(&data)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return err
+		}
+	}
+
+	s.Transition(ev)
+
+	data, err = json.Marshal(s)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO store_read_model (id, name, data) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, data = excluded.data`,
+		ev.AggregateID(), s.Name, data,
+	)
+
+	return err
+}
+
+func (r *storeReadModel) all() ([]*Store, error) {
+	if err := r.runner.CatchUp(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT data FROM store_read_model ORDER BY name`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	stores := []*Store{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		s := &Store{}
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+
+		stores = append(stores, s)
+	}
+
+	return stores, rows.Err()
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	_ "github.com/mattn/go-sqlite3"
+	"sort"
+)
+
+type StoreRepository struct {
+	es        core.EventStore
+	all       func() (core.Iterator, error)
+	readModel *storeReadModel
+}
+
+func NewStoreRepository(es core.EventStore, all func() (core.Iterator, error)) *StoreRepository {
+	aggregate.Register(&Store{})
+	return &StoreRepository{es: es, all: all}
+}
+
+func NewSqliteStoreRepository(db *sql.DB) (*StoreRepository, error) {
+	es, err := database.NewEventStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewStoreRepository(es, func() (core.Iterator, error) {
+		return es.All(0)()
+	})
+
+	r.readModel, err = newStoreReadModel(db, es.SQLite)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func NewFakeStoreRepository() *StoreRepository {
+	es := memory.Create()
+
+	return NewStoreRepository(es, func() (core.Iterator, error) {
+		return es.All(0, 100000)()
+	})
+}
+
+func (r StoreRepository) Get() ([]*Store, error) {
+	if r.readModel != nil {
+		return r.readModel.all()
+	}
+
+	storeMap := map[string]*Store{}
+
+	p := eventsourcing.NewProjection(
+		r.all,
+		func(e eventsourcing.Event) error {
+			if e.AggregateType() != "Store" {
+				return nil
+			}
+
+			s, ok := storeMap[e.AggregateID()]
+			if !ok {
+				s = &Store{}
+				storeMap[e.AggregateID()] = s
+			}
+
+			s.Transition(e)
+
+			return nil
+		})
+
+	(*p).Strict = false
+	_, result := p.RunOnce()
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	stores := make([]*Store, 0, len(storeMap))
+	for _, s := range storeMap {
+		stores = append(stores, s)
+	}
+
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].Name < stores[j].Name
+	})
+
+	return stores, nil
+}
+
+func (r StoreRepository) Find(id string) (*Store, error) {
+	s := &Store{}
+
+	err := aggregate.Load(context.Background(), r.es, id, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r StoreRepository) Save(s *Store) error {
+	return aggregate.Save(r.es, s)
+}
@@ -0,0 +1,118 @@
+package store_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestFakeStoreRepository(t *testing.T) {
+	runSuite(t, func() *store.StoreRepository {
+		return store.NewFakeStoreRepository()
+	}, func() {})
+}
+
+func TestSqliteStoreRepository(t *testing.T) {
+	runSuite(t, func() *store.StoreRepository {
+		db, err := database.CreateDatabase("test.db")
+		assert.NoError(t, err)
+		r, err := store.NewSqliteStoreRepository(db)
+		assert.NoError(t, err)
+		return r
+	}, func() {
+		err := os.Remove("test.db")
+		assert.NoError(t, err)
+	})
+}
+
+func runSuite(t *testing.T, factory func() *store.StoreRepository, teardown func()) {
+	tests := []struct {
+		title string
+		run   func(t *testing.T, r *store.StoreRepository)
+	}{
+		{"saving and finding a store", testSavingAndFindingStore},
+		{"getting stores", testGettingStores},
+		{"getting stores after a rename", testGettingStoresAfterRename},
+		{"locating products", testLocatingProducts},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.run(t, factory())
+			teardown()
+		})
+	}
+}
+
+func testSavingAndFindingStore(t *testing.T, r *store.StoreRepository) {
+	s, err := store.NewStore("corner", "Corner Shop", []category.CategoryName{category.Bakery, category.Fruit})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Save(s))
+
+	s.Rename("Big Shop")
+	s.ChangeLayout([]category.CategoryName{category.Dairy})
+	assert.NoError(t, r.Save(s))
+
+	found, err := r.Find("corner")
+	assert.NoError(t, err)
+	assert.Equal(t, "Big Shop", found.Name)
+	assert.Equal(t, []category.CategoryName{category.Dairy}, found.Layout)
+}
+
+func testGettingStores(t *testing.T, r *store.StoreRepository) {
+	for id, name := range map[string]string{"b": "Supermarket", "a": "Corner Shop"} {
+		s, err := store.NewStore(id, name, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Save(s))
+	}
+
+	stores, err := r.Get()
+	assert.NoError(t, err)
+
+	assert.Len(t, stores, 2)
+	assert.Equal(t, "Corner Shop", stores[0].Name)
+	assert.Equal(t, "Supermarket", stores[1].Name)
+}
+
+func testGettingStoresAfterRename(t *testing.T, r *store.StoreRepository) {
+	a, err := store.NewStore("a", "Corner Shop", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Save(a))
+
+	b, err := store.NewStore("b", "Supermarket", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Save(b))
+
+	_, err = r.Get()
+	assert.NoError(t, err)
+
+	a.Rename("Village Shop")
+	a.LocateProduct("bananas", category.Bakery)
+	assert.NoError(t, r.Save(a))
+
+	stores, err := r.Get()
+	assert.NoError(t, err)
+
+	assert.Len(t, stores, 2)
+	assert.Equal(t, "Supermarket", stores[0].Name)
+	assert.Equal(t, "Village Shop", stores[1].Name)
+	assert.Equal(t, map[string]category.CategoryName{"bananas": category.Bakery}, stores[1].Locations)
+}
+
+func testLocatingProducts(t *testing.T, r *store.StoreRepository) {
+	s, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	s.LocateProduct("bananas", category.Bakery)
+	s.LocateProduct("milk", category.Drinks)
+	s.ClearProductLocation("milk")
+	assert.NoError(t, r.Save(s))
+
+	found, err := r.Find("corner")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]category.CategoryName{"bananas": category.Bakery}, found.Locations)
+	assert.Equal(t, category.Bakery, found.Aisle("bananas", category.Fruit))
+	assert.Equal(t, category.Dairy, found.Aisle("milk", category.Dairy))
+}
@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAddingCategory(t *testing.T) {
+	repo := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/categories", strings.NewReader(`{"id":"world-foods","name":"World Foods"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(repo)}
+
+	if assert.NoError(t, h.AddCategory(c)) {
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, `{"id":"world-foods","name":"World Foods"}`+"\n", rec.Body.String())
+
+		categories, err := repo.Get()
+		assert.NoError(t, err)
+		assert.Len(t, categories.Categories, len(category.Defaults)+1)
+		assert.Equal(t, len(category.Defaults), categories.Position("world-foods"))
+	}
+}
+
+func TestAddingCategoryWithDuplicateName(t *testing.T) {
+	repo := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/categories", strings.NewReader(`{"id":"fruit-2","name":"Fruit"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(repo)}
+
+	if assert.NoError(t, h.AddCategory(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, `{"error":"category already exists","categoryName":"Fruit"}`+"\n", rec.Body.String())
+	}
+}
+
+func TestAddingCategoryWithEmptyName(t *testing.T) {
+	repo := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/categories", strings.NewReader(`{"id":"world-foods","name":""}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(repo)}
+
+	if assert.NoError(t, h.AddCategory(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
@@ -3,8 +3,10 @@ package handlers_test
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -26,13 +28,14 @@ func TestAddingIngredientToMeal(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("123")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddIngredientToMeal(c)) {
 		m, err := repo.Find("123")
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-1", Quantity: quantity.Quantity{Amount: 3, Unit: quantity.Cup}}}, m.Ingredients)
+		assert.Len(t, m.Ingredients, 1)
+		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-1", Id: m.Ingredients[0].Id, Quantity: quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Cup}}}, m.Ingredients)
 	}
 }
 
@@ -49,12 +52,96 @@ func TestAddingIngredientToMealWithoutQuantity(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("123")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddIngredientToMeal(c)) {
 		m, err := repo.Find("123")
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-1", Quantity: quantity.Quantity{Amount: 1, Unit: quantity.Number}}}, m.Ingredients)
+		assert.Len(t, m.Ingredients, 1)
+		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-1", Id: m.Ingredients[0].Id, Quantity: quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Number}}}, m.Ingredients)
+	}
+}
+
+func TestAddingIngredientToMealWithProductDefaultQuantity(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").Build())
+	assert.NoError(t, err)
+
+	products := product.NewFakeProductRepository()
+	p := product.NewProductBuilder().WithId("ing-1").WithName("Rice").Build()
+	assert.NoError(t, p.SetDefaultQuantity(quantity.Quantity{Amount: quantity.NewAmount(75), Unit: quantity.Gram}))
+	assert.NoError(t, products.Add(p))
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/123/ingredients", strings.NewReader(`{"id": "ing-1" }`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, products, unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddIngredientToMeal(c)) {
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, m.Ingredients, 1)
+		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-1", Id: m.Ingredients[0].Id, Quantity: quantity.Quantity{Amount: quantity.NewAmount(75), Unit: quantity.Gram}}}, m.Ingredients)
+	}
+}
+
+func TestAddingSameProductToMealTwice(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("Pie").AddIngredient(*meal.NewIngredient("butter").WithNote("for the pastry")).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/123/ingredients", strings.NewReader(`{"id": "butter", "note": "for greasing"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddIngredientToMeal(c)) {
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Len(t, m.Ingredients, 2)
+		assert.NotEqual(t, m.Ingredients[0].Id, m.Ingredients[1].Id)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"123","name":"Pie","url":"","servings":0,"ingredients":[`+
+			`{"id":"butter","ingredientId":"`+m.Ingredients[0].Id+`","quantity":{"amount":1,"unit":"Number"},"note":"for the pastry"},`+
+			`{"id":"butter","ingredientId":"`+m.Ingredients[1].Id+`","quantity":{"amount":1,"unit":"Number"},"note":"for greasing"}`+
+			`]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestAddingIngredientToMealWithZeroAmount(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/123/ingredients", strings.NewReader(`{"id": "ing-1", "quantity": {"amount": 0, "unit": "Cup"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddIngredientToMeal(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"amount must be greater than zero"}`+"\n", rec.Body.String())
+
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Empty(t, m.Ingredients)
 	}
 }
@@ -37,3 +37,31 @@ func TestAddingItemToBasket(t *testing.T) {
 		assert.Equal(t, []*basket.BasketItem{{IngredientId: "ing-1"}}, b.Items)
 	}
 }
+
+func TestAddingItemToFrozenBasket(t *testing.T) {
+	b, err := basket.NewBasket(1)
+	assert.NoError(t, err)
+	b.Freeze()
+
+	br := basket.NewFakeBasketRepository()
+
+	err = br.Save(b)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/baskets/1/items", strings.NewReader(`{"ingredientId":"ing-1"}`))
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("shopId")
+	c.SetParamValues("1")
+	h := &handlers.BasketHandler{Application: application.NewBasketApplication(br)}
+
+	if assert.NoError(t, h.AddItemToBasket(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, `{"error":"basket has been frozen"}`+"\n", rec.Body.String())
+		b, _ := br.FindByShopId(1)
+		assert.Equal(t, []*basket.BasketItem{}, b.Items)
+	}
+}
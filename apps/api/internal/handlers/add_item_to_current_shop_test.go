@@ -2,9 +2,13 @@ package handlers_test
 
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -27,11 +31,36 @@ func TestAddingItemToCurrentShop(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddItemToCurrentShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		s, _ := r.Find(1)
-		assert.Equal(t, []*shop.Item{{ProductId: "abc", Quantity: quantity.Quantity{Amount: 3, Unit: quantity.Cup}}}, s.Items)
+		assert.Equal(t, []*shop.Item{{ProductId: "abc", Quantity: quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Cup}}}, s.Items)
+	}
+}
+
+func TestAddingItemToCompletedShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = s.Complete([]string{}, []string{}, []string{})
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops/current/items", strings.NewReader(`{"productId": "abc", "quantity": {"amount": 3, "unit": "Cup"}}`))
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddItemToCurrentShop(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		s, _ := r.Find(1)
+		assert.Empty(t, s.Items)
 	}
 }
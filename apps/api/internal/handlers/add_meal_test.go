@@ -4,7 +4,9 @@ import (
 	"errors"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -21,14 +23,14 @@ func TestAddingMeal(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddMeal(c)) {
 		m, err := repo.Get()
 		assert.NoError(t, err)
 		assert.Len(t, m, 1)
 		assert.Equal(t, http.StatusCreated, rec.Code)
-		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"\",\"ingredients\":[]}\n", rec.Body.String())
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"\",\"servings\":0,\"ingredients\":[]}\n", rec.Body.String())
 		assert.EqualExportedValues(t, &meal.Meal{Id: "123", Name: "foo", Ingredients: make([]meal.Ingredient, 0)}, m[0])
 	}
 }
@@ -41,14 +43,14 @@ func TestAddingMealWithWithUrl(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddMeal(c)) {
 		m, err := repo.Get()
 		assert.NoError(t, err)
 		assert.Len(t, m, 1)
 		assert.Equal(t, http.StatusCreated, rec.Code)
-		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"https://example.com\",\"ingredients\":[]}\n", rec.Body.String())
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"https://example.com\",\"servings\":0,\"ingredients\":[]}\n", rec.Body.String())
 		assert.EqualExportedValues(t, &meal.Meal{Id: "123", Name: "foo", Url: "https://example.com", Ingredients: make([]meal.Ingredient, 0)}, m[0])
 	}
 }
@@ -64,7 +66,7 @@ func TestAddingDuplicateMeal(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddMeal(c)) {
 		m, err := repo.Get()
@@ -82,7 +84,7 @@ func TestAddingMealWithEmptyId(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddMeal(c)) {
 		m, err := repo.Get()
@@ -100,7 +102,7 @@ func TestAddingMealWithEmptyName(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddMeal(c)) {
 		m, err := repo.Get()
@@ -124,6 +126,9 @@ func (r MealRepoWithError) Save(m *meal.Meal) error {
 func (r MealRepoWithError) FindByName(name string) (*meal.Meal, error) {
 	return nil, errors.New("error")
 }
+func (r MealRepoWithError) In(unit *unitofwork.Unit) meal.MealRepository {
+	return r
+}
 
 func TestAddingMealWithUnknownError(t *testing.T) {
 	repo := MealRepoWithError{}
@@ -133,7 +138,7 @@ func TestAddingMealWithUnknownError(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	assert.Error(t, h.AddMeal(c), "error")
 }
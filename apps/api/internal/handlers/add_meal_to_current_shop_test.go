@@ -2,8 +2,12 @@ package handlers_test
 
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -26,7 +30,7 @@ func TestAddingMealToCurrentShop(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, newShopMealRepository(t), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.AddMealToCurrentShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -34,3 +38,117 @@ func TestAddingMealToCurrentShop(t *testing.T) {
 		assert.Equal(t, []*shop.ShopMeal{{MealId: "abc"}}, s.Meals)
 	}
 }
+
+func TestAddingMealToCurrentShopWithServings(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops/current/meals", strings.NewReader(`{"id":"abc","servings":6}`))
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, newShopMealRepository(t), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddMealToCurrentShop(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":1,"meals":[{"id":"abc","servings":6}],"items":[]}`+"\n", rec.Body.String())
+		s, _ := r.Find(1)
+		assert.Equal(t, []*shop.ShopMeal{{MealId: "abc", Servings: 6}}, s.Meals)
+	}
+}
+
+func TestAddingMealToCompletedShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = s.Complete([]string{}, []string{}, []string{})
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops/current/meals", strings.NewReader(`{"id":"abc"}`))
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, newShopMealRepository(t), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddMealToCurrentShop(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, `{"error":"shop has been completed"}`+"\n", rec.Body.String())
+		s, _ := r.Find(1)
+		assert.Empty(t, s.Meals)
+	}
+}
+
+func TestAddingUnknownMealToCurrentShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops/current/meals", strings.NewReader(`{"id":"does-not-exist"}`))
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, newShopMealRepository(t), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddMealToCurrentShop(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"meal not found","mealId":"does-not-exist"}`+"\n", rec.Body.String())
+		s, _ := r.Find(1)
+		assert.Empty(t, s.Meals)
+	}
+}
+
+func TestAddingArchivedMealToCurrentShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	m := meal.NewMealBuilder().WithId("abc").WithName("foo").Build()
+	m.Archive()
+
+	meals := meal.NewFakeMealRepository()
+	err = meals.Save(m)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops/current/meals", strings.NewReader(`{"id":"abc"}`))
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meals, store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.AddMealToCurrentShop(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, `{"error":"meal has been archived"}`+"\n", rec.Body.String())
+		s, _ := r.Find(1)
+		assert.Empty(t, s.Meals)
+	}
+}
+
+func newShopMealRepository(t *testing.T) *meal.EventSourcedMealRepository {
+	meals := meal.NewFakeMealRepository()
+
+	err := meals.Save(meal.NewMealBuilder().WithId("abc").WithName("foo").Build())
+	assert.NoError(t, err)
+
+	return meals
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -23,7 +24,7 @@ func TestAddingProduct(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo)}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
 
 	if assert.NoError(t, h.AddProduct(c)) {
 		m, err := repo.Get()
@@ -46,7 +47,7 @@ func TestAddingProductWithDuplicateName(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo)}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
 
 	if assert.NoError(t, h.AddProduct(c)) {
 		m, err := repo.Get()
@@ -64,7 +65,7 @@ func TestAddingProductWithEmptyCategory(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo)}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
 
 	if assert.NoError(t, h.AddProduct(c)) {
 		m, err := repo.Get()
@@ -74,6 +75,47 @@ func TestAddingProductWithEmptyCategory(t *testing.T) {
 	}
 }
 
+func TestAddingProductWithUnknownCategory(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/products", strings.NewReader(`{"id": "123","name":"foo","category":"Nope"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.AddProduct(c)) {
+		m, err := repo.Get()
+		assert.NoError(t, err)
+		assert.Len(t, m, 0)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "{\"error\":\"category does not exist\"}\n", rec.Body.String())
+	}
+}
+
+func TestAddingProductInNewCategory(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	categories := category.NewFakeCategoryRepository()
+
+	c, err := categories.Get()
+	assert.NoError(t, err)
+	assert.NoError(t, c.Create("world-foods", "World Foods"))
+	assert.NoError(t, categories.Save(c))
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/products", strings.NewReader(`{"id": "123","name":"foo","category":"world-foods"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, categories)}
+
+	if assert.NoError(t, h.AddProduct(ctx)) {
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"category\":\"world-foods\"}\n", rec.Body.String())
+	}
+}
+
 func TestAddingProductWithEmptyName(t *testing.T) {
 	repo := product.NewFakeProductRepository()
 
@@ -82,7 +124,7 @@ func TestAddingProductWithEmptyName(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo)}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
 
 	if assert.NoError(t, h.AddProduct(c)) {
 		m, err := repo.Get()
@@ -100,7 +142,7 @@ func TestAddingProductWithEmptyId(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo)}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
 
 	if assert.NoError(t, h.AddProduct(c)) {
 		m, err := repo.Get()
@@ -115,6 +157,12 @@ type ProductRepoWithError struct{}
 func (r ProductRepoWithError) Add(p *product.Product) error {
 	return errors.New("error")
 }
+func (r ProductRepoWithError) Save(p *product.Product) error {
+	return errors.New("error")
+}
+func (r ProductRepoWithError) Find(id string) (*product.Product, error) {
+	return nil, errors.New("error")
+}
 func (r ProductRepoWithError) Get() ([]*product.Product, error) {
 	return nil, errors.New("error")
 }
@@ -124,6 +172,9 @@ func (r ProductRepoWithError) GetByName(name product.ProductName) (*product.Prod
 func (r ProductRepoWithError) FindByName(name product.ProductName) (*product.Product, error) {
 	return nil, errors.New("error")
 }
+func (r ProductRepoWithError) In(unit *unitofwork.Unit) product.ProductRepository {
+	return r
+}
 
 func TestAddingProductWithUnknownError(t *testing.T) {
 	repo := ProductRepoWithError{}
@@ -133,7 +184,7 @@ func TestAddingProductWithUnknownError(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo)}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
 
 	assert.Error(t, h.AddProduct(c), "error")
 }
@@ -0,0 +1,77 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAddingStore(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/stores", strings.NewReader(`{"id":"corner","name":"Corner Shop","layout":["Bakery","Fruit"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.AddStore(c)) {
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, `{"id":"corner","name":"Corner Shop","layout":["Bakery","Fruit"],"locations":{}}`+"\n", rec.Body.String())
+
+		s, err := repo.Find("corner")
+		assert.NoError(t, err)
+		assert.Equal(t, "Corner Shop", s.Name)
+	}
+}
+
+func TestAddingStoreWithDuplicateName(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	s, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Save(s))
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/stores", strings.NewReader(`{"id":"other","name":"Corner Shop"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.AddStore(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, `{"error":"store already exists","storeName":"Corner Shop"}`+"\n", rec.Body.String())
+	}
+}
+
+func TestAddingStoreWithUnknownCategoryInLayout(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/stores", strings.NewReader(`{"id":"corner","name":"Corner Shop","layout":["Toys"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.StoresHandler{Application: newStoreApplication(store.NewFakeStoreRepository())}
+
+	if assert.NoError(t, h.AddStore(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"category does not exist"}`+"\n", rec.Body.String())
+	}
+}
+
+func newStoreApplication(r *store.StoreRepository) *application.StoreApplication {
+	return newStoreApplicationWithProducts(r, product.NewFakeProductRepository())
+}
+
+func newStoreApplicationWithProducts(r *store.StoreRepository, products product.ProductRepository) *application.StoreApplication {
+	return application.NewStoreApplication(r, category.NewFakeCategoryRepository(), products, shop.NewFakeShopRepository())
+}
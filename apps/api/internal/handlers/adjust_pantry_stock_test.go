@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdjustingPantryStock(t *testing.T) {
+	p, err := pantry.NewPantry()
+	assert.NoError(t, err)
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+
+	r := pantry.NewFakePantryRepository()
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/pantry/items/rice/adjustments", strings.NewReader(`{"amount": -200, "unit": "Gram"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("productId")
+	c.SetParamValues("rice")
+	h := &handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	if assert.NoError(t, h.AdjustStock(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"items":[{"productId":"rice","quantity":{"amount":0.8,"unit":"Kg"}}]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestAdjustingPantryStockWithIncompatibleUnit(t *testing.T) {
+	p, err := pantry.NewPantry()
+	assert.NoError(t, err)
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+
+	r := pantry.NewFakePantryRepository()
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/pantry/items/rice/adjustments", strings.NewReader(`{"amount": 1, "unit": "Cup"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("productId")
+	c.SetParamValues("rice")
+	h := &handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	if assert.NoError(t, h.AdjustStock(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"cannot convert Cup to Kg"}`+"\n", rec.Body.String())
+		p, _ := r.Get()
+		assert.Equal(t, &quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg}, p.Stock("rice"))
+	}
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestArchivingMeal(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("foo").WithUrl("foo.localhost").Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/meals/123", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.ArchiveMeal(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"foo.localhost\",\"servings\":0,\"ingredients\":[],\"archived\":true}\n", rec.Body.String())
+
+		m, err := repo.Get()
+		assert.NoError(t, err)
+		assert.Empty(t, m)
+	}
+}
+
+func TestArchivingMealThatDoesNotExist(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/meals/123", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.ArchiveMeal(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "{\"error\":\"meal not found\",\"mealId\":\"123\"}\n", rec.Body.String())
+	}
+}
@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestArchivingProduct(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	err := repo.Add(product.NewProductBuilder().WithId("123").WithName("Tomatoes").WithCategory(category.Fruit).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/products/123", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.ArchiveProduct(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"Tomatoes\",\"category\":\"Fruit\",\"archived\":true}\n", rec.Body.String())
+
+		ps, err := repo.Get()
+		assert.NoError(t, err)
+		assert.Empty(t, ps)
+	}
+}
+
+func TestRenamingArchivedProduct(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	p := product.NewProductBuilder().WithId("123").WithName("Tomatoes").Build()
+	p.Archive()
+	err := repo.Add(p)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/products/123", strings.NewReader(`{"name": "Tomato"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.UpdateProduct(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "{\"error\":\"product has been archived\"}\n", rec.Body.String())
+	}
+}
+
+func TestArchivingProductThatDoesNotExist(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/products/123", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.ArchiveProduct(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
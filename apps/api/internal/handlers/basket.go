@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"strconv"
@@ -10,6 +12,7 @@ import (
 
 type BasketHandler struct {
 	Application *application.BasketApplication
+	Feed        *live.Feed
 }
 
 func (h *BasketHandler) AddItemToBasket(c echo.Context) error {
@@ -27,7 +30,7 @@ func (h *BasketHandler) AddItemToBasket(c echo.Context) error {
 	b, err := h.Application.AddItemToBasket(shopId, i)
 
 	if err != nil {
-		return err
+		return handleBasketError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, b)
@@ -45,7 +48,7 @@ func (h *BasketHandler) RemoveItemFromBasket(c echo.Context) error {
 	b, err := h.Application.RemoveItemFromBasket(shopId, ingredientId)
 
 	if err != nil {
-		return err
+		return handleBasketError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, b)
@@ -67,6 +70,36 @@ func (h *BasketHandler) GetBasket(c echo.Context) error {
 	return c.JSON(http.StatusOK, b)
 }
 
+func (h *BasketHandler) StreamBasket(c echo.Context) error {
+	shopId, err := getShopIdFromContext(c)
+
+	if err != nil {
+		return err
+	}
+
+	b, err := h.Application.GetBasket(shopId)
+
+	if err != nil {
+		return err
+	}
+
+	return stream(c, h.Feed, streamEvent{Name: "basket", Data: b}, changedJSON("basket", b, func() (any, error) {
+		return h.Application.GetBasket(shopId)
+	}))
+}
+
+func handleBasketError(c echo.Context, err error) error {
+	if errors.Is(err, basket.ErrBasketFrozen) {
+		return c.JSON(http.StatusConflict, struct {
+			Error string `json:"error"`
+		}{
+			Error: err.Error(),
+		})
+	}
+
+	return err
+}
+
 func getShopIdFromContext(c echo.Context) (int, error) {
 	return strconv.Atoi(c.Param("shopId"))
 }
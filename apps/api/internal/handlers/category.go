@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -12,5 +14,116 @@ type CategoriesHandler struct {
 }
 
 func (h *CategoriesHandler) GetCategories(c echo.Context) error {
-	return c.JSON(http.StatusOK, h.Application.GetCategories())
+	categories, err := h.Application.GetCategories()
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+func (h *CategoriesHandler) AddCategory(c echo.Context) error {
+	body := new(category.Category)
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	added, err := h.Application.AddCategory(body.Id, body.Name)
+
+	if err != nil {
+		return handleCategoryError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, added)
+}
+
+func (h *CategoriesHandler) RenameCategory(c echo.Context) error {
+	body := new(struct {
+		Name string `json:"name"`
+	})
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	renamed, err := h.Application.RenameCategory(category.CategoryName(c.Param("id")), body.Name)
+
+	if err != nil {
+		return handleCategoryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, renamed)
+}
+
+func (h *CategoriesHandler) ReorderCategories(c echo.Context) error {
+	body := new(struct {
+		Ids []category.CategoryName `json:"ids"`
+	})
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	categories, err := h.Application.ReorderCategories(body.Ids)
+
+	if err != nil {
+		return handleCategoryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+func handleCategoryError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	var categoryAlreadyExists *application.CategoryAlreadyExists
+	if errors.As(err, &categoryAlreadyExists) {
+		return c.JSON(http.StatusConflict, struct {
+			Error        string `json:"error"`
+			CategoryName string `json:"categoryName"`
+		}{
+			Error:        categoryAlreadyExists.Error(),
+			CategoryName: categoryAlreadyExists.CategoryName,
+		})
+	}
+
+	var categoryNotFound *application.CategoryNotFound
+	if errors.As(err, &categoryNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error      string                `json:"error"`
+			CategoryId category.CategoryName `json:"categoryId"`
+		}{
+			Error:      categoryNotFound.Error(),
+			CategoryId: categoryNotFound.CategoryId,
+		})
+	}
+
+	return err
 }
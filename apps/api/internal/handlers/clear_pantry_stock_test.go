@@ -0,0 +1,36 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClearingPantryStock(t *testing.T) {
+	p, err := pantry.NewPantry()
+	assert.NoError(t, err)
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+
+	r := pantry.NewFakePantryRepository()
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/pantry/items/rice", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("productId")
+	c.SetParamValues("rice")
+	h := &handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	if assert.NoError(t, h.ClearStock(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"items":[]}`+"\n", rec.Body.String())
+	}
+}
@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompletingCurrentShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	baskets := basket.NewFakeBasketRepository()
+	b, err := basket.NewBasket(1)
+	assert.NoError(t, err)
+	err = baskets.Save(b)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops/current/complete", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{
+		Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), baskets, unitofwork.NewFakeUnitOfWork()),
+		ShoppingList: func() (map[string]shoppinglist.ShoppingListItem, error) {
+			return map[string]shoppinglist.ShoppingListItem{
+				"rice":  {Product: product.Product{Id: "rice"}, IsInBasket: true},
+				"eggs":  {Product: product.Product{Id: "eggs"}, IsInBasket: false},
+				"flour": {Product: product.Product{Id: "flour"}, IsInBasket: true},
+				"salt":  {Product: product.Product{Id: "salt"}, Covered: true},
+				"oil":   {Product: product.Product{Id: "oil"}, IsInBasket: true, Covered: true},
+			}, nil
+		},
+	}
+
+	if assert.NoError(t, h.CompleteCurrentShop(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		s, _ := r.Find(1)
+		assert.True(t, s.IsCompleted())
+		assert.Equal(t, []string{"flour", "oil", "rice"}, s.Completion.Bought)
+		assert.Equal(t, []string{"eggs"}, s.Completion.Abandoned)
+		assert.Equal(t, []string{"salt"}, s.Completion.Covered)
+
+		b, err := baskets.FindByShopId(1)
+		assert.NoError(t, err)
+		assert.True(t, b.Frozen)
+	}
+}
+
+func TestCompletingCurrentShopTwice(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = s.Complete([]string{}, []string{}, []string{})
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops/current/complete", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{
+		Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork()),
+		ShoppingList: func() (map[string]shoppinglist.ShoppingListItem, error) {
+			return map[string]shoppinglist.ShoppingListItem{}, nil
+		},
+	}
+
+	if assert.NoError(t, h.CompleteCurrentShop(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, `{"error":"shop has been completed"}`+"\n", rec.Body.String())
+	}
+}
@@ -2,8 +2,12 @@ package handlers_test
 
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -32,7 +36,7 @@ func TestGettingCurrentShop(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.CurrentShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -53,7 +57,7 @@ func TestGettingCurrentShopWithNoMeals(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.CurrentShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
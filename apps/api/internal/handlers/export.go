@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type ExportHandler struct {
+	Application *application.ExportMealsApplication
+}
+
+// ExportMeals exports every meal, as CSV that can be uploaded again with
+// format=csv, or as JSON with format=json, which also keeps their urls,
+// notes and recipes.
+func (h *ExportHandler) ExportMeals(c echo.Context) error {
+	switch c.QueryParam("format") {
+	case "", "csv":
+		var buf bytes.Buffer
+		if err := h.Application.ExportMealsCsv(&buf); err != nil {
+			return err
+		}
+
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="meals.csv"`)
+
+		return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "json":
+		meals, err := h.Application.ExportMeals()
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="meals.json"`)
+
+		return c.JSON(http.StatusOK, meals)
+	default:
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: "format must be csv or json",
+		})
+	}
+}
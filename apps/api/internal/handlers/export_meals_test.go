@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"bytes"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newExportRepositories(t *testing.T) (*product.EventSourcedProductRepository, *meal.EventSourcedMealRepository) {
+	products := product.NewFakeProductRepository()
+	require.NoError(t, products.Add(product.NewProductBuilder().WithId("rice").WithName("Rice").Build()))
+	require.NoError(t, products.Add(product.NewProductBuilder().WithId("beans").WithName("Black beans").Build()))
+	require.NoError(t, products.Add(product.NewProductBuilder().WithId("cream").WithName("Cream, double").Build()))
+
+	meals := meal.NewFakeMealRepository()
+	require.NoError(t, meals.Save(meal.NewMealBuilder().WithName("Burritos").WithUrl("https://recipes.localhost/burritos").WithServings(4).
+		AddIngredient(*meal.NewIngredient("rice").WithQuantity(quantity.NewAmount(300), quantity.Gram)).
+		AddIngredient(*meal.NewIngredient("beans").WithQuantity(quantity.NewFraction(3, 2), quantity.Tin).WithNote("drained")).
+		WithTags("mexican").
+		Build()))
+	require.NoError(t, meals.Save(meal.NewMealBuilder().WithName("Eton mess").
+		AddIngredient(*meal.NewIngredient("cream").WithQuantity(quantity.NewFraction(1, 3), quantity.Litre)).
+		Build()))
+	require.NoError(t, meals.Save(meal.NewMealBuilder().WithName("Toast").Build()))
+
+	return products, meals
+}
+
+func TestExportingMealsAsCsv(t *testing.T) {
+	products, meals := newExportRepositories(t)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/meals/export?format=csv", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.ExportHandler{Application: application.NewExportMealsApplication(products, meals)}
+
+	require.NoError(t, h.ExportMeals(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	require.Equal(t, "name,product,amount,unit\n"+
+		"Burritos,Rice,300,Gram\n"+
+		"Burritos,Black beans,1.5,Tin\n"+
+		"Eton mess,\"Cream, double\",1/3,Litre\n", rec.Body.String())
+}
+
+func TestExportedCsvCanBeUploaded(t *testing.T) {
+	products, meals := newExportRepositories(t)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/meals/export", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.ExportHandler{Application: application.NewExportMealsApplication(products, meals)}
+	require.NoError(t, h.ExportMeals(c))
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	part, err := w.CreateFormFile("meals", "meals.csv")
+	require.NoError(t, err)
+
+	_, err = part.Write(rec.Body.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req = httptest.NewRequest("POST", "/meals/upload", body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	uploaded := meal.NewFakeMealRepository()
+	upload := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(products, uploaded, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, upload.UploadMeals(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	m, err := uploaded.Get()
+	require.NoError(t, err)
+	require.Len(t, m, 2)
+
+	require.Equal(t, "Burritos", m[0].Name)
+	require.Equal(t, []meal.Ingredient{
+		*meal.NewIngredient("rice").WithQuantity(quantity.NewAmount(300), quantity.Gram),
+		*meal.NewIngredient("beans").WithQuantity(quantity.NewFraction(3, 2), quantity.Tin),
+	}, withoutIngredientIds(m[0].Ingredients))
+
+	require.Equal(t, "Eton mess", m[1].Name)
+	require.Equal(t, []meal.Ingredient{
+		*meal.NewIngredient("cream").WithQuantity(quantity.NewFraction(1, 3), quantity.Litre),
+	}, withoutIngredientIds(m[1].Ingredients))
+}
+
+func TestExportingMealsAsJson(t *testing.T) {
+	products, meals := newExportRepositories(t)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/meals/export?format=json", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.ExportHandler{Application: application.NewExportMealsApplication(products, meals)}
+
+	require.NoError(t, h.ExportMeals(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `[`+
+		`{"name":"Burritos","url":"https://recipes.localhost/burritos","servings":4,"ingredients":[`+
+		`{"product":"Rice","quantity":{"amount":300,"unit":"Gram"}},`+
+		`{"product":"Black beans","quantity":{"amount":1.5,"unit":"Tin"},"note":"drained"}`+
+		`],"tags":["mexican"]},`+
+		`{"name":"Eton mess","url":"","servings":0,"ingredients":[{"product":"Cream, double","quantity":{"amount":"1/3","unit":"Litre"}}]},`+
+		`{"name":"Toast","url":"","servings":0,"ingredients":[]}`+
+		`]`+"\n", rec.Body.String())
+}
+
+func TestExportingMealsInUnknownFormat(t *testing.T) {
+	products, meals := newExportRepositories(t)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/meals/export?format=xml", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.ExportHandler{Application: application.NewExportMealsApplication(products, meals)}
+
+	require.NoError(t, h.ExportMeals(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"format must be csv or json"}`+"\n", rec.Body.String())
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/bus"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type HealthHandler struct {
+	ShoppingList *shoppinglist.Service
+	Feed         *live.Feed
+	Events       *bus.Bus
+}
+
+func (h *HealthHandler) GetHealth(c echo.Context) error {
+	shoppingList := h.ShoppingList.Health()
+	feed := h.Feed.Health()
+	events := h.Events.Health()
+
+	status, code := "ok", http.StatusOK
+	if !shoppingList.Healthy || !feed.Healthy || !events.Healthy {
+		status, code = "unhealthy", http.StatusServiceUnavailable
+	}
+
+	return c.JSON(code, struct {
+		Status       string              `json:"status"`
+		ShoppingList shoppinglist.Health `json:"shoppingList"`
+		Feed         live.Health         `json:"feed"`
+		Events       bus.Health          `json:"events"`
+	}{
+		Status:       status,
+		ShoppingList: shoppingList,
+		Feed:         feed,
+		Events:       events,
+	})
+}
@@ -0,0 +1,173 @@
+package handlers_test
+
+import (
+	"bytes"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/recipe"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const recipePage = `<html><head><script type="application/ld+json">{
+	"@context": "https://schema.org",
+	"@type": "Recipe",
+	"name": "Onion soup",
+	"recipeYield": "Serves 4",
+	"prepTime": "PT10M",
+	"cookTime": "PT1H",
+	"recipeCuisine": "French",
+	"suitableForDiet": "https://schema.org/VegetarianDiet",
+	"recipeIngredient": ["4 large onions, sliced", "50g butter", "1 litre vegetable stock", "Gruyère, to serve"],
+	"recipeInstructions": [{"@type": "HowToStep", "text": "Soften the onions in the butter."}, {"@type": "HowToStep", "text": "Add the stock and simmer."}]
+}</script></head><body></body></html>`
+
+const onionSoupProposal = `{"name":"Onion soup","url":"%s","servings":4,"ingredients":[` +
+	`{"line":"4 large onions, sliced","product":"Onion","productId":"onion","matched":true,"quantity":{"amount":4,"unit":"Number"},"note":"sliced"},` +
+	`{"line":"50g butter","product":"Butter","productId":"butter","matched":true,"quantity":{"amount":50,"unit":"Gram"}},` +
+	`{"line":"1 litre vegetable stock","product":"vegetable stock","matched":false,"quantity":{"amount":1,"unit":"Litre"}},` +
+	`{"line":"Gruyère, to serve","product":"Gruyère","matched":false,"quantity":{"amount":1,"unit":"Number"},"note":"to serve"}` +
+	`],"steps":["Soften the onions in the butter.","Add the stock and simmer."],"prepTime":10,"cookTime":60,"tags":["french","vegetarian"],` +
+	`"notFoundProducts":["vegetable stock","Gruyère"]}` + "\n"
+
+func newRecipeProductRepository(t *testing.T) product.ProductRepository {
+	products := product.NewFakeProductRepository()
+
+	require.NoError(t, products.Add(product.NewProductBuilder().WithId("onion").WithName("Onion").Build()))
+
+	butter := product.NewProductBuilder().WithId("butter").WithName("Butter").Build()
+	require.NoError(t, butter.SetDefaultQuantity(quantity.Quantity{Amount: quantity.NewAmount(25), Unit: quantity.Gram}))
+	require.NoError(t, products.Add(butter))
+
+	return products
+}
+
+func TestImportingUploadedRecipe(t *testing.T) {
+	e := echo.New()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	part, err := w.CreateFormFile("recipe", "soup.html")
+	require.NoError(t, err)
+
+	_, err = part.Write([]byte(recipePage))
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteField("url", "https://recipes.localhost/soup"))
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", "/meals/import", body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.RecipesHandler{Application: application.NewRecipeApplication(newRecipeProductRepository(t), recipe.NewHttpFetcher())}
+
+	require.NoError(t, h.ImportRecipe(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, strings.Replace(onionSoupProposal, "%s", "https://recipes.localhost/soup", 1), rec.Body.String())
+}
+
+func TestImportingRecipeFromUrl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/soup" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte(recipePage))
+	}))
+	defer server.Close()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/import", strings.NewReader(url.Values{"url": {server.URL + "/soup"}}.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.RecipesHandler{Application: application.NewRecipeApplication(newRecipeProductRepository(t), &recipe.HttpFetcher{Client: server.Client(), AllowPrivate: true})}
+
+	require.NoError(t, h.ImportRecipe(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, strings.Replace(onionSoupProposal, "%s", server.URL+"/soup", 1), rec.Body.String())
+}
+
+func TestImportingRecipeFromUnreachableUrl(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/import", strings.NewReader(url.Values{"url": {server.URL + "/soup"}}.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.RecipesHandler{Application: application.NewRecipeApplication(newRecipeProductRepository(t), &recipe.HttpFetcher{Client: server.Client(), AllowPrivate: true})}
+
+	require.NoError(t, h.ImportRecipe(c))
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Equal(t, `{"error":"could not fetch recipe","url":"`+server.URL+`/soup"}`+"\n", rec.Body.String())
+}
+
+func TestImportingPageWithoutRecipe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>Not a recipe</body></html>`))
+	}))
+	defer server.Close()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/import", strings.NewReader(url.Values{"url": {server.URL}}.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.RecipesHandler{Application: application.NewRecipeApplication(newRecipeProductRepository(t), &recipe.HttpFetcher{Client: server.Client(), AllowPrivate: true})}
+
+	require.NoError(t, h.ImportRecipe(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"no recipe found","url":"`+server.URL+`"}`+"\n", rec.Body.String())
+}
+
+func TestImportingRecipeFromPrivateAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(recipePage))
+	}))
+	defer server.Close()
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/import", strings.NewReader(url.Values{"url": {server.URL + "/soup"}}.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.RecipesHandler{Application: application.NewRecipeApplication(newRecipeProductRepository(t), recipe.NewHttpFetcher())}
+
+	require.NoError(t, h.ImportRecipe(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"url must be a public address"}`+"\n", rec.Body.String())
+}
+
+func TestImportingRecipeWithInvalidUrl(t *testing.T) {
+	for _, body := range []string{"", "url=ftp%3A%2F%2Frecipes.localhost%2Fsoup"} {
+		t.Run(body, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest("POST", "/meals/import", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			h := &handlers.RecipesHandler{Application: application.NewRecipeApplication(newRecipeProductRepository(t), recipe.NewHttpFetcher())}
+
+			require.NoError(t, h.ImportRecipe(c))
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
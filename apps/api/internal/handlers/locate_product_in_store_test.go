@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocatingProductInStore(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	s, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Save(s))
+
+	products := product.NewFakeProductRepository()
+	assert.NoError(t, products.Add(product.NewProductBuilder().WithId("bananas").WithName("Bananas").WithCategory(category.Fruit).Build()))
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/stores/corner/locations/bananas", strings.NewReader(`{"category":"Bakery"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "productId")
+	c.SetParamValues("corner", "bananas")
+	h := &handlers.StoresHandler{Application: newStoreApplicationWithProducts(repo, products)}
+
+	if assert.NoError(t, h.LocateProduct(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"corner","name":"Corner Shop","layout":[],"locations":{"bananas":"Bakery"}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestLocatingUnknownProductInStore(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	s, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Save(s))
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/stores/corner/locations/bananas", strings.NewReader(`{"category":"Bakery"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "productId")
+	c.SetParamValues("corner", "bananas")
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.LocateProduct(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"product not found","productId":"bananas"}`+"\n", rec.Body.String())
+	}
+}
+
+func TestClearingProductLocationInStore(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	s, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	s.LocateProduct("bananas", category.Bakery)
+	assert.NoError(t, repo.Save(s))
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/stores/corner/locations/bananas", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "productId")
+	c.SetParamValues("corner", "bananas")
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.ClearProductLocation(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"corner","name":"Corner Shop","layout":[],"locations":{}}`+"\n", rec.Body.String())
+	}
+}
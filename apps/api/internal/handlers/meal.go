@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
@@ -13,15 +14,37 @@ type MealsHandler struct {
 }
 
 func (h *MealsHandler) GetMeals(c echo.Context) error {
-	m, err := h.Application.GetMeals()
+	maxTime, err := intQueryParam(c, "maxTime", 0)
 
 	if err != nil {
-		return err
+		return handleMealsFilterError(c, err)
+	}
+
+	m, err := h.Application.GetMeals(application.MealFilter{
+		Tags:    c.QueryParams()["tag"],
+		MaxTime: maxTime,
+	})
+
+	if err != nil {
+		return handleMealsFilterError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, m)
 }
 
+func handleMealsFilterError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	return err
+}
+
 func (h *MealsHandler) FindMeal(c echo.Context) error {
 	m, err := h.Application.FindMeal(c.Param("id"))
 
@@ -47,6 +70,7 @@ func (h *MealsHandler) AddMeal(c echo.Context) error {
 		body.Id,
 		body.Name,
 		body.Url,
+		body.Servings,
 		ingredients,
 	)
 
@@ -77,26 +101,85 @@ func (h *MealsHandler) AddMeal(c echo.Context) error {
 func (h *MealsHandler) AddIngredientToMeal(c echo.Context) error {
 	mealId := c.Param("mealId")
 
-	ingredient := meal.NewIngredient("")
-	if err := c.Bind(&ingredient); err != nil {
+	body := new(struct {
+		Id       string             `json:"id"`
+		Quantity *quantity.Quantity `json:"quantity"`
+		Note     string             `json:"note"`
+	})
+	if err := c.Bind(body); err != nil {
 		return err
 	}
 
-	m, err := h.Application.AddIngredientToMeal(mealId, *ingredient)
+	m, err := h.Application.AddIngredientToMeal(mealId, body.Id, body.Quantity, body.Note)
 	if err != nil {
-		return err
+		return handleIngredientError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, m)
+}
+
+func (h *MealsHandler) UpdateIngredient(c echo.Context) error {
+	body := new(application.PartialIngredient)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	m, err := h.Application.UpdateIngredient(c.Param("mealId"), c.Param("ingredientId"), *body)
+	if err != nil {
+		return handleIngredientError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, m)
 }
 
+func handleIngredientError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	var mealNotFound *application.MealNotFound
+	if errors.As(err, &mealNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error  string `json:"error"`
+			MealId string `json:"mealId"`
+		}{
+			Error:  mealNotFound.Error(),
+			MealId: mealNotFound.MealId,
+		})
+	}
+
+	var ingredientNotInMeal *application.IngredientNotInMeal
+	if errors.As(err, &ingredientNotInMeal) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error        string `json:"error"`
+			IngredientId string `json:"ingredientId"`
+		}{
+			Error:        ingredientNotInMeal.Error(),
+			IngredientId: ingredientNotInMeal.IngredientId,
+		})
+	}
+
+	return err
+}
+
 func (h *MealsHandler) RemoveIngredientFromMeal(c echo.Context) error {
 	mealId := c.Param("mealId")
 	ingredientId := c.Param("ingredientId")
 
 	m, err := h.Application.RemoveIngredientFromMeal(mealId, ingredientId)
 	if err != nil {
-		return err
+		return handleIngredientError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, m)
@@ -112,8 +195,38 @@ func (h *MealsHandler) UpdateMeal(c echo.Context) error {
 
 	m, err := h.Application.UpdateMeal(mealId, *body)
 	if err != nil {
+		var validationError *application.ValidationError
+		if errors.As(err, &validationError) {
+			return c.JSON(http.StatusBadRequest, struct {
+				Error string `json:"error"`
+			}{
+				Error: validationError.Error(),
+			})
+		}
+
 		return errors.New("error updating meal: " + err.Error())
 	}
 
 	return c.JSON(http.StatusOK, m)
 }
+
+func (h *MealsHandler) ArchiveMeal(c echo.Context) error {
+	m, err := h.Application.ArchiveMeal(c.Param("id"))
+
+	if err != nil {
+		var mealNotFound *application.MealNotFound
+		if errors.As(err, &mealNotFound) {
+			return c.JSON(http.StatusNotFound, struct {
+				Error  string `json:"error"`
+				MealId string `json:"mealId"`
+			}{
+				Error:  mealNotFound.Error(),
+				MealId: mealNotFound.MealId,
+			})
+		}
+
+		return errors.New("error archiving meal: " + err.Error())
+	}
+
+	return c.JSON(http.StatusOK, m)
+}
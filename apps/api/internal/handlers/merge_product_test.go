@@ -0,0 +1,185 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mergeRepositories struct {
+	products *product.EventSourcedProductRepository
+	meals    *meal.EventSourcedMealRepository
+	shops    *shop.ShopRepository
+	baskets  *basket.BasketRepository
+	pantry   *pantry.PantryRepository
+}
+
+func createMergeRepositories(t *testing.T) mergeRepositories {
+	r := mergeRepositories{
+		products: product.NewFakeProductRepository(),
+		meals:    meal.NewFakeMealRepository(),
+		shops:    shop.NewFakeShopRepository(),
+		baskets:  basket.NewFakeBasketRepository(),
+		pantry:   pantry.NewFakePantryRepository(),
+	}
+
+	assert.NoError(t, r.products.Add(product.NewProductBuilder().WithId("tomato").WithName("Tomato").WithCategory(category.Vegetables).Build()))
+	assert.NoError(t, r.products.Add(product.NewProductBuilder().WithId("tomatoes").WithName("Tomatoes").WithCategory(category.Vegetables).Build()))
+
+	return r
+}
+
+func (r mergeRepositories) handler() *handlers.ProductHandler {
+	return &handlers.ProductHandler{
+		Application: application.NewProductApplication(r.products, category.NewFakeCategoryRepository()),
+		Merge:       application.NewMergeProductsApplication(r.products, r.meals, r.shops, r.baskets, r.pantry, unitofwork.NewFakeUnitOfWork()),
+	}
+}
+
+func mergeRequest(id string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/products/"+id+"/merge", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+
+	return c, rec
+}
+
+func TestMergingProduct(t *testing.T) {
+	r := createMergeRepositories(t)
+
+	grams := func(n int64) quantity.Quantity {
+		return quantity.Quantity{Amount: quantity.NewAmount(n), Unit: quantity.Gram}
+	}
+
+	onlyDuplicate := meal.NewMealBuilder().WithId("salad").WithName("Salad").AddIngredient(meal.Ingredient{ProductId: "tomato", Id: "salad-tomato", Quantity: grams(100)}).Build()
+	both := meal.NewMealBuilder().WithId("sauce").WithName("Sauce").AddIngredients([]meal.Ingredient{
+		{ProductId: "tomato", Id: "sauce-tomato", Quantity: grams(100)},
+		{ProductId: "tomatoes", Id: "sauce-tomatoes", Quantity: grams(400)},
+	}).Build()
+	assert.NoError(t, r.meals.Save(onlyDuplicate))
+	assert.NoError(t, r.meals.Save(both))
+
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	assert.NoError(t, s.AddItem(&shop.Item{ProductId: "tomato", Quantity: grams(50)}))
+	assert.NoError(t, r.shops.Save(s))
+
+	b, err := basket.NewBasket(1)
+	assert.NoError(t, err)
+	assert.NoError(t, b.AddItem(basket.NewBasketItem("tomato")))
+	assert.NoError(t, r.baskets.Save(b))
+
+	stock, err := r.pantry.Get()
+	assert.NoError(t, err)
+	stock.SetStock("tomato", grams(200))
+	stock.SetStock("tomatoes", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+	assert.NoError(t, r.pantry.Save(stock))
+
+	c, rec := mergeRequest("tomato", `{"into": "tomatoes"}`)
+
+	if assert.NoError(t, r.handler().MergeProduct(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "{\"id\":\"tomato\",\"name\":\"Tomato\",\"category\":\"Vegetables\",\"archived\":true,\"mergedInto\":\"tomatoes\"}\n", rec.Body.String())
+
+		m, err := r.meals.Find("salad")
+		assert.NoError(t, err)
+		assert.Equal(t, []meal.Ingredient{{ProductId: "tomatoes", Id: "salad-tomato", Quantity: grams(100)}}, m.Ingredients)
+
+		m, err = r.meals.Find("sauce")
+		assert.NoError(t, err)
+		assert.Equal(t, []meal.Ingredient{{ProductId: "tomatoes", Id: "sauce-tomatoes", Quantity: grams(500)}}, m.Ingredients)
+
+		s, err := r.shops.Find(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []*shop.Item{{ProductId: "tomatoes", Quantity: grams(50)}}, s.Items)
+
+		b, err := r.baskets.FindByShopId(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []*basket.BasketItem{{IngredientId: "tomatoes"}}, b.Items)
+
+		stock, err := r.pantry.Get()
+		assert.NoError(t, err)
+		assert.Nil(t, stock.Stock("tomato"))
+		assert.Equal(t, &quantity.Quantity{Amount: quantity.NewFraction(6, 5), Unit: quantity.Kg}, stock.Stock("tomatoes"))
+
+		ps, err := r.products.Get()
+		assert.NoError(t, err)
+		assert.Len(t, ps, 1)
+		assert.Equal(t, "tomatoes", ps[0].Id)
+	}
+}
+
+func TestMergingPantryStockInIncompatibleUnits(t *testing.T) {
+	r := createMergeRepositories(t)
+
+	stock, err := r.pantry.Get()
+	assert.NoError(t, err)
+	stock.SetStock("tomato", quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Number})
+	stock.SetStock("tomatoes", quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg})
+	assert.NoError(t, r.pantry.Save(stock))
+
+	c, rec := mergeRequest("tomato", `{"into": "tomatoes"}`)
+
+	if assert.NoError(t, r.handler().MergeProduct(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		stock, err := r.pantry.Get()
+		assert.NoError(t, err)
+		assert.Nil(t, stock.Stock("tomato"))
+		assert.Equal(t, &quantity.Quantity{Amount: quantity.NewAmount(1), Unit: quantity.Kg}, stock.Stock("tomatoes"))
+	}
+}
+
+func TestMergingProductIntoItself(t *testing.T) {
+	r := createMergeRepositories(t)
+
+	c, rec := mergeRequest("tomato", `{"into": "tomato"}`)
+
+	if assert.NoError(t, r.handler().MergeProduct(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "{\"error\":\"cannot merge a product into itself\"}\n", rec.Body.String())
+	}
+}
+
+func TestMergingProductIntoArchivedProduct(t *testing.T) {
+	r := createMergeRepositories(t)
+
+	p, err := r.products.Find("tomatoes")
+	assert.NoError(t, err)
+	p.Archive()
+	assert.NoError(t, r.products.Save(p))
+
+	c, rec := mergeRequest("tomato", `{"into": "tomatoes"}`)
+
+	if assert.NoError(t, r.handler().MergeProduct(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+}
+
+func TestMergingProductIntoProductThatDoesNotExist(t *testing.T) {
+	r := createMergeRepositories(t)
+
+	c, rec := mergeRequest("tomato", `{"into": "potato"}`)
+
+	if assert.NoError(t, r.handler().MergeProduct(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "{\"error\":\"product not found\",\"productId\":\"potato\"}\n", rec.Body.String())
+	}
+}
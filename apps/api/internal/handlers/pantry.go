@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type PantryHandler struct {
+	Application *application.PantryApplication
+}
+
+func (h *PantryHandler) GetPantry(c echo.Context) error {
+	p, err := h.Application.GetPantry()
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *PantryHandler) SetStock(c echo.Context) error {
+	q := new(quantity.Quantity)
+	if err := c.Bind(q); err != nil {
+		return err
+	}
+
+	p, err := h.Application.SetStock(c.Param("productId"), *q)
+
+	if err != nil {
+		return handleStockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *PantryHandler) AdjustStock(c echo.Context) error {
+	q := new(quantity.Quantity)
+	if err := c.Bind(q); err != nil {
+		return err
+	}
+
+	p, err := h.Application.AdjustStock(c.Param("productId"), *q)
+
+	if err != nil {
+		return handleStockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *PantryHandler) ClearStock(c echo.Context) error {
+	p, err := h.Application.ClearStock(c.Param("productId"))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+func handleStockError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	return err
+}
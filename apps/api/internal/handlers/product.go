@@ -13,6 +13,7 @@ import (
 
 type ProductHandler struct {
 	Application *application.ProductApplication
+	Merge       *application.MergeProductsApplication
 	EventStore  *sqlStore.SQLite
 }
 
@@ -78,3 +79,102 @@ func (h *ProductHandler) AddProduct(c echo.Context) error {
 
 	return c.JSON(http.StatusAccepted, p)
 }
+
+func (h *ProductHandler) UpdateProduct(c echo.Context) error {
+	body := new(application.PartialProduct)
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	p, err := h.Application.UpdateProduct(c.Param("id"), *body)
+
+	if err != nil {
+		return handleProductError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *ProductHandler) ArchiveProduct(c echo.Context) error {
+	p, err := h.Application.ArchiveProduct(c.Param("id"))
+
+	if err != nil {
+		return handleProductError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *ProductHandler) MergeProduct(c echo.Context) error {
+	body := new(struct {
+		Into string `json:"into"`
+	})
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	p, err := h.Merge.MergeProduct(c.Param("id"), body.Into)
+
+	if err != nil {
+		return handleProductError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+func handleProductError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	var productAlreadyExists *application.ProductAlreadyExists
+	if errors.As(err, &productAlreadyExists) {
+		return c.JSON(http.StatusConflict, struct {
+			Error       string `json:"error"`
+			ProductName string `json:"productName"`
+		}{
+			Error:       productAlreadyExists.Error(),
+			ProductName: productAlreadyExists.ProductName,
+		})
+	}
+
+	var productNotFound *application.ProductNotFound
+	if errors.As(err, &productNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error     string `json:"error"`
+			ProductId string `json:"productId"`
+		}{
+			Error:     productNotFound.Error(),
+			ProductId: productNotFound.ProductId,
+		})
+	}
+
+	if errors.Is(err, product.ErrProductArchived) {
+		return c.JSON(http.StatusConflict, struct {
+			Error string `json:"error"`
+		}{
+			Error: err.Error(),
+		})
+	}
+
+	return err
+}
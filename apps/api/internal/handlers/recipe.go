@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type RecipesHandler struct {
+	Application *application.RecipeApplication
+}
+
+// ImportRecipe proposes a meal from a recipe page, either uploaded as the
+// "recipe" file or fetched from "url".
+func (h *RecipesHandler) ImportRecipe(c echo.Context) error {
+	url := c.FormValue("url")
+
+	file, err := c.FormFile("recipe")
+
+	if err != nil && url == "" {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: "recipe file or url is required",
+		})
+	}
+
+	var proposal *application.MealProposal
+
+	if err == nil {
+		src, err := file.Open()
+		if err != nil {
+			return err
+		}
+
+		defer src.Close()
+
+		proposal, err = h.Application.ImportRecipe(src, url)
+		if err != nil {
+			return handleRecipeError(c, err)
+		}
+	} else {
+		proposal, err = h.Application.ImportRecipeFromUrl(url)
+		if err != nil {
+			return handleRecipeError(c, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, proposal)
+}
+
+func handleRecipeError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	var recipeNotFound *application.RecipeNotFound
+	if errors.As(err, &recipeNotFound) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+			Url   string `json:"url"`
+		}{
+			Error: recipeNotFound.Error(),
+			Url:   recipeNotFound.Url,
+		})
+	}
+
+	var recipeNotFetched *application.RecipeNotFetched
+	if errors.As(err, &recipeNotFetched) {
+		return c.JSON(http.StatusBadGateway, struct {
+			Error string `json:"error"`
+			Url   string `json:"url"`
+		}{
+			Error: recipeNotFetched.Error(),
+			Url:   recipeNotFetched.Url,
+		})
+	}
+
+	return err
+}
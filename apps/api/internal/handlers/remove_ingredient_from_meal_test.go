@@ -1,9 +1,12 @@
 package handlers_test
 
 import (
+	"github.com/hallgren/eventsourcing"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -16,8 +19,8 @@ func TestRemovingIngredientFromMeal(t *testing.T) {
 
 	err := repo.Save(meal.NewMealBuilder().
 		WithId("123").
-		AddIngredient(meal.Ingredient{ProductId: "ing-1"}).
-		AddIngredient(meal.Ingredient{ProductId: "ing-2"}).
+		AddIngredient(meal.Ingredient{ProductId: "ing-1", Id: "ing-1"}).
+		AddIngredient(meal.Ingredient{ProductId: "ing-2", Id: "ing-2"}).
 		Build())
 
 	assert.NoError(t, err)
@@ -29,14 +32,14 @@ func TestRemovingIngredientFromMeal(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId", "ingredientId")
 	c.SetParamValues("123", "ing-1")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.RemoveIngredientFromMeal(c)) {
 		m, err := repo.Find("123")
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Len(t, m.Ingredients, 1)
-		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-2"}}, m.Ingredients)
+		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-2", Id: "ing-2"}}, m.Ingredients)
 	}
 }
 
@@ -45,7 +48,7 @@ func TestRemovingAllIngredientsFromMeal(t *testing.T) {
 
 	err := repo.Save(meal.NewMealBuilder().
 		WithId("123").
-		AddIngredient(meal.Ingredient{ProductId: "ing-1"}).
+		AddIngredient(meal.Ingredient{ProductId: "ing-1", Id: "ing-1"}).
 		Build())
 
 	assert.NoError(t, err)
@@ -57,7 +60,7 @@ func TestRemovingAllIngredientsFromMeal(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId", "ingredientId")
 	c.SetParamValues("123", "ing-1")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.RemoveIngredientFromMeal(c)) {
 		m, err := repo.Find("123")
@@ -67,3 +70,32 @@ func TestRemovingAllIngredientsFromMeal(t *testing.T) {
 		assert.Equal(t, []meal.Ingredient{}, m.Ingredients)
 	}
 }
+
+func TestRemovingUnknownIngredientFromMeal(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().
+		WithId("123").
+		AddIngredient(meal.Ingredient{ProductId: "ing-1", Id: "ing-1"}).
+		Build())
+
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/meals/123/ingredients/missing", nil)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId", "ingredientId")
+	c.SetParamValues("123", "missing")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.RemoveIngredientFromMeal(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"ingredient not in meal","ingredientId":"missing"}`+"\n", rec.Body.String())
+
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Equal(t, eventsourcing.Version(1), m.Version())
+	}
+}
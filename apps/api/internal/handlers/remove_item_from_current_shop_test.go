@@ -2,9 +2,13 @@ package handlers_test
 
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -19,13 +23,13 @@ func TestRemovingItemFromCurrentShop(t *testing.T) {
 	s.AddItem(
 		&shop.Item{
 			ProductId: "abc",
-			Quantity:  quantity.Quantity{Amount: 3, Unit: quantity.Cup},
+			Quantity:  quantity.Quantity{Amount: quantity.NewAmount(3), Unit: quantity.Cup},
 		},
 	)
 	s.AddItem(
 		&shop.Item{
 			ProductId: "xyz",
-			Quantity:  quantity.Quantity{Amount: 2, Unit: quantity.Gram},
+			Quantity:  quantity.Quantity{Amount: quantity.NewAmount(2), Unit: quantity.Gram},
 		},
 	)
 
@@ -41,7 +45,7 @@ func TestRemovingItemFromCurrentShop(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("productId")
 	c.SetParamValues("abc")
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.RemoveItemFromCurrentShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -49,7 +53,7 @@ func TestRemovingItemFromCurrentShop(t *testing.T) {
 		assert.Equal(t, []*shop.Item{
 			{
 				ProductId: "xyz",
-				Quantity:  quantity.Quantity{Amount: 2, Unit: quantity.Gram},
+				Quantity:  quantity.Quantity{Amount: quantity.NewAmount(2), Unit: quantity.Gram},
 			},
 		}, s.Items)
 	}
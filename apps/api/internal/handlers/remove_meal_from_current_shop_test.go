@@ -2,8 +2,12 @@ package handlers_test
 
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -15,7 +19,8 @@ func TestRemovingMealFromCurrentShop(t *testing.T) {
 	s, err := shop.NewShop(1)
 	assert.NoError(t, err)
 
-	s.AddMeal(&shop.ShopMeal{MealId: "abc"}).AddMeal(&shop.ShopMeal{MealId: "def"})
+	s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+	s.AddMeal(&shop.ShopMeal{MealId: "def"})
 
 	r := shop.NewFakeShopRepository()
 	err = r.Save(s)
@@ -29,7 +34,7 @@ func TestRemovingMealFromCurrentShop(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("abc")
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.RemoveMealFromCurrentShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -56,7 +61,7 @@ func TestRemovingAllMealsFromCurrentShop(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("abc")
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.RemoveMealFromCurrentShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenamingCategory(t *testing.T) {
+	repo := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/categories/FishAndSeafood", strings.NewReader(`{"name":"Fish and Seafood"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("FishAndSeafood")
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(repo)}
+
+	if assert.NoError(t, h.RenameCategory(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"FishAndSeafood","name":"Fish and Seafood"}`+"\n", rec.Body.String())
+
+		categories, err := repo.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, "Fish and Seafood", categories.Find(category.FishAndSeafood).Name)
+	}
+}
+
+func TestRenamingCategoryThatDoesNotExist(t *testing.T) {
+	repo := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/categories/nope", strings.NewReader(`{"name":"Nope"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("nope")
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(repo)}
+
+	if assert.NoError(t, h.RenameCategory(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"category not found","categoryId":"nope"}`+"\n", rec.Body.String())
+	}
+}
@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReorderingCategories(t *testing.T) {
+	repo := category.NewFakeCategoryRepository()
+
+	order := slices.Clone(category.Defaults)
+	slices.Reverse(order)
+
+	body, err := json.Marshal(map[string]any{"ids": order})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/categories/order", strings.NewReader(string(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(repo)}
+
+	if assert.NoError(t, h.ReorderCategories(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		categories, err := repo.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, categories.Position(category.Eggs))
+		assert.Equal(t, len(order)-1, categories.Position(category.Fruit))
+	}
+}
+
+func TestReorderingCategoriesWithMissingCategory(t *testing.T) {
+	repo := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/categories/order", strings.NewReader(`{"ids":["Eggs","Fruit"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(repo)}
+
+	if assert.NoError(t, h.ReorderCategories(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"order must include every category exactly once"}`+"\n", rec.Body.String())
+
+		categories, err := repo.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, categories.Position(category.Fruit))
+	}
+}
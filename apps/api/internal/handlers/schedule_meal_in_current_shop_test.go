@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSchedulingMealInCurrentShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/shops/current/meals/abc/schedule", strings.NewReader(`{"date":"2026-10-13","slot":"Dinner"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("abc")
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.ScheduleMealInCurrentShop(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":1,"meals":[{"id":"abc","schedule":{"date":"2026-10-13","slot":"Dinner"}}],"items":[]}`+"\n", rec.Body.String())
+		s, _ := r.Find(1)
+		assert.Equal(t, []*shop.ShopMeal{{MealId: "abc", Schedule: &shop.Schedule{Date: "2026-10-13", Slot: shop.Dinner}}}, s.Meals)
+	}
+}
+
+func TestMovingScheduledMealInCurrentShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	s.AddMeal(&shop.ShopMeal{MealId: "abc", Schedule: &shop.Schedule{Date: "2026-10-13", Slot: shop.Dinner}})
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/shops/current/meals/abc/schedule", strings.NewReader(`{"date":"2026-10-16","slot":"Lunch"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("abc")
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.ScheduleMealInCurrentShop(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		s, _ := r.Find(1)
+		assert.Equal(t, []*shop.ShopMeal{{MealId: "abc", Schedule: &shop.Schedule{Date: "2026-10-16", Slot: shop.Lunch}}}, s.Meals)
+	}
+}
+
+func TestSchedulingMealNotInCurrentShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/shops/current/meals/abc/schedule", strings.NewReader(`{"date":"2026-10-13","slot":"Dinner"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("abc")
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.ScheduleMealInCurrentShop(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"meal not in shop","mealId":"abc"}`+"\n", rec.Body.String())
+	}
+}
+
+func TestSchedulingMealWithInvalidDate(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/shops/current/meals/abc/schedule", strings.NewReader(`{"date":"13/10/2026","slot":"Dinner"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("abc")
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.ScheduleMealInCurrentShop(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		s, _ := r.Find(1)
+		assert.Nil(t, s.Meals[0].Schedule)
+	}
+}
+
+func TestSchedulingMealWithoutSlot(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/shops/current/meals/abc/schedule", strings.NewReader(`{"date":"2026-10-13"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("abc")
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.ScheduleMealInCurrentShop(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"slot must be Breakfast, Lunch or Dinner"}`+"\n", rec.Body.String())
+		s, _ := r.Find(1)
+		assert.Nil(t, s.Meals[0].Schedule)
+	}
+}
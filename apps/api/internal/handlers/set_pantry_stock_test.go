@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSettingPantryStock(t *testing.T) {
+	r := pantry.NewFakePantryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/pantry/items/rice", strings.NewReader(`{"amount": 2, "unit": "Kg"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("productId")
+	c.SetParamValues("rice")
+	h := &handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	if assert.NoError(t, h.SetStock(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"items":[{"productId":"rice","quantity":{"amount":2,"unit":"Kg"}}]}`+"\n", rec.Body.String())
+		p, _ := r.Get()
+		assert.Equal(t, &quantity.Quantity{Amount: quantity.NewAmount(2), Unit: quantity.Kg}, p.Stock("rice"))
+	}
+}
+
+func TestSettingNegativePantryStock(t *testing.T) {
+	r := pantry.NewFakePantryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("PUT", "/pantry/items/rice", strings.NewReader(`{"amount": -2, "unit": "Kg"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("productId")
+	c.SetParamValues("rice")
+	h := &handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	if assert.NoError(t, h.SetStock(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"stock cannot be negative"}`+"\n", rec.Body.String())
+	}
+}
@@ -1,14 +1,131 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type ShopsHandler struct {
-	Application *application.ShopApplication
+	Application      *application.ShopApplication
+	ShoppingList     func() (map[string]shoppinglist.ShoppingListItem, error)
+	ShopShoppingList func(shopId int) (shoppinglist.ShoppingListProjectionOutput, error)
+	Feed             *live.Feed
+	Categories       *application.CategoryApplication
+	Stores           *application.StoreApplication
+}
+
+func (h *ShopsHandler) GetShops(c echo.Context) error {
+	page, err := intQueryParam(c, "page", 1)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	pageSize, err := intQueryParam(c, "pageSize", 20)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	shops, err := h.Application.GetShops(page, pageSize)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, shops)
+}
+
+func (h *ShopsHandler) GetShop(c echo.Context) error {
+	id, err := shopIdParam(c)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	s, err := h.Application.GetShop(id)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *ShopsHandler) GetShopShoppingList(c echo.Context) error {
+	id, err := shopIdParam(c)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	s, err := h.Application.GetShop(id)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	output, err := h.ShopShoppingList(id)
+
+	if err != nil {
+		return err
+	}
+
+	if c.QueryParam("grouped") == "true" {
+		categories, err := h.Categories.GetAisles()
+
+		if err != nil {
+			return err
+		}
+
+		shoppingList := shoppinglist.ShoppingList{ShopId: id, ShoppingList: *output.ShoppingList}
+
+		if s.StoreId == "" {
+			return c.JSON(http.StatusOK, shoppingList.Group(categories))
+		}
+
+		st, err := h.Stores.GetStore(s.StoreId)
+
+		if err != nil {
+			return handleStoreError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, shoppingList.GroupAtStore(categories, st))
+	}
+
+	return c.JSON(http.StatusOK, output)
+}
+
+func shopIdParam(c echo.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return 0, &application.ValidationError{Field: "id", Message: "id must be a number"}
+	}
+
+	return id, nil
+}
+
+func intQueryParam(c echo.Context, name string, fallback int) (int, error) {
+	if c.QueryParam(name) == "" {
+		return fallback, nil
+	}
+
+	v, err := strconv.Atoi(c.QueryParam(name))
+
+	if err != nil {
+		return 0, &application.ValidationError{Field: name, Message: name + " must be a number"}
+	}
+
+	return v, nil
 }
 
 func (h *ShopsHandler) CurrentShop(c echo.Context) error {
@@ -21,13 +138,65 @@ func (h *ShopsHandler) CurrentShop(c echo.Context) error {
 	return c.JSON(http.StatusOK, s)
 }
 
-func (h *ShopsHandler) StartShop(c echo.Context) error {
-	s, err := h.Application.StartShop()
+func (h *ShopsHandler) StreamCurrentShop(c echo.Context) error {
+	s, err := h.Application.GetCurrentShop()
 
 	if err != nil {
 		return err
 	}
 
+	return stream(c, h.Feed, streamEvent{Name: "shop", Data: s}, changedJSON("shop", s, func() (any, error) {
+		return h.Application.GetCurrentShop()
+	}))
+}
+
+func (h *ShopsHandler) CurrentShopWeek(c echo.Context) error {
+	date := time.Now()
+
+	if c.QueryParam("date") != "" {
+		d, err := shop.ParseDate(c.QueryParam("date"))
+
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, struct {
+				Error string `json:"error"`
+			}{
+				Error: err.Error(),
+			})
+		}
+
+		date = d
+	}
+
+	w, err := h.Application.GetCurrentShopWeek(date)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, w)
+}
+
+func (h *ShopsHandler) StartShop(c echo.Context) error {
+	body := new(struct {
+		StoreId string `json:"storeId"`
+	})
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	s, err := h.Application.StartShop(body.StoreId)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
 	return c.JSON(http.StatusOK, s)
 }
 
@@ -40,7 +209,7 @@ func (h *ShopsHandler) AddMealToCurrentShop(c echo.Context) error {
 	s, err := h.Application.AddMealToCurrentShop(shopMeal)
 
 	if err != nil {
-		return err
+		return handleShopError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, s)
@@ -50,12 +219,102 @@ func (h *ShopsHandler) RemoveMealFromCurrentShop(c echo.Context) error {
 	s, err := h.Application.RemoveMealFromCurrentShop(c.Param("mealId"))
 
 	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *ShopsHandler) ScheduleMealInCurrentShop(c echo.Context) error {
+	schedule := new(shop.Schedule)
+	if err := c.Bind(schedule); err != nil {
 		return err
 	}
 
+	s, err := h.Application.ScheduleMealInCurrentShop(c.Param("mealId"), *schedule)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *ShopsHandler) UnscheduleMealInCurrentShop(c echo.Context) error {
+	s, err := h.Application.UnscheduleMealInCurrentShop(c.Param("mealId"))
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
 	return c.JSON(http.StatusOK, s)
 }
 
+func handleShopError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	var mealNotInShop *application.MealNotInShop
+	if errors.As(err, &mealNotInShop) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error  string `json:"error"`
+			MealId string `json:"mealId"`
+		}{
+			Error:  mealNotInShop.Error(),
+			MealId: mealNotInShop.MealId,
+		})
+	}
+
+	var mealNotFound *application.MealNotFound
+	if errors.As(err, &mealNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error  string `json:"error"`
+			MealId string `json:"mealId"`
+		}{
+			Error:  mealNotFound.Error(),
+			MealId: mealNotFound.MealId,
+		})
+	}
+
+	var shopNotFound *application.ShopNotFound
+	if errors.As(err, &shopNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error  string `json:"error"`
+			ShopId int    `json:"shopId"`
+		}{
+			Error:  shopNotFound.Error(),
+			ShopId: shopNotFound.ShopId,
+		})
+	}
+
+	var storeNotFound *application.StoreNotFound
+	if errors.As(err, &storeNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error   string `json:"error"`
+			StoreId string `json:"storeId"`
+		}{
+			Error:   storeNotFound.Error(),
+			StoreId: storeNotFound.StoreId,
+		})
+	}
+
+	if errors.Is(err, shop.ErrShopCompleted) || errors.Is(err, meal.ErrMealArchived) {
+		return c.JSON(http.StatusConflict, struct {
+			Error string `json:"error"`
+		}{
+			Error: err.Error(),
+		})
+	}
+
+	return err
+}
+
 func (h *ShopsHandler) AddItemToCurrentShop(c echo.Context) error {
 	item := new(shop.Item)
 	if err := c.Bind(item); err != nil {
@@ -65,7 +324,7 @@ func (h *ShopsHandler) AddItemToCurrentShop(c echo.Context) error {
 	s, err := h.Application.AddItemToCurrentShop(item)
 
 	if err != nil {
-		return err
+		return handleShopError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, s)
@@ -74,9 +333,25 @@ func (h *ShopsHandler) AddItemToCurrentShop(c echo.Context) error {
 func (h *ShopsHandler) RemoveItemFromCurrentShop(c echo.Context) error {
 	s, err := h.Application.RemoveItemFromCurrentShop(c.Param("productId"))
 
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *ShopsHandler) CompleteCurrentShop(c echo.Context) error {
+	shoppingList, err := h.ShoppingList()
+
 	if err != nil {
 		return err
 	}
 
+	s, err := h.Application.CompleteCurrentShop(shoppingList)
+
+	if err != nil {
+		return handleShopError(c, err)
+	}
+
 	return c.JSON(http.StatusOK, s)
 }
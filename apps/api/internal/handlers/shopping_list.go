@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/labstack/echo/v4"
+	"log/slog"
+	"net/http"
+)
+
+var errShoppingListUnavailable = errors.New("shopping list unavailable")
+
+type ShoppingListHandler struct {
+	Service    *shoppinglist.Service
+	Feed       *live.Feed
+	Categories *application.CategoryApplication
+	Stores     *application.StoreApplication
+}
+
+func (h *ShoppingListHandler) GetShoppingList(c echo.Context) error {
+	shoppingList, err := h.current()
+
+	if err != nil {
+		return handleShoppingListError(c, err)
+	}
+
+	if c.QueryParam("grouped") == "true" {
+		categories, err := h.Categories.GetAisles()
+
+		if err != nil {
+			return err
+		}
+
+		s, err := h.Stores.GetShopStore(shoppingList.ShopId)
+
+		if err != nil {
+			return handleShoppingListError(c, err)
+		}
+
+		if s != nil {
+			return c.JSON(http.StatusOK, shoppingList.GroupAtStore(categories, s))
+		}
+
+		return c.JSON(http.StatusOK, shoppingList.Group(categories))
+	}
+
+	return c.JSON(http.StatusOK, shoppingList)
+}
+
+// StreamShoppingList sends the whole list when the client connects, followed
+// by a diff each time it changes.
+func (h *ShoppingListHandler) StreamShoppingList(c echo.Context) error {
+	last, err := h.current()
+
+	if err != nil {
+		return handleShoppingListError(c, err)
+	}
+
+	return stream(c, h.Feed, streamEvent{Name: "shoppingList", Data: last}, func() (*streamEvent, error) {
+		next, err := h.current()
+
+		if err != nil {
+			return nil, err
+		}
+
+		diff, changed := last.Diff(next)
+		last = next
+
+		if !changed {
+			return nil, nil
+		}
+
+		return &streamEvent{Name: "diff", Data: diff}, nil
+	})
+}
+
+func (h *ShoppingListHandler) current() (shoppinglist.ShoppingList, error) {
+	shoppingList, err := h.Service.Current()
+
+	if err != nil {
+		slog.Error("Building shopping list failed", "error", err)
+		return shoppinglist.ShoppingList{}, errShoppingListUnavailable
+	}
+
+	return shoppingList, nil
+}
+
+func handleShoppingListError(c echo.Context, err error) error {
+	return c.JSON(http.StatusInternalServerError, struct {
+		Error string `json:"error"`
+	}{
+		Error: err.Error(),
+	})
+}
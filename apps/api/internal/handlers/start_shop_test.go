@@ -2,12 +2,17 @@ package handlers_test
 
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -24,7 +29,7 @@ func TestStartingShop(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, func(string) {})}
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.StartShop(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -33,3 +38,37 @@ func TestStartingShop(t *testing.T) {
 		assert.Equal(t, c.Id, 2)
 	}
 }
+
+func TestStartingShopAtStore(t *testing.T) {
+	r := shop.NewFakeShopRepository()
+	stores := store.NewFakeStoreRepository()
+	corner, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, stores.Save(corner))
+
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops", strings.NewReader(`{"storeId":"corner"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), stores, basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.StartShop(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":1,"storeId":"corner","meals":[],"items":[]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestStartingShopAtUnknownStore(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/shops", strings.NewReader(`{"storeId":"corner"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(shop.NewFakeShopRepository(), meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.StartShop(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"store not found","storeId":"corner"}`+"\n", rec.Body.String())
+	}
+}
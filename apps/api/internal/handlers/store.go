@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StoresHandler struct {
+	Application *application.StoreApplication
+}
+
+func (h *StoresHandler) GetStores(c echo.Context) error {
+	stores, err := h.Application.GetStores()
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, stores)
+}
+
+func (h *StoresHandler) GetStore(c echo.Context) error {
+	s, err := h.Application.GetStore(c.Param("id"))
+
+	if err != nil {
+		return handleStoreError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *StoresHandler) AddStore(c echo.Context) error {
+	body := new(struct {
+		Id     string                  `json:"id"`
+		Name   string                  `json:"name"`
+		Layout []category.CategoryName `json:"layout"`
+	})
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	added, err := h.Application.AddStore(body.Id, body.Name, body.Layout)
+
+	if err != nil {
+		return handleStoreError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, added)
+}
+
+func (h *StoresHandler) UpdateStore(c echo.Context) error {
+	body := new(application.PartialStore)
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	updated, err := h.Application.UpdateStore(c.Param("id"), *body)
+
+	if err != nil {
+		return handleStoreError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+func (h *StoresHandler) LocateProduct(c echo.Context) error {
+	body := new(struct {
+		Category category.CategoryName `json:"category"`
+	})
+
+	if err := c.Bind(body); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			struct {
+				Error string `json:"error"`
+			}{
+				Error: "Invalid request body: " + err.Error(),
+			})
+	}
+
+	s, err := h.Application.LocateProduct(c.Param("id"), c.Param("productId"), body.Category)
+
+	if err != nil {
+		return handleStoreError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *StoresHandler) ClearProductLocation(c echo.Context) error {
+	s, err := h.Application.ClearProductLocation(c.Param("id"), c.Param("productId"))
+
+	if err != nil {
+		return handleStoreError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func handleStoreError(c echo.Context, err error) error {
+	var validationError *application.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: validationError.Error(),
+		})
+	}
+
+	var storeAlreadyExists *application.StoreAlreadyExists
+	if errors.As(err, &storeAlreadyExists) {
+		return c.JSON(http.StatusConflict, struct {
+			Error     string `json:"error"`
+			StoreName string `json:"storeName"`
+		}{
+			Error:     storeAlreadyExists.Error(),
+			StoreName: storeAlreadyExists.StoreName,
+		})
+	}
+
+	var storeNotFound *application.StoreNotFound
+	if errors.As(err, &storeNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error   string `json:"error"`
+			StoreId string `json:"storeId"`
+		}{
+			Error:   storeNotFound.Error(),
+			StoreId: storeNotFound.StoreId,
+		})
+	}
+
+	var productNotFound *application.ProductNotFound
+	if errors.As(err, &productNotFound) {
+		return c.JSON(http.StatusNotFound, struct {
+			Error     string `json:"error"`
+			ProductId string `json:"productId"`
+		}{
+			Error:     productNotFound.Error(),
+			ProductId: productNotFound.ProductId,
+		})
+	}
+
+	return err
+}
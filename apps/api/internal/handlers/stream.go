@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+// keepAlive is how often an idle stream sends a comment, so that proxies
+// don't close the connection while nothing changes.
+const keepAlive = 15 * time.Second
+
+// streamEvent is a single server-sent event. A nil streamEvent from an update
+// function means there is nothing new to send.
+type streamEvent struct {
+	Name string
+	Data any
+}
+
+// stream sends first as a server-sent event, then calls next each time the
+// feed reports new events, until the client goes away. An error from next is
+// sent to the client as an "error" event and ends the stream. While nothing
+// changes, a keepalive comment is sent every so often.
+func stream(c echo.Context, feed *live.Feed, first streamEvent, next func() (*streamEvent, error)) error {
+	changes, unsubscribe := feed.Subscribe()
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	if err := writeStreamEvent(c, first); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Response(), ": keepalive\n\n"); err != nil {
+				return err
+			}
+
+			c.Response().Flush()
+		case <-changes:
+			ev, err := next()
+
+			if err != nil {
+				return writeStreamEvent(c, streamEvent{Name: "error", Data: struct {
+					Error string `json:"error"`
+				}{Error: err.Error()}})
+			}
+
+			if ev == nil {
+				continue
+			}
+
+			if err := writeStreamEvent(c, *ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeStreamEvent(c echo.Context, ev streamEvent) error {
+	data, err := json.Marshal(ev.Data)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.Response(), "event: %s\ndata: %s\n\n", ev.Name, data); err != nil {
+		return err
+	}
+
+	c.Response().Flush()
+
+	return nil
+}
+
+// changedJSON wraps an update function so that it only produces an event when
+// the JSON of the value it returns differs from the last one sent.
+func changedJSON(name string, last any, get func() (any, error)) func() (*streamEvent, error) {
+	previous, _ := json.Marshal(last)
+
+	return func() (*streamEvent, error) {
+		v, err := get()
+
+		if err != nil {
+			return nil, err
+		}
+
+		current, err := json.Marshal(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if string(current) == string(previous) {
+			return nil, nil
+		}
+
+		previous = current
+
+		return &streamEvent{Name: name, Data: v}, nil
+	}
+}
@@ -0,0 +1,44 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStreamingBasket(t *testing.T) {
+	es := createEventStore(t)
+	br := basket.NewBasketRepository(es, nil)
+
+	b, err := basket.NewBasket(1)
+	assert.NoError(t, err)
+	err = br.Save(b)
+	assert.NoError(t, err)
+
+	feed := live.NewFeed(es)
+	h := &handlers.BasketHandler{Application: application.NewBasketApplication(br), Feed: feed}
+
+	events := openStream(t, "/baskets/1/stream", func(e *echo.Echo) {
+		e.GET("/baskets/:shopId/stream", h.StreamBasket)
+	})
+
+	assert.Equal(t, "event: basket\ndata: {\"shopId\":1,\"items\":[]}", events())
+
+	other, err := basket.NewBasket(2)
+	assert.NoError(t, err)
+	err = br.Save(other)
+	assert.NoError(t, err)
+	feed.Notify()
+
+	err = b.AddItem(basket.NewBasketItem("ing-1"))
+	assert.NoError(t, err)
+	err = br.Save(b)
+	assert.NoError(t, err)
+	feed.Notify()
+
+	assert.Equal(t, "event: basket\ndata: {\"shopId\":1,\"items\":[{\"ingredientId\":\"ing-1\"}]}", events())
+}
@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStreamingCurrentShop(t *testing.T) {
+	r := shop.NewFakeShopRepository()
+
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	feed := live.NewFeed(createEventStore(t))
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork()), Feed: feed}
+
+	events := openStream(t, "/shops/current/stream", func(e *echo.Echo) {
+		e.GET("/shops/current/stream", h.StreamCurrentShop)
+	})
+
+	assert.Equal(t, "event: shop\ndata: {\"id\":1,\"meals\":[],\"items\":[]}", events())
+
+	err = s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+	assert.NoError(t, err)
+	err = r.Save(s)
+	assert.NoError(t, err)
+	feed.Notify()
+
+	assert.Equal(t, "event: shop\ndata: {\"id\":1,\"meals\":[{\"id\":\"abc\"}],\"items\":[]}", events())
+
+	next, err := shop.NewShop(2)
+	assert.NoError(t, err)
+	err = r.Save(next)
+	assert.NoError(t, err)
+	feed.Notify()
+
+	assert.Equal(t, "event: shop\ndata: {\"id\":2,\"meals\":[],\"items\":[]}", events())
+}
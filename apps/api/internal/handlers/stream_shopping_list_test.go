@@ -0,0 +1,134 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamingShoppingList(t *testing.T) {
+	es := createEventStore(t)
+
+	p, err := product.NewProduct("a", "A", category.Bakery)
+	assert.NoError(t, err)
+	err = product.NewProductRepository(es, nil).Add(p)
+	assert.NoError(t, err)
+
+	m := meal.NewMealBuilder().WithId("m").AddIngredient(*meal.NewIngredient("a")).Build()
+	err = meal.NewMealRepository(es, nil).Save(m)
+	assert.NoError(t, err)
+
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = s.AddMeal(&shop.ShopMeal{MealId: "m"})
+	assert.NoError(t, err)
+	err = shop.NewShopRepository(es, nil).Save(s)
+	assert.NoError(t, err)
+
+	br := basket.NewBasketRepository(es, nil)
+	b, err := basket.NewBasket(1)
+	assert.NoError(t, err)
+	err = br.Save(b)
+	assert.NoError(t, err)
+
+	feed := live.NewFeed(es)
+	h := &handlers.ShoppingListHandler{Service: shoppinglist.NewService(es), Feed: feed}
+
+	events := openStream(t, "/shopping-list/stream", func(e *echo.Echo) {
+		e.GET("/shopping-list/stream", h.StreamShoppingList)
+	})
+
+	assert.Equal(t, `event: shoppingList
+data: {"shopId":1,"shoppingList":{"a":{"id":"a","name":"A","category":"Bakery","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}}}`, events())
+
+	err = b.AddItem(basket.NewBasketItem("a"))
+	assert.NoError(t, err)
+	err = br.Save(b)
+	assert.NoError(t, err)
+	feed.Notify()
+
+	assert.Equal(t, `event: diff
+data: {"shopId":1,"changed":{"a":{"id":"a","name":"A","category":"Bakery","mealCount":1,"isInBasket":true,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}},"removed":[]}`, events())
+
+	err = s.RemoveMeal("m")
+	assert.NoError(t, err)
+	err = shop.NewShopRepository(es, nil).Save(s)
+	assert.NoError(t, err)
+	feed.Notify()
+
+	assert.Equal(t, `event: diff
+data: {"shopId":1,"changed":{},"removed":["a"]}`, events())
+}
+
+func TestStreamingShoppingListWhenProjectionFails(t *testing.T) {
+	es := createEventStore(t)
+	breakShoppingList(t, es)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shopping-list/stream", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShoppingListHandler{Service: shoppinglist.NewService(es), Feed: live.NewFeed(es)}
+
+	if assert.NoError(t, h.StreamShoppingList(c)) {
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, `{"error":"shopping list unavailable"}`+"\n", rec.Body.String())
+	}
+}
+
+// openStream serves the routes added by routes and connects to the given
+// path, returning a function that reads the next server-sent event.
+func openStream(t *testing.T, path string, routes func(e *echo.Echo)) func() string {
+	e := echo.New()
+	routes(e)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+path, nil)
+	assert.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get(echo.HeaderContentType))
+
+	reader := bufio.NewReader(res.Body)
+
+	return func() string {
+		var lines []string
+
+		for {
+			line, err := reader.ReadString('\n')
+			if !assert.NoError(t, err) {
+				return ""
+			}
+
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return strings.Join(lines, "\n")
+			}
+
+			lines = append(lines, line)
+		}
+	}
+}
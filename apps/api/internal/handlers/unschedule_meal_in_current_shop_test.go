@@ -0,0 +1,41 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnschedulingMealInCurrentShop(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	s.AddMeal(&shop.ShopMeal{MealId: "abc", Schedule: &shop.Schedule{Date: "2026-10-13", Slot: shop.Dinner}})
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("DELETE", "/shops/current/meals/abc/schedule", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("abc")
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UnscheduleMealInCurrentShop(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":1,"meals":[{"id":"abc"}],"items":[]}`+"\n", rec.Body.String())
+		s, _ := r.Find(1)
+		assert.Equal(t, []*shop.ShopMeal{{MealId: "abc"}}, s.Meals)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChangingIngredientQuantity(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("Curry").AddIngredient(*meal.NewIngredient("ing-1").WithId("ing-1")).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/meals/123/ingredients/ing-1", strings.NewReader(`{"quantity": {"amount": 2.5, "unit": "Tbsp"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId", "ingredientId")
+	c.SetParamValues("123", "ing-1")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UpdateIngredient(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"123","name":"Curry","url":"","servings":0,"ingredients":[{"id":"ing-1","ingredientId":"ing-1","quantity":{"amount":2.5,"unit":"Tbsp"}}]}`+"\n", rec.Body.String())
+
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Equal(t, []meal.Ingredient{{ProductId: "ing-1", Id: "ing-1", Quantity: quantity.Quantity{Amount: quantity.NewFraction(5, 2), Unit: quantity.Tbsp}}}, m.Ingredients)
+	}
+}
+
+func TestChangingIngredientNote(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("Curry").AddIngredient(*meal.NewIngredient("ing-1").WithId("ing-1")).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/meals/123/ingredients/ing-1", strings.NewReader(`{"note": "diced"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId", "ingredientId")
+	c.SetParamValues("123", "ing-1")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UpdateIngredient(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"123","name":"Curry","url":"","servings":0,"ingredients":[{"id":"ing-1","ingredientId":"ing-1","quantity":{"amount":1,"unit":"Number"},"note":"diced"}]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestChangingQuantityOfIngredientNotInMeal(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/meals/123/ingredients/ing-1", strings.NewReader(`{"quantity": {"amount": 2, "unit": "Tbsp"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId", "ingredientId")
+	c.SetParamValues("123", "ing-1")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UpdateIngredient(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"ingredient not in meal","ingredientId":"ing-1"}`+"\n", rec.Body.String())
+	}
+}
+
+func TestChangingIngredientQuantityToZero(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").AddIngredient(*meal.NewIngredient("ing-1").WithId("ing-1")).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/meals/123/ingredients/ing-1", strings.NewReader(`{"quantity": {"amount": 0, "unit": "Tbsp"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId", "ingredientId")
+	c.SetParamValues("123", "ing-1")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UpdateIngredient(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"amount must be greater than zero"}`+"\n", rec.Body.String())
+	}
+}
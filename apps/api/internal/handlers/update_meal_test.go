@@ -3,7 +3,9 @@ package handlers_test
 import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -27,14 +29,14 @@ func TestUpdatingMealName(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("123")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.UpdateMeal(c)) {
 		m, err := repo.Get()
 		assert.NoError(t, err)
 		assert.Len(t, m, 1)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, "{\"id\":\"123\",\"name\":\"bar\",\"url\":\"foo.localhost\",\"ingredients\":[]}\n", rec.Body.String())
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"bar\",\"url\":\"foo.localhost\",\"servings\":0,\"ingredients\":[]}\n", rec.Body.String())
 		assert.EqualExportedValues(t, &meal.Meal{Id: "123", Name: "bar", Url: "foo.localhost", Ingredients: make([]meal.Ingredient, 0)}, m[0])
 	}
 }
@@ -54,14 +56,14 @@ func TestUpdatingMealUrl(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("123")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.UpdateMeal(c)) {
 		m, err := repo.Get()
 		assert.NoError(t, err)
 		assert.Len(t, m, 1)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"https://test.localhost\",\"ingredients\":[]}\n", rec.Body.String())
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"https://test.localhost\",\"servings\":0,\"ingredients\":[]}\n", rec.Body.String())
 		assert.EqualExportedValues(t, &meal.Meal{Id: "123", Name: "foo", Url: "https://test.localhost", Ingredients: make([]meal.Ingredient, 0)}, m[0])
 	}
 }
@@ -81,14 +83,14 @@ func TestUpdatingMealUrlToBeEmpty(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("123")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.UpdateMeal(c)) {
 		m, err := repo.Get()
 		assert.NoError(t, err)
 		assert.Len(t, m, 1)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"\",\"ingredients\":[]}\n", rec.Body.String())
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"\",\"servings\":0,\"ingredients\":[]}\n", rec.Body.String())
 		assert.EqualExportedValues(t, &meal.Meal{Id: "123", Name: "foo", Url: "", Ingredients: make([]meal.Ingredient, 0)}, m[0])
 	}
 }
@@ -108,14 +110,127 @@ func TestUpdatingMealWithUrlAndName(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("mealId")
 	c.SetParamValues("123")
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.UpdateMeal(c)) {
 		m, err := repo.Get()
 		assert.NoError(t, err)
 		assert.Len(t, m, 1)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, "{\"id\":\"123\",\"name\":\"bar\",\"url\":\"https://bar.localhost\",\"ingredients\":[]}\n", rec.Body.String())
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"bar\",\"url\":\"https://bar.localhost\",\"servings\":0,\"ingredients\":[]}\n", rec.Body.String())
 		assert.EqualExportedValues(t, &meal.Meal{Id: "123", Name: "bar", Url: "https://bar.localhost", Ingredients: make([]meal.Ingredient, 0)}, m[0])
 	}
 }
+
+func TestUpdatingMealServings(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("foo").WithServings(2).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/meals/123", strings.NewReader(`{"servings": 4}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UpdateMeal(c)) {
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"foo\",\"url\":\"\",\"servings\":4,\"ingredients\":[]}\n", rec.Body.String())
+		assert.Equal(t, 4, m.Servings)
+	}
+}
+
+func TestUpdatingMealServingsToBeNegative(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("foo").WithServings(2).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/meals/123", strings.NewReader(`{"servings": -1}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UpdateMeal(c)) {
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, 2, m.Servings)
+	}
+}
+
+func TestUpdatingMealRecipe(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("foo").WithTimes(10, 0).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/meals/123", strings.NewReader(`{"steps": [" Chop the onions ", "Fry them"], "cookTime": 20, "tags": ["Vegetarian", "quick ", "vegetarian"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("mealId")
+	c.SetParamValues("123")
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.UpdateMeal(c)) {
+		m, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"123","name":"foo","url":"","servings":0,"ingredients":[],"steps":["Chop the onions","Fry them"],"prepTime":10,"cookTime":20,"tags":["vegetarian","quick"]}`+"\n", rec.Body.String())
+		assert.Equal(t, []string{"Chop the onions", "Fry them"}, m.Steps)
+		assert.Equal(t, 10, m.PrepTime)
+		assert.Equal(t, 20, m.CookTime)
+		assert.Equal(t, []string{"vegetarian", "quick"}, m.Tags)
+	}
+}
+
+func TestUpdatingMealWithInvalidRecipe(t *testing.T) {
+	tests := []struct {
+		body  string
+		error string
+	}{
+		{`{"steps": ["Chop the onions", " "]}`, "steps cannot be empty"},
+		{`{"prepTime": -5}`, "prepTime cannot be negative"},
+		{`{"cookTime": -5}`, "cookTime cannot be negative"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.body, func(t *testing.T) {
+			repo := meal.NewFakeMealRepository()
+
+			err := repo.Save(meal.NewMealBuilder().WithId("123").WithName("foo").WithSteps("Fry the onions").WithTimes(5, 10).Build())
+			assert.NoError(t, err)
+
+			e := echo.New()
+			req := httptest.NewRequest("PATCH", "/meals/123", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("mealId")
+			c.SetParamValues("123")
+			h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+			if assert.NoError(t, h.UpdateMeal(c)) {
+				m, err := repo.Find("123")
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Equal(t, `{"error":"`+test.error+`"}`+"\n", rec.Body.String())
+				assert.Equal(t, []string{"Fry the onions"}, m.Steps)
+				assert.Equal(t, 5, m.PrepTime)
+				assert.Equal(t, 10, m.CookTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenamingProduct(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	err := repo.Add(product.NewProductBuilder().WithId("123").WithName("Tomatos").WithCategory(category.Fruit).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/products/123", strings.NewReader(`{"name": "Tomatoes"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.UpdateProduct(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"Tomatoes\",\"category\":\"Fruit\"}\n", rec.Body.String())
+
+		p, err := repo.Find("123")
+		assert.NoError(t, err)
+		assert.EqualExportedValues(t, &product.Product{Id: "123", Name: "Tomatoes", Category: category.Fruit}, p)
+	}
+}
+
+func TestChangingProductCategory(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	err := repo.Add(product.NewProductBuilder().WithId("123").WithName("Tomatoes").WithCategory(category.Fruit).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/products/123", strings.NewReader(`{"category": "Vegetables"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.UpdateProduct(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "{\"id\":\"123\",\"name\":\"Tomatoes\",\"category\":\"Vegetables\"}\n", rec.Body.String())
+	}
+}
+
+func TestSettingProductDefaultQuantity(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	err := repo.Add(product.NewProductBuilder().WithId("123").WithName("Rice").WithCategory(category.PastaRiceAndNoodles).Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/products/123", strings.NewReader(`{"defaultQuantity": {"amount": 75, "unit": "Gram"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.UpdateProduct(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"123","name":"Rice","category":"PastaRiceAndNoodles","defaultQuantity":{"amount":75,"unit":"Gram"}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestRenamingProductToExistingName(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	err := repo.Add(product.NewProductBuilder().WithId("123").WithName("Tomato").Build())
+	assert.NoError(t, err)
+	err = repo.Add(product.NewProductBuilder().WithId("456").WithName("Tomatoes").Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/products/123", strings.NewReader(`{"name": "Tomatoes"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.UpdateProduct(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "{\"error\":\"product already exists\",\"productName\":\"Tomatoes\"}\n", rec.Body.String())
+	}
+}
+
+func TestRenamingProductToEmptyName(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+	err := repo.Add(product.NewProductBuilder().WithId("123").WithName("Tomato").Build())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/products/123", strings.NewReader(`{"name": ""}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.UpdateProduct(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestUpdatingProductThatDoesNotExist(t *testing.T) {
+	repo := product.NewFakeProductRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/products/123", strings.NewReader(`{"name": "Tomatoes"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
+
+	if assert.NoError(t, h.UpdateProduct(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "{\"error\":\"product not found\",\"productId\":\"123\"}\n", rec.Body.String())
+	}
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpdatingStore(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	s, err := store.NewStore("corner", "Corner Shop", []category.CategoryName{category.Fruit})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Save(s))
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/stores/corner", strings.NewReader(`{"name":"Local Shop","layout":["Dairy","Bakery"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("corner")
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.UpdateStore(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"corner","name":"Local Shop","layout":["Dairy","Bakery"],"locations":{}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestUpdatingStoreWithRepeatedCategoryInLayout(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	s, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Save(s))
+
+	e := echo.New()
+	req := httptest.NewRequest("PATCH", "/stores/corner", strings.NewReader(`{"layout":["Dairy","Dairy"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("corner")
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.UpdateStore(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"category listed more than once"}`+"\n", rec.Body.String())
+	}
+}
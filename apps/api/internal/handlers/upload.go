@@ -16,16 +16,50 @@ func (h *UploadHandler) UploadMeals(c echo.Context) error {
 	file, err := c.FormFile("meals")
 
 	if err != nil {
-		return err
+		return c.JSON(http.StatusBadRequest, struct {
+			Error string `json:"error"`
+		}{
+			Error: "meals file is required",
+		})
 	}
 
 	src, err := file.Open()
 
+	if err != nil {
+		return err
+	}
+
 	defer src.Close()
 
-	err = h.Application.UploadMeals(src)
+	options := application.UploadOptions{
+		Mode:               application.UploadMode(c.FormValue("mode")),
+		DryRun:             c.FormValue("dryRun") == "true",
+		AutoCreateProducts: c.FormValue("autoCreateProducts") == "true",
+	}
+
+	report, err := h.Application.UploadMeals(src, options)
 
 	if err != nil {
+		var invalidUpload *application.InvalidUpload
+		if errors.As(err, &invalidUpload) {
+			return c.JSON(http.StatusBadRequest, struct {
+				Error  string                       `json:"error"`
+				Errors []application.UploadRowError `json:"errors"`
+			}{
+				Error:  invalidUpload.Error(),
+				Errors: invalidUpload.Errors,
+			})
+		}
+
+		var validationError *application.ValidationError
+		if errors.As(err, &validationError) {
+			return c.JSON(http.StatusBadRequest, struct {
+				Error string `json:"error"`
+			}{
+				Error: validationError.Error(),
+			})
+		}
+
 		var mealAlreadyExists *application.MealAlreadyExists
 		if errors.As(err, &mealAlreadyExists) {
 			return c.JSON(http.StatusBadRequest, struct {
@@ -41,7 +75,13 @@ func (h *UploadHandler) UploadMeals(c echo.Context) error {
 				NotFoundProducts []product.ProductName `json:"notFoundIngredients"`
 			}{ingredientsNotFound.NotFoundProducts})
 		}
+
+		return err
+	}
+
+	if options.DryRun {
+		return c.JSON(http.StatusOK, report)
 	}
 
-	return c.NoContent(http.StatusCreated)
+	return c.JSON(http.StatusCreated, report)
 }
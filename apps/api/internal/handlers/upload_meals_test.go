@@ -2,16 +2,23 @@ package handlers_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -48,7 +55,7 @@ func TestUploadingMeals(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo)}
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	err = h.UploadMeals(c)
 
@@ -64,16 +71,16 @@ func TestUploadingMeals(t *testing.T) {
 	require.Equal(t, m[0].Name, "bar")
 	require.Len(t, m[0].Ingredients, 2)
 	require.Equal(t, []meal.Ingredient{
-		*meal.NewIngredient("def").WithQuantity(400, quantity.Gram),
-		*meal.NewIngredient("ghi").WithQuantity(6, quantity.Tbsp),
-	}, m[0].Ingredients)
+		*meal.NewIngredient("def").WithQuantity(quantity.NewAmount(400), quantity.Gram),
+		*meal.NewIngredient("ghi").WithQuantity(quantity.NewAmount(6), quantity.Tbsp),
+	}, withoutIngredientIds(m[0].Ingredients))
 
 	require.Equal(t, m[1].Name, "foo")
 	require.Len(t, m[1].Ingredients, 2)
 	require.Equal(t, []meal.Ingredient{
-		*meal.NewIngredient("abc").WithQuantity(300, quantity.Gram),
-		*meal.NewIngredient("def").WithQuantity(5, quantity.Tbsp),
-	}, m[1].Ingredients)
+		*meal.NewIngredient("abc").WithQuantity(quantity.NewAmount(300), quantity.Gram),
+		*meal.NewIngredient("def").WithQuantity(quantity.NewAmount(5), quantity.Tbsp),
+	}, withoutIngredientIds(m[1].Ingredients))
 }
 
 func TestProductsNotExisting(t *testing.T) {
@@ -99,7 +106,7 @@ func TestProductsNotExisting(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(product.NewFakeProductRepository(), repo)}
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(product.NewFakeProductRepository(), repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	err = h.UploadMeals(c)
 
@@ -153,7 +160,7 @@ func TestMealAlreadyExisting(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo)}
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	err = h.UploadMeals(c)
 
@@ -165,3 +172,496 @@ func TestMealAlreadyExisting(t *testing.T) {
 
 	require.Equal(t, "{\"error\":\"meal already exists\",\"mealName\":\"bar\"}\n", rec.Body.String())
 }
+
+func TestUploadingMealsWithFractionalAmounts(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+	productRepo := product.NewFakeProductRepository()
+
+	err := productRepo.Add(product.NewProductBuilder().WithName("Abc Name").WithId("abc").Build())
+	require.NoError(t, err)
+
+	err = productRepo.Add(product.NewProductBuilder().WithName("Def Name").WithId("def").Build())
+	require.NoError(t, err)
+
+	err = productRepo.Add(product.NewProductBuilder().WithName("Ghi Name").WithId("ghi").Build())
+	require.NoError(t, err)
+
+	e := echo.New()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	part, err := w.CreateFormFile("meals", "meals.csv")
+	require.NoError(t, err)
+
+	_, err = part.Write([]byte("name,product,amount,unit\nfoo,Abc Name,0.5,Kg\nfoo,Def Name,1/2,Cup\nfoo,Ghi Name,1 1/2,Litre"))
+	require.NoError(t, err)
+
+	err = w.Close()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/meals/upload", body)
+
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	err = h.UploadMeals(c)
+
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	m, err := repo.Get()
+
+	require.NoError(t, err)
+	require.Len(t, m, 1)
+
+	require.Equal(t, []meal.Ingredient{
+		*meal.NewIngredient("abc").WithQuantity(quantity.NewFraction(1, 2), quantity.Kg),
+		*meal.NewIngredient("def").WithQuantity(quantity.NewFraction(1, 2), quantity.Cup),
+		*meal.NewIngredient("ghi").WithQuantity(quantity.NewFraction(3, 2), quantity.Litre),
+	}, withoutIngredientIds(m[0].Ingredients))
+}
+
+func newUploadRequest(t *testing.T, csv string, fields map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	part, err := w.CreateFormFile("meals", "meals.csv")
+	require.NoError(t, err)
+
+	_, err = part.Write([]byte(csv))
+	require.NoError(t, err)
+
+	for name, value := range fields {
+		require.NoError(t, w.WriteField(name, value))
+	}
+
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", "/meals/upload", body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+
+	return req
+}
+
+func newUploadProductRepository(t *testing.T) *product.EventSourcedProductRepository {
+	productRepo := product.NewFakeProductRepository()
+
+	for _, id := range []string{"abc", "def", "ghi"} {
+		name := strings.ToUpper(id[:1]) + id[1:] + " Name"
+		require.NoError(t, productRepo.Add(product.NewProductBuilder().WithName(product.ProductName(name)).WithId(id).Build()))
+	}
+
+	return productRepo
+}
+
+func TestUploadingMealsInUpdateMode(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	bar := meal.NewMealBuilder().WithName("bar").
+		AddIngredient(*meal.NewIngredient("abc").WithId("abc").WithQuantity(quantity.NewAmount(1), quantity.Kg)).
+		AddIngredient(*meal.NewIngredient("def").WithId("def").WithQuantity(quantity.NewAmount(400), quantity.Gram).WithNote("sliced")).
+		Build()
+	require.NoError(t, repo.Save(bar))
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\nbar,Def Name,500,Gram\nbar,Ghi Name,6,Tbsp\nfoo,Abc Name,300,Gram", map[string]string{"mode": "update"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var report application.UploadReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+
+	require.Len(t, report.Meals, 2)
+	require.Len(t, report.Meals[0].Added, 1)
+	ghi := report.Meals[0].Added[0].Id
+
+	require.Equal(t, application.UploadedMealReport{
+		Rows:    []int{2, 3},
+		Name:    "bar",
+		MealId:  bar.Id,
+		Action:  application.MealUpdated,
+		Added:   []meal.Ingredient{*meal.NewIngredient("ghi").WithId(ghi).WithQuantity(quantity.NewAmount(6), quantity.Tbsp)},
+		Removed: []meal.Ingredient{*meal.NewIngredient("abc").WithId("abc").WithQuantity(quantity.NewAmount(1), quantity.Kg)},
+		Changed: []meal.Ingredient{*meal.NewIngredient("def").WithId("def").WithQuantity(quantity.NewAmount(500), quantity.Gram).WithNote("sliced")},
+	}, report.Meals[0])
+	require.Equal(t, "foo", report.Meals[1].Name)
+	require.Equal(t, application.MealCreated, report.Meals[1].Action)
+
+	m, err := repo.Find(bar.Id)
+	require.NoError(t, err)
+	require.Equal(t, "bar", m.Name)
+	require.Equal(t, []meal.Ingredient{
+		*meal.NewIngredient("def").WithId("def").WithQuantity(quantity.NewAmount(500), quantity.Gram).WithNote("sliced"),
+		*meal.NewIngredient("ghi").WithId(ghi).WithQuantity(quantity.NewAmount(6), quantity.Tbsp),
+	}, m.Ingredients)
+
+	meals, err := repo.Get()
+	require.NoError(t, err)
+	require.Len(t, meals, 2)
+}
+
+func TestUploadingMealWithDifferentlyCasedNameInUpdateMode(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	bar := meal.NewMealBuilder().WithName("bar").AddIngredient(*meal.NewIngredient("def").WithQuantity(quantity.NewAmount(400), quantity.Gram)).Build()
+	require.NoError(t, repo.Save(bar))
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\nBar,Def Name,400,Gram", map[string]string{"mode": "update"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var report application.UploadReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Equal(t, application.MealCreated, report.Meals[0].Action)
+
+	meals, err := repo.Get()
+	require.NoError(t, err)
+	require.Len(t, meals, 2)
+}
+
+func TestUploadingUnchangedMealInUpdateMode(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	bar := meal.NewMealBuilder().WithName("bar").AddIngredient(*meal.NewIngredient("def").WithQuantity(quantity.NewAmount(400), quantity.Gram)).Build()
+	require.NoError(t, repo.Save(bar))
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\nbar,Def Name,400,Gram", map[string]string{"mode": "update"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, `{"dryRun":false,"meals":[{"rows":[2],"name":"bar","mealId":"`+bar.Id+`","action":"unchanged"}],"notFoundProducts":[],"createdProducts":[]}`+"\n", rec.Body.String())
+}
+
+func TestUploadingMealsInSkipMode(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	bar := meal.NewMealBuilder().WithName("bar").AddIngredient(*meal.NewIngredient("abc").WithId("abc")).Build()
+	require.NoError(t, repo.Save(bar))
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\nfoo,Abc Name,300,Gram\nbar,Def Name,400,Gram", map[string]string{"mode": "skip"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var report application.UploadReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Equal(t, application.MealCreated, report.Meals[0].Action)
+	require.Equal(t, application.MealSkipped, report.Meals[1].Action)
+	require.Equal(t, bar.Id, report.Meals[1].MealId)
+
+	m, err := repo.Find(bar.Id)
+	require.NoError(t, err)
+	require.Equal(t, []meal.Ingredient{*meal.NewIngredient("abc").WithId("abc")}, m.Ingredients)
+
+	meals, err := repo.Get()
+	require.NoError(t, err)
+	require.Len(t, meals, 2)
+}
+
+func TestDryRunningMealUpload(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	bar := meal.NewMealBuilder().WithName("bar").Build()
+	require.NoError(t, repo.Save(bar))
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\nfoo,Abc Name,300,Gram\nbar,Def Name,400,Gram\nbaz,Xyz Name,1,Number", map[string]string{"dryRun": "true"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var report application.UploadReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+
+	require.True(t, report.DryRun)
+	require.Equal(t, []product.ProductName{"Xyz Name"}, report.NotFoundProducts)
+	require.Len(t, report.Meals, 3)
+
+	require.Equal(t, "foo", report.Meals[0].Name)
+	require.Equal(t, application.MealCreated, report.Meals[0].Action)
+	require.Equal(t, []meal.Ingredient{*meal.NewIngredient("abc").WithQuantity(quantity.NewAmount(300), quantity.Gram)}, withoutIngredientIds(report.Meals[0].Added))
+
+	require.Equal(t, application.UploadedMealReport{Rows: []int{3}, Name: "bar", MealId: bar.Id, Action: application.MealRejected, Reason: "meal already exists"}, report.Meals[1])
+	require.Equal(t, application.UploadedMealReport{Rows: []int{4}, Name: "baz", Action: application.MealRejected, Reason: "products not found"}, report.Meals[2])
+
+	meals, err := repo.Get()
+	require.NoError(t, err)
+	require.Len(t, meals, 1)
+}
+
+func TestUploadingMealsInUnknownMode(t *testing.T) {
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\nfoo,Abc Name,300,Gram", map[string]string{"mode": "replace"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(newUploadProductRepository(t), repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"mode must be create, update or skip"}`+"\n", rec.Body.String())
+}
+
+func TestUploadingMealsCreatingMissingProducts(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit,category\n"+
+		"foo,Abc Name,300,Gram,\n"+
+		"foo,Rice,200,Gram,PastaRiceAndNoodles\n"+
+		"bar,Rice,100,Gram,\n"+
+		"bar,Mint,1,Bunch,vegetables", map[string]string{"autoCreateProducts": "true"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var report application.UploadReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.CreatedProducts, 2)
+	require.Equal(t, product.ProductName("Rice"), report.CreatedProducts[0].Name)
+	require.Equal(t, category.PastaRiceAndNoodles, report.CreatedProducts[0].Category)
+	require.Equal(t, product.ProductName("Mint"), report.CreatedProducts[1].Name)
+	require.Equal(t, category.Vegetables, report.CreatedProducts[1].Category)
+
+	rice, err := productRepo.GetByName("Rice")
+	require.NoError(t, err)
+	mint, err := productRepo.GetByName("Mint")
+	require.NoError(t, err)
+
+	m, err := repo.Get()
+	require.NoError(t, err)
+	require.Len(t, m, 2)
+
+	require.Equal(t, "bar", m[0].Name)
+	require.Equal(t, []meal.Ingredient{
+		*meal.NewIngredient(rice.Id).WithQuantity(quantity.NewAmount(100), quantity.Gram),
+		*meal.NewIngredient(mint.Id).WithQuantity(quantity.NewAmount(1), quantity.Bunch),
+	}, withoutIngredientIds(m[0].Ingredients))
+
+	require.Equal(t, "foo", m[1].Name)
+	require.Equal(t, []meal.Ingredient{
+		*meal.NewIngredient("abc").WithQuantity(quantity.NewAmount(300), quantity.Gram),
+		*meal.NewIngredient(rice.Id).WithQuantity(quantity.NewAmount(200), quantity.Gram),
+	}, withoutIngredientIds(m[1].Ingredients))
+}
+
+func TestUploadingMealsWithoutCategoryForMissingProduct(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit,category\nfoo,Rice,200,Gram,", map[string]string{"autoCreateProducts": "true"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"notFoundIngredients":["Rice"]}`+"\n", rec.Body.String())
+
+	_, err := productRepo.GetByName("Rice")
+	require.Error(t, err)
+}
+
+func TestUploadingMealsWhenProductsCannotBeRead(t *testing.T) {
+	es := memory.Create()
+	productRepo := product.NewProductRepository(es, func() (core.Iterator, error) {
+		return nil, errors.New("read failed")
+	})
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit,category\nfoo,Rice,200,Gram,PastaRiceAndNoodles", map[string]string{"autoCreateProducts": "true"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.EqualError(t, h.UploadMeals(c), "read failed")
+
+	events, err := es.All(0, 100)()
+	require.NoError(t, err)
+	require.False(t, events.Next())
+}
+
+func TestUploadingMealsCreatingProductInUnknownCategory(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit,category\nfoo,Rice,200,Gram,Grains", map[string]string{"autoCreateProducts": "true"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"invalid upload","errors":[{"row":2,"column":"category","value":"Grains","reason":"category does not exist"}]}`+"\n", rec.Body.String())
+
+	m, err := repo.Get()
+	require.NoError(t, err)
+	require.Len(t, m, 0)
+}
+
+func TestDryRunningMealUploadCreatingProducts(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit,category\nfoo,Rice,200,Gram,PastaRiceAndNoodles", map[string]string{"autoCreateProducts": "true", "dryRun": "true"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var report application.UploadReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.CreatedProducts, 1)
+	require.Equal(t, application.MealCreated, report.Meals[0].Action)
+
+	_, err := productRepo.GetByName("Rice")
+	require.Error(t, err)
+}
+
+func TestUploadingMealsWithInvalidRows(t *testing.T) {
+	productRepo := newUploadProductRepository(t)
+	repo := meal.NewFakeMealRepository()
+
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\n"+
+		"foo,Abc Name,300,Gram\n"+
+		"foo,,lots,Gram\n"+
+		",Def Name,0,Spoon\n"+
+		"bar,Def Name,400\n"+
+		"bar,Ghi Name,6,Tbsp\n"+
+		"foo,Def Name,5,Tbsp", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(productRepo, repo, category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"invalid upload","errors":[`+
+		`{"row":3,"column":"product","reason":"product cannot be empty"},`+
+		`{"row":3,"column":"amount","value":"lots","reason":"invalid amount: lots"},`+
+		`{"row":4,"column":"name","reason":"name cannot be empty"},`+
+		`{"row":4,"column":"amount","value":"0","reason":"amount must be greater than zero"},`+
+		`{"row":4,"column":"unit","value":"Spoon","reason":"invalid unit"},`+
+		`{"row":5,"reason":"expected 4 columns, found 3"},`+
+		`{"row":7,"column":"name","value":"foo","reason":"rows for a meal must be together, but this meal's rows start on row 2"}`+
+		`]}`+"\n", rec.Body.String())
+
+	m, err := repo.Get()
+	require.NoError(t, err)
+	require.Len(t, m, 0)
+}
+
+func TestUploadingMealsWithInvalidHeader(t *testing.T) {
+	tests := map[string]string{
+		"name,product,quantity,unit\nfoo,Abc Name,300,Gram": `[{"row":1,"column":"amount","value":"quantity","reason":"expected amount column"}]`,
+		"name,product\nfoo,Abc Name":                        `[{"row":1,"reason":"expected columns name, product, amount, unit and optionally category"}]`,
+		"":                                                  `[{"row":1,"reason":"missing header"}]`,
+	}
+
+	for csv, expected := range tests {
+		t.Run(csv, func(t *testing.T) {
+			e := echo.New()
+			req := newUploadRequest(t, csv, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(newUploadProductRepository(t), meal.NewFakeMealRepository(), category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+			require.NoError(t, h.UploadMeals(c))
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Equal(t, `{"error":"invalid upload","errors":`+expected+`}`+"\n", rec.Body.String())
+		})
+	}
+}
+
+func TestUploadingMealsWithMalformedCsv(t *testing.T) {
+	e := echo.New()
+	req := newUploadRequest(t, "name,product,amount,unit\nfoo,Abc \"Name,300,Gram\nfoo,Def Name,5,Tbsp", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(newUploadProductRepository(t), meal.NewFakeMealRepository(), category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"invalid upload","errors":[{"row":2,"reason":"bare \" in non-quoted-field"}]}`+"\n", rec.Body.String())
+}
+
+func TestUploadingWithoutMealsFile(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/meals/upload", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := &handlers.UploadHandler{Application: application.NewUploadMealsApplication(newUploadProductRepository(t), meal.NewFakeMealRepository(), category.NewFakeCategoryRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	require.NoError(t, h.UploadMeals(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"error":"meals file is required"}`+"\n", rec.Body.String())
+}
+
+// withoutIngredientIds clears the ids of ingredients, which are random for
+// ingredients added to a meal.
+func withoutIngredientIds(ingredients []meal.Ingredient) []meal.Ingredient {
+	cleared := []meal.Ingredient{}
+
+	for _, i := range ingredients {
+		i.Id = ""
+		cleared = append(cleared, i)
+	}
+
+	return cleared
+}
@@ -1,6 +1,8 @@
 package handlers_test
 
 import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -15,10 +17,10 @@ func TestViewingCategories(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.CategoriesHandler{}
+	h := &handlers.CategoriesHandler{Application: application.NewCategoryApplication(category.NewFakeCategoryRepository())}
 
 	if assert.NoError(t, h.GetCategories(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, `[{"name":"AlcoholicDrinks"},{"name":"Bakery"},{"name":"Chilled"},{"name":"ChocolateAndSweets"},{"name":"Dairy"},{"name":"Desserts"},{"name":"Drinks"},{"name":"Eggs"},{"name":"FishAndSeafood"},{"name":"FoodCupboard"},{"name":"Frozen"},{"name":"Fruit"},{"name":"Meat"},{"name":"PastaRiceAndNoodles"},{"name":"SaucesOilsAndDressings"},{"name":"SeedsNutsAndDriedFruits"},{"name":"TeaAndCoffee"},{"name":"TinsCansAndPackets"},{"name":"Vegetables"}]`+"\n", rec.Body.String())
+		assert.Equal(t, `[{"id":"Fruit","name":"Fruit"},{"id":"Meat","name":"Meat"},{"id":"FishAndSeafood","name":"FishAndSeafood"},{"id":"FoodCupboard","name":"FoodCupboard"},{"id":"Drinks","name":"Drinks"},{"id":"Chilled","name":"Chilled"},{"id":"Frozen","name":"Frozen"},{"id":"Bakery","name":"Bakery"},{"id":"Vegetables","name":"Vegetables"},{"id":"TeaAndCoffee","name":"TeaAndCoffee"},{"id":"AlcoholicDrinks","name":"AlcoholicDrinks"},{"id":"SaucesOilsAndDressings","name":"SaucesOilsAndDressings"},{"id":"PastaRiceAndNoodles","name":"PastaRiceAndNoodles"},{"id":"SeedsNutsAndDriedFruits","name":"SeedsNutsAndDriedFruits"},{"id":"ChocolateAndSweets","name":"ChocolateAndSweets"},{"id":"TinsCansAndPackets","name":"TinsCansAndPackets"},{"id":"Desserts","name":"Desserts"},{"id":"Dairy","name":"Dairy"},{"id":"Eggs","name":"Eggs"}]`+"\n", rec.Body.String())
 	}
 }
@@ -0,0 +1,68 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestViewingCurrentShopWeek(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	s.AddMeal(&shop.ShopMeal{MealId: "lasagne", Schedule: &shop.Schedule{Date: "2026-10-13", Slot: shop.Dinner}})
+	s.AddMeal(&shop.ShopMeal{MealId: "curry", Schedule: &shop.Schedule{Date: "2026-10-16", Slot: shop.Dinner}})
+	s.AddMeal(&shop.ShopMeal{MealId: "porridge", Schedule: &shop.Schedule{Date: "2026-10-13", Slot: shop.Breakfast}})
+	s.AddMeal(&shop.ShopMeal{MealId: "soup"})
+	s.AddMeal(&shop.ShopMeal{MealId: "pie", Schedule: &shop.Schedule{Date: "2026-10-20", Slot: shop.Lunch}})
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shops/current/week?date=2026-10-15", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.CurrentShopWeek(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"start":"2026-10-12","days":[`+
+			`{"date":"2026-10-12","breakfast":[],"lunch":[],"dinner":[]},`+
+			`{"date":"2026-10-13","breakfast":[{"id":"porridge","schedule":{"date":"2026-10-13","slot":"Breakfast"}}],"lunch":[],"dinner":[{"id":"lasagne","schedule":{"date":"2026-10-13","slot":"Dinner"}}]},`+
+			`{"date":"2026-10-14","breakfast":[],"lunch":[],"dinner":[]},`+
+			`{"date":"2026-10-15","breakfast":[],"lunch":[],"dinner":[]},`+
+			`{"date":"2026-10-16","breakfast":[],"lunch":[],"dinner":[{"id":"curry","schedule":{"date":"2026-10-16","slot":"Dinner"}}]},`+
+			`{"date":"2026-10-17","breakfast":[],"lunch":[],"dinner":[]},`+
+			`{"date":"2026-10-18","breakfast":[],"lunch":[],"dinner":[]}`+
+			`],"unscheduled":[{"id":"soup"}]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingCurrentShopWeekWithInvalidDate(t *testing.T) {
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+
+	r := shop.NewFakeShopRepository()
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shops/current/week?date=tuesday", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.CurrentShopWeek(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/bus"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestViewingHealth(t *testing.T) {
+	es := createEventStore(t)
+	service := shoppinglist.NewService(es)
+	_, err := service.Current()
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/health", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.HealthHandler{ShoppingList: service, Feed: live.NewFeed(es), Events: createBus(t, es, nil)}
+
+	if assert.NoError(t, h.GetHealth(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"status":"ok","shoppingList":{"healthy":true},"feed":{"healthy":true},"events":{"healthy":true}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingHealthWhenShoppingListFails(t *testing.T) {
+	es := createEventStore(t)
+	breakShoppingList(t, es)
+
+	service := shoppinglist.NewService(es)
+	_, err := service.Current()
+	assert.Error(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/health", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.HealthHandler{ShoppingList: service, Feed: live.NewFeed(es), Events: createBus(t, es, nil)}
+
+	if assert.NoError(t, h.GetHealth(c)) {
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, `{"status":"unhealthy","shoppingList":{"healthy":false,"error":"ingredient not found"},"feed":{"healthy":true},"events":{"healthy":true}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingHealthWhenEventsFail(t *testing.T) {
+	es := createEventStore(t)
+	err := meal.NewMealRepository(es, nil).Save(meal.NewMealBuilder().Build())
+	assert.NoError(t, err)
+
+	events := createBus(t, es, func(eventsourcing.Event) error {
+		return errors.New("handler failed")
+	})
+	assert.Error(t, events.Dispatch())
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/health", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.HealthHandler{ShoppingList: shoppinglist.NewService(createEventStore(t)), Feed: live.NewFeed(es), Events: events}
+
+	if assert.NoError(t, h.GetHealth(c)) {
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, `{"status":"unhealthy","shoppingList":{"healthy":true},"feed":{"healthy":true},"events":{"healthy":false,"error":"handler failed"}}`+"\n", rec.Body.String())
+	}
+}
+
+// createBus creates a bus over the event store, with handle subscribed to it
+// if given.
+func createBus(t *testing.T, es *sqlStore.SQLite, handle bus.Handler) *bus.Bus {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	b, err := bus.NewBus(db, es)
+	assert.NoError(t, err)
+	b.Retries = 0
+
+	if handle != nil {
+		assert.NoError(t, b.Subscribe("test", handle))
+	}
+
+	return b
+}
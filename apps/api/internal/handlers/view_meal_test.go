@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -13,7 +15,7 @@ import (
 )
 
 func TestViewingMeal(t *testing.T) {
-	m := meal.NewMealBuilder().WithName("Burritos").AddIngredient(*meal.NewIngredient("ing-123")).Build()
+	m := meal.NewMealBuilder().WithName("Burritos").AddIngredient(*meal.NewIngredient("ing-123").WithId("ing-123")).Build()
 
 	repo := meal.NewFakeMealRepository()
 	err := repo.Save(m)
@@ -26,11 +28,11 @@ func TestViewingMeal(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
 	c.SetParamValues(m.Id)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.FindMeal(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, fmt.Sprintf(`{"id":"%s","name":"Burritos","url":"","ingredients":[{"id":"ing-123","quantity":{"amount":1,"unit":"Number"}}]}`+"\n", m.Id), rec.Body.String())
+		assert.Equal(t, fmt.Sprintf(`{"id":"%s","name":"Burritos","url":"","servings":0,"ingredients":[{"id":"ing-123","ingredientId":"ing-123","quantity":{"amount":1,"unit":"Number"}}]}`+"\n", m.Id), rec.Body.String())
 	}
 }
 
@@ -48,10 +50,32 @@ func TestViewingMealWithNoIngredients(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
 	c.SetParamValues(m.Id)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.FindMeal(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, fmt.Sprintf(`{"id":"%s","name":"Burritos","url":"","ingredients":[]}`+"\n", m.Id), rec.Body.String())
+		assert.Equal(t, fmt.Sprintf(`{"id":"%s","name":"Burritos","url":"","servings":0,"ingredients":[]}`+"\n", m.Id), rec.Body.String())
+	}
+}
+
+func TestViewingMealWithRecipe(t *testing.T) {
+	m := meal.NewMealBuilder().WithName("Burritos").WithSteps("Cook the rice", "Wrap it up").WithTimes(10, 20).WithTags("mexican").Build()
+
+	repo := meal.NewFakeMealRepository()
+	err := repo.Save(m)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/meals/"+m.Id, nil)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(m.Id)
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.FindMeal(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, fmt.Sprintf(`{"id":"%s","name":"Burritos","url":"","servings":0,"ingredients":[],"steps":["Cook the rice","Wrap it up"],"prepTime":10,"cookTime":20,"tags":["mexican"]}`+"\n", m.Id), rec.Body.String())
 	}
 }
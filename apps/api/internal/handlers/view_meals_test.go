@@ -1,10 +1,13 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -30,10 +33,71 @@ func TestViewingMeals(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo)}
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
 
 	if assert.NoError(t, h.GetMeals(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, fmt.Sprintf(`[{"id":"%s","name":"Burritos","url":"","ingredients":[]},{"id":"%s","name":"Shepherd's pie","url":"","ingredients":[]},{"id":"%s","name":"Tacos","url":"","ingredients":[]}]`+"\n", meal1.Id, meal2.Id, meal3.Id), rec.Body.String())
+		assert.Equal(t, fmt.Sprintf(`[{"id":"%s","name":"Burritos","url":"","servings":0,"ingredients":[]},{"id":"%s","name":"Shepherd's pie","url":"","servings":0,"ingredients":[]},{"id":"%s","name":"Tacos","url":"","servings":0,"ingredients":[]}]`+"\n", meal1.Id, meal2.Id, meal3.Id), rec.Body.String())
+	}
+}
+
+func TestFilteringMeals(t *testing.T) {
+	chilli := meal.NewMealBuilder().WithName("Chilli").WithTimes(15, 60).WithTags("vegetarian", "mexican").Build()
+	salad := meal.NewMealBuilder().WithName("Salad").WithTimes(10, 0).WithTags("vegetarian").Build()
+	soup := meal.NewMealBuilder().WithName("Soup").WithTags("vegetarian").Build()
+	tacos := meal.NewMealBuilder().WithName("Tacos").WithTimes(10, 15).WithTags("mexican").Build()
+
+	repo := meal.NewFakeMealRepository()
+	for _, m := range []*meal.Meal{chilli, salad, soup, tacos} {
+		assert.NoError(t, repo.Save(m))
+	}
+
+	tests := []struct {
+		query string
+		meals []string
+	}{
+		{"tag=vegetarian", []string{"Chilli", "Salad", "Soup"}},
+		{"tag=Vegetarian&tag=mexican", []string{"Chilli"}},
+		{"maxTime=30", []string{"Salad", "Tacos"}},
+		{"tag=vegetarian&maxTime=30", []string{"Salad"}},
+		{"tag=italian", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest("", "/meals?"+test.query, nil)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			h := &handlers.MealsHandler{Application: application.NewMealApplication(repo, product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+			if assert.NoError(t, h.GetMeals(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+
+				var meals []meal.Meal
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &meals))
+
+				names := []string{}
+				for _, m := range meals {
+					names = append(names, m.Name)
+				}
+				assert.Equal(t, test.meals, names)
+			}
+		})
+	}
+}
+
+func TestFilteringMealsByInvalidMaxTime(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("", "/meals?maxTime=soon", nil)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.MealsHandler{Application: application.NewMealApplication(meal.NewFakeMealRepository(), product.NewFakeProductRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.GetMeals(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"maxTime must be a number"}`+"\n", rec.Body.String())
 	}
 }
@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/quantity"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestViewingPantry(t *testing.T) {
+	p, err := pantry.NewPantry()
+	assert.NoError(t, err)
+
+	p.SetStock("rice", quantity.Quantity{Amount: quantity.NewFraction(3, 2), Unit: quantity.Kg})
+
+	r := pantry.NewFakePantryRepository()
+	err = r.Save(p)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/pantry", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	if assert.NoError(t, h.GetPantry(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"items":[{"productId":"rice","quantity":{"amount":1.5,"unit":"Kg"}}]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingEmptyPantry(t *testing.T) {
+	r := pantry.NewFakePantryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/pantry", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	if assert.NoError(t, h.GetPantry(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"items":[]}`+"\n", rec.Body.String())
+	}
+}
@@ -32,7 +32,7 @@ func TestViewingProducts(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo)}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository())}
 
 	if assert.NoError(t, h.GetProducts(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -70,7 +70,7 @@ func TestViewingProductsGrouped(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo), EventStore: es}
+	h := &handlers.ProductHandler{Application: application.NewProductApplication(repo, category.NewFakeCategoryRepository()), EventStore: es}
 
 	if assert.NoError(t, h.GetProducts(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
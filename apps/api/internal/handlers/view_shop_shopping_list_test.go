@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestViewingShopShoppingList(t *testing.T) {
+	r := shop.NewFakeShopRepository()
+
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = r.Save(s)
+	assert.NoError(t, err)
+
+	var requested int
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shops/1/shopping-list", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	h := &handlers.ShopsHandler{
+		Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork()),
+		ShopShoppingList: func(shopId int) (shoppinglist.ShoppingListProjectionOutput, error) {
+			requested = shopId
+			return shoppinglist.ShoppingListProjectionOutput{ShopId: &shopId, ShoppingList: &map[string]shoppinglist.ShoppingListItem{}}, nil
+		},
+	}
+
+	if assert.NoError(t, h.GetShopShoppingList(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, requested)
+		assert.Equal(t, `{"shopId":1,"shoppingList":{}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingShopShoppingListGroupedByStoreLayout(t *testing.T) {
+	es := createEventStore(t)
+
+	products := product.NewProductRepository(es, nil)
+	for _, p := range []*product.Product{
+		product.NewProductBuilder().WithId("a").WithName("Apples").WithCategory(category.Fruit).Build(),
+		product.NewProductBuilder().WithId("b").WithName("Bread").WithCategory(category.Bakery).Build(),
+	} {
+		assert.NoError(t, products.Add(p))
+	}
+
+	m := meal.NewMealBuilder().WithId("m").AddIngredients([]meal.Ingredient{*meal.NewIngredient("a"), *meal.NewIngredient("b")}).Build()
+	assert.NoError(t, meal.NewMealRepository(es, nil).Save(m))
+
+	stores := store.NewStoreRepository(es, nil)
+	corner, err := store.NewStore("corner", "Corner Shop", []category.CategoryName{category.Fruit})
+	assert.NoError(t, err)
+	assert.NoError(t, stores.Save(corner))
+
+	s, err := shop.NewShopAtStore(1, "corner")
+	assert.NoError(t, err)
+	assert.NoError(t, s.AddMeal(&shop.ShopMeal{MealId: "m"}))
+	shops := shop.NewShopRepository(es, nil)
+	assert.NoError(t, shops.Save(s))
+
+	categories := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shops/1/shopping-list?grouped=true", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	h := &handlers.ShopsHandler{
+		Application: application.NewShopApplication(shops, meal.NewFakeMealRepository(), stores, basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork()),
+		ShopShoppingList: func(shopId int) (shoppinglist.ShoppingListProjectionOutput, error) {
+			p, output := shoppinglist.CreateShopShoppingListProjection(es, shopId)
+			return output, p.RunToEnd(t.Context()).Error
+		},
+		Categories: application.NewCategoryApplication(categories),
+		Stores:     application.NewStoreApplication(stores, categories, products, shops),
+	}
+
+	if assert.NoError(t, h.GetShopShoppingList(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"shopId":1,"storeId":"corner","aisles":[`+
+			`{"category":{"id":"Fruit","name":"Fruit"},"items":[{"id":"a","name":"Apples","category":"Fruit","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}]},`+
+			`{"category":{"id":"Bakery","name":"Bakery"},"items":[{"id":"b","name":"Bread","category":"Bakery","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}]}`+
+			`]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingShoppingListOfMissingShop(t *testing.T) {
+	r := shop.NewFakeShopRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shops/5/shopping-list", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.GetShopShoppingList(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers_test
+
+import (
+	"github.com/hallgren/eventsourcing/aggregate"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestViewingShoppingList(t *testing.T) {
+	es := createEventStore(t)
+
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	err = shop.NewShopRepository(es, nil).Save(s)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shopping-list", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShoppingListHandler{Service: shoppinglist.NewService(es)}
+
+	if assert.NoError(t, h.GetShoppingList(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"shopId":1,"shoppingList":{}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingShoppingListGroupedByAisle(t *testing.T) {
+	es := createEventStore(t)
+
+	products := product.NewProductRepository(es, nil)
+	for _, p := range []*product.Product{
+		product.NewProductBuilder().WithId("a").WithName("Apples").WithCategory(category.Fruit).Build(),
+		product.NewProductBuilder().WithId("b").WithName("Bread").WithCategory(category.Bakery).Build(),
+		product.NewProductBuilder().WithId("c").WithName("Bananas").WithCategory(category.Fruit).Build(),
+	} {
+		assert.NoError(t, products.Add(p))
+	}
+
+	m := meal.NewMealBuilder().WithId("m").AddIngredients([]meal.Ingredient{*meal.NewIngredient("a"), *meal.NewIngredient("b"), *meal.NewIngredient("c")}).Build()
+	assert.NoError(t, meal.NewMealRepository(es, nil).Save(m))
+
+	s, err := shop.NewShop(1)
+	assert.NoError(t, err)
+	assert.NoError(t, s.AddMeal(&shop.ShopMeal{MealId: "m"}))
+	err = shop.NewShopRepository(es, nil).Save(s)
+	assert.NoError(t, err)
+
+	categories := category.NewFakeCategoryRepository()
+	aisles, err := categories.Get()
+	assert.NoError(t, err)
+	order := slices.DeleteFunc(slices.Clone(category.Defaults), func(id category.CategoryName) bool { return id == category.Bakery })
+	assert.NoError(t, aisles.Reorder(append([]category.CategoryName{category.Bakery}, order...)))
+	assert.NoError(t, categories.Save(aisles))
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shopping-list?grouped=true", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShoppingListHandler{
+		Service:    shoppinglist.NewService(es),
+		Categories: application.NewCategoryApplication(categories),
+		Stores:     application.NewStoreApplication(store.NewStoreRepository(es, nil), categories, products, shop.NewShopRepository(es, nil)),
+	}
+
+	if assert.NoError(t, h.GetShoppingList(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"shopId":1,"aisles":[`+
+			`{"category":{"id":"Bakery","name":"Bakery"},"items":[{"id":"b","name":"Bread","category":"Bakery","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}]},`+
+			`{"category":{"id":"Fruit","name":"Fruit"},"items":[{"id":"a","name":"Apples","category":"Fruit","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]},{"id":"c","name":"Bananas","category":"Fruit","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}]}`+
+			`]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingShoppingListGroupedByStoreLayout(t *testing.T) {
+	es := createEventStore(t)
+
+	products := product.NewProductRepository(es, nil)
+	for _, p := range []*product.Product{
+		product.NewProductBuilder().WithId("a").WithName("Apples").WithCategory(category.Fruit).Build(),
+		product.NewProductBuilder().WithId("b").WithName("Bread").WithCategory(category.Bakery).Build(),
+		product.NewProductBuilder().WithId("c").WithName("Bananas").WithCategory(category.Fruit).Build(),
+	} {
+		assert.NoError(t, products.Add(p))
+	}
+
+	m := meal.NewMealBuilder().WithId("m").AddIngredients([]meal.Ingredient{*meal.NewIngredient("a"), *meal.NewIngredient("b"), *meal.NewIngredient("c")}).Build()
+	assert.NoError(t, meal.NewMealRepository(es, nil).Save(m))
+
+	stores := store.NewStoreRepository(es, nil)
+	corner, err := store.NewStore("corner", "Corner Shop", []category.CategoryName{category.Bakery})
+	assert.NoError(t, err)
+	corner.LocateProduct("c", category.Bakery)
+	assert.NoError(t, stores.Save(corner))
+
+	s, err := shop.NewShopAtStore(1, "corner")
+	assert.NoError(t, err)
+	assert.NoError(t, s.AddMeal(&shop.ShopMeal{MealId: "m"}))
+	shops := shop.NewShopRepository(es, nil)
+	assert.NoError(t, shops.Save(s))
+
+	categories := category.NewFakeCategoryRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shopping-list?grouped=true", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShoppingListHandler{
+		Service:    shoppinglist.NewService(es),
+		Categories: application.NewCategoryApplication(categories),
+		Stores:     application.NewStoreApplication(stores, categories, products, shops),
+	}
+
+	if assert.NoError(t, h.GetShoppingList(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"shopId":1,"storeId":"corner","aisles":[`+
+			`{"category":{"id":"Bakery","name":"Bakery"},"items":[{"id":"c","name":"Bananas","category":"Bakery","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]},{"id":"b","name":"Bread","category":"Bakery","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}]},`+
+			`{"category":{"id":"Fruit","name":"Fruit"},"items":[{"id":"a","name":"Apples","category":"Fruit","mealCount":1,"isInBasket":false,"quantities":[{"amount":1,"unit":"Number"}],"total":[{"amount":1,"unit":"Number"}]}]}`+
+			`]}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingShoppingListWhenProjectionFails(t *testing.T) {
+	es := createEventStore(t)
+	breakShoppingList(t, es)
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shopping-list", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShoppingListHandler{Service: shoppinglist.NewService(es)}
+
+	if assert.NoError(t, h.GetShoppingList(c)) {
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, `{"error":"shopping list unavailable"}`+"\n", rec.Body.String())
+	}
+}
+
+func createEventStore(t *testing.T) *sqlStore.SQLite {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	assert.NoError(t, err)
+
+	return es
+}
+
+// breakShoppingList saves an event the shopping list projection can't apply.
+func breakShoppingList(t *testing.T, es *sqlStore.SQLite) {
+	m := meal.NewMealBuilder().Build()
+	aggregate.TrackChange(m, &meal.IngredientRemoved{IngredientId: "missing"})
+
+	err := meal.NewMealRepository(es, nil).Save(m)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestViewingShops(t *testing.T) {
+	r := shop.NewFakeShopRepository()
+
+	for id := 1; id <= 3; id++ {
+		s, err := shop.NewShop(id)
+		assert.NoError(t, err)
+		err = s.AddMeal(&shop.ShopMeal{MealId: "abc"})
+		assert.NoError(t, err)
+		err = r.Save(s)
+		assert.NoError(t, err)
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shops?page=2&pageSize=2", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.GetShops(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var page application.ShopsPage
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Equal(t, 2, page.Page)
+		assert.Equal(t, 2, page.PageSize)
+		assert.Equal(t, 3, page.Total)
+		assert.Len(t, page.Shops, 1)
+		assert.Equal(t, 1, page.Shops[0].Id)
+		assert.Equal(t, 1, page.Shops[0].MealCount)
+	}
+}
+
+func TestViewingShopsWithInvalidPage(t *testing.T) {
+	r := shop.NewFakeShopRepository()
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/shops?page=0", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.ShopsHandler{Application: application.NewShopApplication(r, meal.NewFakeMealRepository(), store.NewFakeStoreRepository(), basket.NewFakeBasketRepository(), unitofwork.NewFakeUnitOfWork())}
+
+	if assert.NoError(t, h.GetShops(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"error":"page must be at least 1"}`+"\n", rec.Body.String())
+	}
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
+	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestViewingStores(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	for id, name := range map[string]string{"super": "Supermarket", "corner": "Corner Shop"} {
+		s, err := store.NewStore(id, name, []category.CategoryName{category.Bakery})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Save(s))
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/stores", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.GetStores(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `[`+
+			`{"id":"corner","name":"Corner Shop","layout":["Bakery"],"locations":{}},`+
+			`{"id":"super","name":"Supermarket","layout":["Bakery"],"locations":{}}`+
+			`]`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingStore(t *testing.T) {
+	repo := store.NewFakeStoreRepository()
+	s, err := store.NewStore("corner", "Corner Shop", nil)
+	assert.NoError(t, err)
+	s.LocateProduct("bananas", category.Bakery)
+	assert.NoError(t, repo.Save(s))
+
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/stores/corner", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("corner")
+	h := &handlers.StoresHandler{Application: newStoreApplication(repo)}
+
+	if assert.NoError(t, h.GetStore(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"id":"corner","name":"Corner Shop","layout":[],"locations":{"bananas":"Bakery"}}`+"\n", rec.Body.String())
+	}
+}
+
+func TestViewingUnknownStore(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/stores/corner", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("corner")
+	h := &handlers.StoresHandler{Application: newStoreApplication(store.NewFakeStoreRepository())}
+
+	if assert.NoError(t, h.GetStore(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"error":"store not found","storeId":"corner"}`+"\n", rec.Body.String())
+	}
+}
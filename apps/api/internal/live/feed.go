@@ -0,0 +1,164 @@
+package live
+
+import (
+	"context"
+	"github.com/hallgren/eventsourcing/core"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Feed watches the event store and tells subscribers when new events have
+// been saved. Notifications carry no data: subscribers re-read whatever they
+// are interested in, so a slow subscriber only ever has one pending.
+type Feed struct {
+	es          *sqlStore.SQLite
+	lock        sync.Mutex
+	subscribers map[int]chan struct{}
+	next        int
+	position    core.Version
+	err         error
+}
+
+// maxBackoff caps how long the feed waits between polls while they keep
+// failing.
+const maxBackoff = 30 * time.Second
+
+type Health struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+func NewFeed(es *sqlStore.SQLite) *Feed {
+	return &Feed{es: es, subscribers: map[int]chan struct{}{}}
+}
+
+// Run polls the store at the given interval until the context is cancelled.
+// Events already in the store when it starts don't trigger a notification. A
+// failed poll is logged and retried, backing off while polls keep failing.
+func (f *Feed) Run(ctx context.Context, interval time.Duration) {
+	_, err := f.poll()
+	f.record(err)
+
+	failures := 0
+
+	for {
+		wait := interval
+
+		if err != nil {
+			failures++
+			wait = backoff(interval, failures)
+		} else {
+			failures = 0
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		var found bool
+		found, err = f.poll()
+		f.record(err)
+
+		if found {
+			f.Notify()
+		}
+	}
+}
+
+// Health reports whether the last poll of the store succeeded.
+func (f *Feed) Health() Health {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.err != nil {
+		return Health{Healthy: false, Error: f.err.Error()}
+	}
+
+	return Health{Healthy: true}
+}
+
+func (f *Feed) record(err error) {
+	if err != nil {
+		slog.Error("Polling events failed", "error", err)
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.err = err
+}
+
+// backoff doubles the interval for each failure in a row, up to maxBackoff.
+func backoff(interval time.Duration, failures int) time.Duration {
+	wait := interval
+
+	for i := 1; i < failures && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, maxBackoff)
+}
+
+// Subscribe returns a channel receiving a value whenever new events arrive,
+// and a function to stop the subscription.
+func (f *Feed) Subscribe() (<-chan struct{}, func()) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	id := f.next
+	f.next++
+
+	ch := make(chan struct{}, 1)
+	f.subscribers[id] = ch
+
+	return ch, func() {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		delete(f.subscribers, id)
+	}
+}
+
+func (f *Feed) Notify() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (f *Feed) poll() (bool, error) {
+	iterator, err := f.es.All(f.position + 1)()
+
+	if err != nil {
+		return false, err
+	}
+
+	defer iterator.Close()
+
+	found := false
+
+	for iterator.Next() {
+		ev, err := iterator.Value()
+
+		if err != nil {
+			return false, err
+		}
+
+		f.position = ev.GlobalVersion
+		found = true
+	}
+
+	return found, nil
+}
@@ -0,0 +1,130 @@
+package live_test
+
+import (
+	"context"
+	"database/sql"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNotifyingSubscribersOfNewEvents(t *testing.T) {
+	es, r := setup(t)
+	addProduct(t, r, "a")
+
+	feed := live.NewFeed(es)
+	first, unsubscribeFirst := feed.Subscribe()
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := feed.Subscribe()
+	defer unsubscribeSecond()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feed.Run(ctx, 10*time.Millisecond)
+
+	assertNotNotified(t, first)
+
+	addProduct(t, r, "b")
+
+	assertNotified(t, first)
+	assertNotified(t, second)
+}
+
+func TestStoppingSubscription(t *testing.T) {
+	es, _ := setup(t)
+
+	feed := live.NewFeed(es)
+	changes, unsubscribe := feed.Subscribe()
+	unsubscribe()
+
+	feed.Notify()
+
+	assertNotNotified(t, changes)
+}
+
+func TestCoalescingNotifications(t *testing.T) {
+	es, _ := setup(t)
+
+	feed := live.NewFeed(es)
+	changes, unsubscribe := feed.Subscribe()
+	defer unsubscribe()
+
+	feed.Notify()
+	feed.Notify()
+
+	assertNotified(t, changes)
+	assertNotNotified(t, changes)
+}
+
+func TestPollingAfterStoreFails(t *testing.T) {
+	db := createDatabase(t)
+	es, r := setupWithDatabase(t, db)
+
+	feed := live.NewFeed(es)
+	changes, unsubscribe := feed.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feed.Run(ctx, 10*time.Millisecond)
+
+	_, err := db.Exec(`ALTER TABLE events RENAME TO broken_events`)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return !feed.Health().Healthy }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, feed.Health().Error, "no such table")
+
+	_, err = db.Exec(`ALTER TABLE broken_events RENAME TO events`)
+	assert.NoError(t, err)
+
+	addProduct(t, r, "a")
+
+	assertNotified(t, changes)
+	assert.Equal(t, live.Health{Healthy: true}, feed.Health())
+}
+
+func setup(t *testing.T) (*sqlStore.SQLite, *product.EventSourcedProductRepository) {
+	return setupWithDatabase(t, createDatabase(t))
+}
+
+func createDatabase(t *testing.T) *sql.DB {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func setupWithDatabase(t *testing.T, db *sql.DB) (*sqlStore.SQLite, *product.EventSourcedProductRepository) {
+	es, err := sqlStore.NewSQLiteSingelWriter(db)
+	assert.NoError(t, err)
+
+	return es, product.NewProductRepository(es, nil)
+}
+
+func addProduct(t *testing.T, r *product.EventSourcedProductRepository, name product.ProductName) {
+	err := r.Add(product.NewProductBuilder().WithName(name).WithCategory(category.Bakery).Build())
+	assert.NoError(t, err)
+}
+
+func assertNotified(t *testing.T, changes <-chan struct{}) {
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("expected a notification")
+	}
+}
+
+func assertNotNotified(t *testing.T, changes <-chan struct{}) {
+	select {
+	case <-changes:
+		t.Fatal("unexpected notification")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
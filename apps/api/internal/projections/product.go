@@ -1,31 +1,72 @@
 package projections
 
 import (
+	"cmp"
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/core"
 	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"slices"
 )
 
 type ProductProjectionOutput map[category.CategoryName][]product.Product
 
 func CreateProductProjection(es *sqlStore.SQLite) (*eventsourcing.Projection, ProductProjectionOutput) {
 	prods := ProductProjectionOutput{}
+	products := map[string]*product.Product{}
+	order := map[string]int{}
+
+	// Groups keep products in the order they were created.
+	remove := func(p product.Product) {
+		group := slices.DeleteFunc(prods[p.Category], func(q product.Product) bool { return q.Id == p.Id })
+
+		if len(group) == 0 {
+			delete(prods, p.Category)
+		} else {
+			prods[p.Category] = group
+		}
+	}
+
+	insert := func(p product.Product) {
+		group := prods[p.Category]
+		i, _ := slices.BinarySearchFunc(group, order[p.Id], func(q product.Product, position int) int {
+			return cmp.Compare(order[q.Id], position)
+		})
+
+		prods[p.Category] = slices.Insert(group, i, p)
+	}
 
 	start := core.Version(0)
 
 	p := eventsourcing.NewProjection(func() (core.Iterator, error) {
 		return es.All(start)()
 	}, func(ev eventsourcing.Event) error {
-		switch event := ev.Data().(type) {
-		case *product.Created:
-			prod := product.Product{}
-			prod.Transition(ev)
-			prods[event.Category] = append(prods[event.Category], prod)
+		start = core.Version(ev.GlobalVersion() + 1)
+
+		if ev.AggregateType() != "Product" {
+			return nil
 		}
 
-		start = core.Version(ev.GlobalVersion() + 1)
+		prod, ok := products[ev.AggregateID()]
+		if !ok {
+			prod = &product.Product{}
+			products[ev.AggregateID()] = prod
+			order[ev.AggregateID()] = len(order)
+		}
+
+		// Products can move between categories or be archived, so the
+		// product is taken out of its group and put back where it now
+		// belongs.
+		if ok && !prod.Archived {
+			remove(*prod)
+		}
+
+		prod.Transition(ev)
+
+		if !prod.Archived {
+			insert(*prod)
+		}
 
 		return nil
 	})
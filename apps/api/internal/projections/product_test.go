@@ -9,6 +9,7 @@ import (
 	"github.com/joe-reed/meal-planner/apps/api/internal/projections"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"testing"
 )
 
 type ShoppingListSuite struct {
@@ -67,8 +68,65 @@ func (suite *ShoppingListSuite) TestProductProjection() {
 	assert.NotEmpty(suite.T(), output)
 
 	assert.Equal(suite.T(), 2, len(output[category.AlcoholicDrinks]))
-	assert.Equal(suite.T(), productA, output[category.AlcoholicDrinks][0])
-	assert.Equal(suite.T(), productB, output[category.AlcoholicDrinks][1])
+	assert.EqualExportedValues(suite.T(), *productA, output[category.AlcoholicDrinks][0])
+	assert.EqualExportedValues(suite.T(), *productB, output[category.AlcoholicDrinks][1])
 	assert.Equal(suite.T(), 1, len(output[category.Dairy]))
-	assert.Equal(suite.T(), productC, output[category.Dairy][0])
+	assert.EqualExportedValues(suite.T(), *productC, output[category.Dairy][0])
+}
+
+func (suite *ShoppingListSuite) TestProductProjectionFollowsProductChanges() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+	productB := suite.addProduct("ing-b", "Ing B", category.AlcoholicDrinks)
+	productC := suite.addProduct("ing-c", "Ing C", category.Dairy)
+
+	err := productA.Rename("Ing A2")
+	assert.NoError(suite.T(), err)
+	err = productA.ChangeCategory(category.Dairy)
+	assert.NoError(suite.T(), err)
+	err = suite.productRepository.Save(productA)
+	assert.NoError(suite.T(), err)
+
+	productB.Archive()
+	err = suite.productRepository.Save(productB)
+	assert.NoError(suite.T(), err)
+
+	p, output := projections.CreateProductProjection(suite.es)
+
+	result := p.RunToEnd(suite.T().Context())
+
+	assert.NoError(suite.T(), result.Error)
+	assert.Empty(suite.T(), output[category.AlcoholicDrinks])
+	assert.Len(suite.T(), output[category.Dairy], 2)
+	assert.EqualExportedValues(suite.T(), *productA, output[category.Dairy][0])
+	assert.Equal(suite.T(), product.ProductName("Ing A2"), output[category.Dairy][0].Name)
+	assert.EqualExportedValues(suite.T(), *productC, output[category.Dairy][1])
+}
+
+func (suite *ShoppingListSuite) TestProductProjectionKeepsCreationOrderWithinCategories() {
+	productA := suite.addProduct("ing-a", "Ing A", category.AlcoholicDrinks)
+	productB := suite.addProduct("ing-b", "Ing B", category.Dairy)
+	productC := suite.addProduct("ing-c", "Ing C", category.AlcoholicDrinks)
+
+	p, output := projections.CreateProductProjection(suite.es)
+
+	result := p.RunToEnd(suite.T().Context())
+	assert.NoError(suite.T(), result.Error)
+
+	err := productB.ChangeCategory(category.AlcoholicDrinks)
+	assert.NoError(suite.T(), err)
+	err = suite.productRepository.Save(productB)
+	assert.NoError(suite.T(), err)
+
+	result = p.RunToEnd(suite.T().Context())
+
+	assert.NoError(suite.T(), result.Error)
+	assert.NotContains(suite.T(), output, category.Dairy)
+	assert.Len(suite.T(), output[category.AlcoholicDrinks], 3)
+	assert.EqualExportedValues(suite.T(), *productA, output[category.AlcoholicDrinks][0])
+	assert.EqualExportedValues(suite.T(), *productB, output[category.AlcoholicDrinks][1])
+	assert.EqualExportedValues(suite.T(), *productC, output[category.AlcoholicDrinks][2])
+}
+
+func TestProductProjectionSuite(t *testing.T) {
+	suite.Run(t, new(ShoppingListSuite))
 }
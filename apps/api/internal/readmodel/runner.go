@@ -0,0 +1,130 @@
+package readmodel
+
+import (
+	"database/sql"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"sync"
+)
+
+const batchSize = 500
+
+var checkpointsTable = `CREATE TABLE IF NOT EXISTS read_model_checkpoints (
+	name     VARCHAR PRIMARY KEY,
+	position INTEGER NOT NULL
+);`
+
+// Handler applies a single event to a read model. It runs inside the same
+// transaction that moves the read model's checkpoint forward.
+type Handler func(tx *sql.Tx, ev eventsourcing.Event) error
+
+type Projection struct {
+	Name string
+	// Tables holds the statements creating the read model's tables. They are
+	// run on every start so must be idempotent.
+	Tables []string
+	Handle Handler
+}
+
+// Runner keeps a read model up to date with the event store. The position of
+// the last event applied is stored alongside the read model, so each run only
+// handles events saved since the previous one.
+type Runner struct {
+	db         *sql.DB
+	es         *sqlStore.SQLite
+	projection Projection
+	lock       sync.Mutex
+}
+
+func NewRunner(db *sql.DB, es *sqlStore.SQLite, projection Projection) (*Runner, error) {
+	statements := append([]string{checkpointsTable}, projection.Tables...)
+
+	for _, s := range statements {
+		if _, err := db.Exec(s); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err := db.Exec(`INSERT OR IGNORE INTO read_model_checkpoints (name, position) VALUES (?, 0)`, projection.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Runner{db: db, es: es, projection: projection}, nil
+}
+
+// CatchUp applies every event saved since the last checkpoint.
+func (r *Runner) CatchUp() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for {
+		handled, err := r.runBatch()
+
+		if err != nil {
+			return err
+		}
+
+		if handled < batchSize {
+			return nil
+		}
+	}
+}
+
+// Checkpoint returns the global version of the last event applied.
+func (r *Runner) Checkpoint() (core.Version, error) {
+	var position core.Version
+	err := r.db.QueryRow(`SELECT position FROM read_model_checkpoints WHERE name = ?`, r.projection.Name).Scan(&position)
+	return position, err
+}
+
+func (r *Runner) runBatch() (int, error) {
+	checkpoint, err := r.Checkpoint()
+
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := database.ReadBatch(r.es, checkpoint+1, batchSize)
+
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	last := events[len(events)-1].Position
+
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	// Another runner for the same read model may have got here first, in which
+	// case its work is kept and this batch is dropped.
+	result, err := tx.Exec(`UPDATE read_model_checkpoints SET position = ? WHERE name = ? AND position = ?`, last, r.projection.Name, checkpoint)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return 0, err
+	}
+
+	for _, ev := range events {
+		if ev.Event == nil {
+			continue
+		}
+
+		if err := r.projection.Handle(tx, *ev.Event); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(events), tx.Commit()
+}
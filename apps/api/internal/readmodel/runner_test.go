@@ -0,0 +1,165 @@
+package readmodel_test
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/readmodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type RunnerSuite struct {
+	suite.Suite
+	db                *sql.DB
+	es                *sqlStore.SQLite
+	productRepository *product.EventSourcedProductRepository
+	handled           []string
+}
+
+func (suite *RunnerSuite) SetupTest() {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(suite.T(), err)
+
+	suite.db = db
+	suite.es, err = sqlStore.NewSQLiteSingelWriter(db)
+	assert.NoError(suite.T(), err)
+
+	suite.productRepository = product.NewProductRepository(suite.es, nil)
+	suite.handled = []string{}
+}
+
+func (suite *RunnerSuite) TearDownTest() {
+	err := suite.db.Close()
+
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+}
+
+func (suite *RunnerSuite) TestCatchingUp() {
+	suite.addProduct("a")
+	suite.addProduct("b")
+
+	r := suite.createRunner(suite.recordProductIds)
+
+	err := r.CatchUp()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.handled)
+	suite.assertCheckpoint(r, 2)
+}
+
+func (suite *RunnerSuite) TestHandlingOnlyNewEvents() {
+	r := suite.createRunner(suite.recordProductIds)
+
+	suite.addProduct("a")
+	err := r.CatchUp()
+	assert.NoError(suite.T(), err)
+
+	suite.addProduct("b")
+	err = r.CatchUp()
+	assert.NoError(suite.T(), err)
+
+	err = r.CatchUp()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.handled)
+}
+
+func (suite *RunnerSuite) TestResumingFromCheckpoint() {
+	suite.addProduct("a")
+
+	err := suite.createRunner(suite.recordProductIds).CatchUp()
+	assert.NoError(suite.T(), err)
+
+	suite.addProduct("b")
+
+	r := suite.createRunner(suite.recordProductIds)
+	err = r.CatchUp()
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.handled)
+	suite.assertCheckpoint(r, 2)
+}
+
+func (suite *RunnerSuite) TestKeepingCheckpointWhenHandlerFails() {
+	suite.addProduct("a")
+	suite.addProduct("b")
+
+	r := suite.createRunner(func(tx *sql.Tx, ev eventsourcing.Event) error {
+		if ev.AggregateID() == "b" {
+			return errors.New("failed")
+		}
+		return suite.recordProductIds(tx, ev)
+	})
+
+	err := r.CatchUp()
+	assert.Error(suite.T(), err)
+
+	suite.assertCheckpoint(r, 0)
+
+	var count int
+	err = suite.db.QueryRow(`SELECT COUNT(*) FROM handled_products`).Scan(&count)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, count)
+}
+
+func (suite *RunnerSuite) TestCatchingUpOverManyBatches() {
+	for i := 0; i < 1200; i++ {
+		suite.addProduct(strconv.Itoa(i))
+	}
+
+	r := suite.createRunner(suite.recordProductIds)
+
+	err := r.CatchUp()
+	assert.NoError(suite.T(), err)
+
+	assert.Len(suite.T(), suite.handled, 1200)
+	suite.assertCheckpoint(r, 1200)
+}
+
+func (suite *RunnerSuite) createRunner(handle readmodel.Handler) *readmodel.Runner {
+	r, err := readmodel.NewRunner(suite.db, suite.es, readmodel.Projection{
+		Name:   "test",
+		Tables: []string{`CREATE TABLE IF NOT EXISTS handled_products (id VARCHAR PRIMARY KEY);`},
+		Handle: handle,
+	})
+	assert.NoError(suite.T(), err)
+
+	return r
+}
+
+func (suite *RunnerSuite) recordProductIds(tx *sql.Tx, ev eventsourcing.Event) error {
+	if _, err := tx.Exec(`INSERT INTO handled_products (id) VALUES (?)`, ev.AggregateID()); err != nil {
+		return err
+	}
+
+	suite.handled = append(suite.handled, ev.AggregateID())
+
+	return nil
+}
+
+func (suite *RunnerSuite) addProduct(id string) {
+	p, err := product.NewProduct(id, product.ProductName("Product "+id), category.Bakery)
+	assert.NoError(suite.T(), err)
+
+	err = suite.productRepository.Add(p)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *RunnerSuite) assertCheckpoint(r *readmodel.Runner, expected int) {
+	checkpoint, err := r.Checkpoint()
+	assert.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), expected, checkpoint)
+}
+
+func TestRunnerSuite(t *testing.T) {
+	suite.Run(t, new(RunnerSuite))
+}
@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"database/sql"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"os"
+	"strconv"
+)
+
+const DefaultInterval = 100
+
+// Snapshotter decides when an aggregate's state should be written to its
+// snapshot store. A snapshot is taken each time an aggregate's version passes
+// a multiple of Interval; an Interval of zero or less disables snapshots.
+type Snapshotter struct {
+	Store    core.SnapshotStore
+	Interval int
+}
+
+func NewSqliteSnapshotter(db *sql.DB) (*Snapshotter, error) {
+	store, err := NewSqliteStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	interval, err := Interval()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshotter{Store: store, Interval: interval}, nil
+}
+
+// Interval reads the snapshot interval from SNAPSHOT_INTERVAL, falling back
+// to DefaultInterval when it isn't set.
+func Interval() (int, error) {
+	v, ok := os.LookupEnv("SNAPSHOT_INTERVAL")
+
+	if !ok || v == "" {
+		return DefaultInterval, nil
+	}
+
+	return strconv.Atoi(v)
+}
+
+func (s *Snapshotter) Enabled() bool {
+	return s != nil && s.Interval > 0
+}
+
+// Due reports whether saving events moving an aggregate from one version to
+// another should be followed by a snapshot.
+func (s *Snapshotter) Due(from eventsourcing.Version, to eventsourcing.Version) bool {
+	if !s.Enabled() {
+		return false
+	}
+
+	return int(to)/s.Interval > int(from)/s.Interval
+}
+
+// WithInterval returns a copy of the snapshotter sharing its store.
+func (s *Snapshotter) WithInterval(interval int) *Snapshotter {
+	if s == nil {
+		return nil
+	}
+
+	return &Snapshotter{Store: s.Store, Interval: interval}
+}
@@ -0,0 +1,62 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing/core"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SqliteStore is a core.SnapshotStore keeping the latest snapshot of each
+// aggregate in the application's database.
+type SqliteStore struct {
+	db *sql.DB
+}
+
+func NewSqliteStore(db *sql.DB) (*SqliteStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS snapshots (
+		id             VARCHAR NOT NULL,
+		type           VARCHAR NOT NULL,
+		version        INTEGER NOT NULL,
+		global_version INTEGER NOT NULL,
+		state          BLOB,
+		PRIMARY KEY (id, type)
+	);`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &SqliteStore{db: db}, nil
+}
+
+func (s *SqliteStore) Save(snapshot core.Snapshot) error {
+	_, err := s.db.Exec(
+		`INSERT INTO snapshots (id, type, version, global_version, state) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id, type) DO UPDATE SET version = excluded.version, global_version = excluded.global_version, state = excluded.state`,
+		snapshot.ID, snapshot.Type, snapshot.Version, snapshot.GlobalVersion, snapshot.State,
+	)
+
+	return err
+}
+
+func (s *SqliteStore) Get(ctx context.Context, id string, aggregateType string) (core.Snapshot, error) {
+	snapshot := core.Snapshot{ID: id, Type: aggregateType}
+
+	err := s.db.QueryRowContext(
+		ctx,
+		`SELECT version, global_version, state FROM snapshots WHERE id = ? AND type = ?`,
+		id, aggregateType,
+	).Scan(&snapshot.Version, &snapshot.GlobalVersion, &snapshot.State)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Snapshot{}, core.ErrSnapshotNotFound
+	}
+
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,45 @@
+package snapshot_test
+
+import (
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestSqliteStore(t *testing.T) {
+	testsuite.TestSnapshotStore(t, func() (core.SnapshotStore, func(), error) {
+		db, err := database.CreateDatabase("test.db")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ss, err := snapshot.NewSqliteStore(db)
+
+		return ss, func() {
+			db.Close()
+			os.Remove("test.db")
+		}, err
+	})
+}
+
+func TestSnapshotDue(t *testing.T) {
+	s := &snapshot.Snapshotter{Interval: 3}
+
+	assert.False(t, s.Due(0, 2))
+	assert.True(t, s.Due(2, 3))
+	assert.True(t, s.Due(1, 7))
+	assert.False(t, s.Due(3, 5))
+	assert.True(t, s.Due(5, 6))
+}
+
+func TestDisabledSnapshots(t *testing.T) {
+	var s *snapshot.Snapshotter
+
+	assert.False(t, s.Due(0, 10))
+	assert.False(t, s.WithInterval(3).Enabled())
+	assert.False(t, (&snapshot.Snapshotter{Interval: 0}).Due(0, 10))
+}
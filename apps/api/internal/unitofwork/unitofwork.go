@@ -0,0 +1,181 @@
+package unitofwork
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"slices"
+	"time"
+)
+
+var ErrUnsupportedStore = errors.New("unit of work cannot save to this event store")
+
+// UnitOfWork saves the events of every aggregate changed by a command
+// together, so that a command failing part way through leaves nothing
+// behind.
+type UnitOfWork struct {
+	commit func(batches []batch) error
+}
+
+// Unit collects the events saved during a single command until it commits.
+// Repositories take part by saving through Store. Reads still go to the
+// underlying stores, so they only see events from earlier commands, and
+// aggregates saved in a unit have no global version until it commits.
+type Unit struct {
+	batches []batch
+	after   []func() error
+}
+
+type batch struct {
+	es     core.EventStore
+	events []core.Event
+}
+
+// NewSqliteUnitOfWork commits units in a single transaction on the database,
+// holding the same write lock as its event stores. Every store saved to must
+// be a database.EventStore on the same database.
+func NewSqliteUnitOfWork(db *sql.DB) *UnitOfWork {
+	lock := database.WriteLock(db)
+
+	return &UnitOfWork{commit: func(batches []batch) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return commitSqlite(db, batches)
+	}}
+}
+
+// NewFakeUnitOfWork commits units by saving to each store in turn. It's for
+// in-memory stores, which have no transactions to share.
+func NewFakeUnitOfWork() *UnitOfWork {
+	return &UnitOfWork{commit: func(batches []batch) error {
+		for _, b := range batches {
+			if err := b.es.Save(b.events); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}}
+}
+
+// Do runs fn with a new unit. The events saved in it are committed if fn
+// succeeds and thrown away if it returns an error.
+func (u *UnitOfWork) Do(fn func(unit *Unit) error) error {
+	unit := &Unit{}
+
+	if err := fn(unit); err != nil {
+		return err
+	}
+
+	if err := u.commit(unit.batches); err != nil {
+		if errors.Is(err, core.ErrConcurrency) {
+			return eventsourcing.ErrConcurrency
+		}
+
+		return err
+	}
+
+	for _, after := range unit.after {
+		if err := after(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Store returns an event store that saves to es as part of the unit.
+func (unit *Unit) Store(es core.EventStore) core.EventStore {
+	if s, ok := es.(*store); ok {
+		es = s.EventStore
+	}
+
+	return &store{EventStore: es, unit: unit}
+}
+
+// AfterCommit runs fn once events saved to es are committed. That's straight
+// away, unless es saves as part of a unit.
+func AfterCommit(es core.EventStore, fn func() error) error {
+	if s, ok := es.(*store); ok {
+		s.unit.after = append(s.unit.after, fn)
+		return nil
+	}
+
+	return fn()
+}
+
+type store struct {
+	core.EventStore
+	unit *Unit
+}
+
+func (s *store) Save(events []core.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.unit.batches = append(s.unit.batches, batch{es: s.EventStore, events: slices.Clone(events)})
+
+	return nil
+}
+
+// commitSqlite writes batches as the SQLite event store would, checking each
+// aggregate's version the same way, but within one transaction.
+func commitSqlite(db *sql.DB, batches []batch) error {
+	if len(batches) == 0 {
+		return nil
+	}
+
+	for _, b := range batches {
+		if _, ok := b.es.(*database.EventStore); !ok {
+			return ErrUnsupportedStore
+		}
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, b := range batches {
+		var version core.Version
+
+		err := tx.QueryRow(
+			`SELECT version FROM events WHERE id = ? AND type = ? ORDER BY version DESC LIMIT 1`,
+			b.events[0].AggregateID,
+			b.events[0].AggregateType,
+		).Scan(&version)
+
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if version+1 != b.events[0].Version {
+			return core.ErrConcurrency
+		}
+
+		for _, ev := range b.events {
+			_, err := tx.Exec(
+				`INSERT INTO events (id, version, reason, type, timestamp, data, metadata) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				ev.AggregateID,
+				ev.Version,
+				ev.Reason,
+				ev.AggregateType,
+				ev.Timestamp.Format(time.RFC3339),
+				ev.Data,
+				ev.Metadata,
+			)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
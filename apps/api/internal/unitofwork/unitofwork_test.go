@@ -0,0 +1,195 @@
+package unitofwork_test
+
+import (
+	"context"
+	"errors"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/joe-reed/meal-planner/apps/api/internal/database"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type repositories struct {
+	uow      *unitofwork.UnitOfWork
+	meals    *meal.EventSourcedMealRepository
+	products *product.EventSourcedProductRepository
+}
+
+func newSqliteRepositories(t *testing.T) repositories {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	meals, err := meal.NewSqliteMealRepository(db)
+	assert.NoError(t, err)
+
+	products, err := product.NewSqliteProductRepository(db)
+	assert.NoError(t, err)
+
+	return repositories{uow: unitofwork.NewSqliteUnitOfWork(db), meals: meals, products: products}
+}
+
+func newFakeRepositories(*testing.T) repositories {
+	return repositories{uow: unitofwork.NewFakeUnitOfWork(), meals: meal.NewFakeMealRepository(), products: product.NewFakeProductRepository()}
+}
+
+func TestCommittingUnit(t *testing.T) {
+	for name, factory := range map[string]func(*testing.T) repositories{"sqlite": newSqliteRepositories, "fake": newFakeRepositories} {
+		t.Run(name, func(t *testing.T) {
+			r := factory(t)
+
+			p := product.NewProductBuilder().WithId("a").WithName("Pasta").Build()
+			m := meal.NewMealBuilder().WithId("b").WithName("Pesto pasta").AddIngredient(*meal.NewIngredient("a")).Build()
+
+			err := r.uow.Do(func(unit *unitofwork.Unit) error {
+				if err := r.products.In(unit).Add(p); err != nil {
+					return err
+				}
+
+				if err := r.meals.In(unit).Save(m); err != nil {
+					return err
+				}
+
+				_, err := r.meals.Find("b")
+				assert.ErrorIs(t, err, eventsourcing.ErrAggregateNotFound)
+
+				return nil
+			})
+			assert.NoError(t, err)
+
+			found, err := r.products.Find("a")
+			assert.NoError(t, err)
+			assert.EqualExportedValues(t, p, found)
+
+			saved, err := r.meals.Find("b")
+			assert.NoError(t, err)
+			assert.EqualExportedValues(t, m, saved)
+		})
+	}
+}
+
+func TestDiscardingUnitWhenCommandFails(t *testing.T) {
+	for name, factory := range map[string]func(*testing.T) repositories{"sqlite": newSqliteRepositories, "fake": newFakeRepositories} {
+		t.Run(name, func(t *testing.T) {
+			r := factory(t)
+			failed := errors.New("failed")
+
+			err := r.uow.Do(func(unit *unitofwork.Unit) error {
+				if err := r.meals.In(unit).Save(meal.NewMealBuilder().WithId("a").WithName("a").Build()); err != nil {
+					return err
+				}
+
+				return failed
+			})
+			assert.ErrorIs(t, err, failed)
+
+			meals, err := r.meals.Get()
+			assert.NoError(t, err)
+			assert.Empty(t, meals)
+		})
+	}
+}
+
+func TestRollingBackUnitWhenAggregateChangedConcurrently(t *testing.T) {
+	r := newSqliteRepositories(t)
+
+	p := product.NewProductBuilder().WithId("a").WithName("Pasta").Build()
+	assert.NoError(t, r.products.Add(p))
+
+	stale, err := r.products.Find("a")
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Rename("Spaghetti"))
+	assert.NoError(t, r.products.Save(p))
+
+	err = r.uow.Do(func(unit *unitofwork.Unit) error {
+		if err := r.meals.In(unit).Save(meal.NewMealBuilder().WithId("b").WithName("b").Build()); err != nil {
+			return err
+		}
+
+		if err := stale.Rename("Penne"); err != nil {
+			return err
+		}
+
+		return r.products.In(unit).Save(stale)
+	})
+	assert.ErrorIs(t, err, eventsourcing.ErrConcurrency)
+
+	_, err = r.meals.Find("b")
+	assert.ErrorIs(t, err, eventsourcing.ErrAggregateNotFound)
+
+	found, err := r.products.Find("a")
+	assert.NoError(t, err)
+	assert.Equal(t, product.ProductName("Spaghetti"), found.Name)
+}
+
+func TestSnapshottingAfterUnitCommits(t *testing.T) {
+	r := newSqliteRepositories(t)
+	meals := r.meals.WithSnapshotInterval(2)
+
+	m := meal.NewMealBuilder().WithId("a").WithName("a").Build()
+	m.UpdateServings(2)
+	m.UpdateServings(4)
+
+	err := r.uow.Do(func(unit *unitofwork.Unit) error {
+		return meals.In(unit).Save(m)
+	})
+	assert.NoError(t, err)
+
+	snapshotted := &meal.Meal{}
+	err = aggregate.LoadSnapshot(context.Background(), meals.SnapshotStore(), "a", snapshotted)
+	assert.NoError(t, err)
+	assert.Equal(t, eventsourcing.Version(3), snapshotted.Version())
+
+	found, err := meals.Find("a")
+	assert.NoError(t, err)
+
+	replayed, err := meals.WithSnapshotInterval(0).Find("a")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, found.Servings)
+	assert.EqualExportedValues(t, replayed, found)
+	assert.Equal(t, replayed.GlobalVersion(), found.GlobalVersion())
+}
+
+func TestWaitingForDatabaseWriteLock(t *testing.T) {
+	db, err := database.CreateDatabase(":memory:")
+	assert.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	products, err := product.NewSqliteProductRepository(db)
+	assert.NoError(t, err)
+
+	lock := database.WriteLock(db)
+	lock.Lock()
+
+	committed := make(chan error)
+	go func() {
+		committed <- unitofwork.NewSqliteUnitOfWork(db).Do(func(unit *unitofwork.Unit) error {
+			return products.In(unit).Add(product.NewProductBuilder().WithId("a").WithName("Pasta").Build())
+		})
+	}()
+
+	saved := make(chan error)
+	go func() {
+		saved <- products.Add(product.NewProductBuilder().WithId("b").WithName("Penne").Build())
+	}()
+
+	select {
+	case <-committed:
+		t.Fatal("unit committed while the database was locked")
+	case <-saved:
+		t.Fatal("store saved while the database was locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	lock.Unlock()
+
+	assert.NoError(t, <-committed)
+	assert.NoError(t, <-saved)
+}
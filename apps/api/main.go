@@ -5,21 +5,24 @@ import (
 	"database/sql"
 	sqlStore "github.com/hallgren/eventsourcing/eventstore/sql"
 	"github.com/joe-reed/meal-planner/apps/api/internal/application"
+	"github.com/joe-reed/meal-planner/apps/api/internal/bus"
 	"github.com/joe-reed/meal-planner/apps/api/internal/database"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/basket"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/category"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/meal"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/pantry"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/product"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/recipe"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shop"
 	"github.com/joe-reed/meal-planner/apps/api/internal/domain/shoppinglist"
+	"github.com/joe-reed/meal-planner/apps/api/internal/domain/store"
 	"github.com/joe-reed/meal-planner/apps/api/internal/handlers"
+	"github.com/joe-reed/meal-planner/apps/api/internal/live"
+	"github.com/joe-reed/meal-planner/apps/api/internal/unitofwork"
 	"github.com/labstack/echo/v4"
-	"strconv"
-	"strings"
+	"time"
 )
 
-type EventSubscriber func(EventPublisher)
-type EventPublisher func(string)
-
 func main() {
 	e := echo.New()
 
@@ -36,113 +39,100 @@ func main() {
 		e.Logger.Fatal(err)
 	}
 
-	publisher, subscribe := setupEvents()
-
-	addMealRoutes(e, db)
-	addUploadRoutes(e, db)
-	addShopRoutes(e, db, publisher)
-	addCategoryRoutes(e)
-	addBasketRoutes(e, db, subscribe)
-	addProductRoutes(e, db, es)
+	events, err := bus.NewBus(db, es)
 
 	if err != nil {
 		e.Logger.Fatal(err)
 	}
 
-	p, output := shoppinglist.CreateShoppingListProjection(es)
-
-	result := p.RunToEnd(context.TODO())
-
-	if result.Error != nil {
-		e.Logger.Fatal(result.Error)
-	}
-
-	e.GET("/shopping-list", func(c echo.Context) error {
-		result = p.RunToEnd(context.TODO())
-		if result.Error != nil {
-			e.Logger.Fatal(result.Error)
-		}
+	uow := unitofwork.NewSqliteUnitOfWork(db)
 
-		return c.JSON(200, output)
-	})
+	shoppingListService := shoppinglist.NewService(es)
 
-	e.Debug = true
-	e.Logger.Fatal(e.Start(":1323"))
-}
+	feed := live.NewFeed(es)
 
-func setupEvents() (EventPublisher, EventSubscriber) {
-	messageChannel := make(chan string)
+	go feed.Run(context.Background(), 500*time.Millisecond)
 
-	return getPublisher(messageChannel), getSubscribe(messageChannel)
-}
+	shoppingList := func() (map[string]shoppinglist.ShoppingListItem, error) {
+		l, err := shoppingListService.Current()
+		if err != nil {
+			return nil, err
+		}
 
-func getPublisher(messageChannel chan string) EventPublisher {
-	return func(message string) {
-		messageChannel <- message
+		return l.ShoppingList, nil
 	}
-}
 
-func getSubscribe(messageChannel chan string) EventSubscriber {
-	return func(f EventPublisher) {
-		go func() {
-			for {
-				f(<-messageChannel)
-			}
-		}()
+	addMealRoutes(e, db, uow)
+	addUploadRoutes(e, db, uow)
+	addRecipeRoutes(e, db)
+	addShopRoutes(e, db, es, feed, shoppingList, uow)
+	addCategoryRoutes(e, db)
+	addStoreRoutes(e, db)
+	addBasketRoutes(e, db, feed, events)
+	addProductRoutes(e, db, es, uow)
+	addPantryRoutes(e, db)
+	addShoppingListRoutes(e, db, shoppingListService, feed)
+	addHealthRoutes(e, shoppingListService, feed, events)
+
+	if _, err := shoppingListService.Current(); err != nil {
+		e.Logger.Error(err)
 	}
+
+	go events.Run(context.Background(), 250*time.Millisecond)
+
+	e.Debug = true
+	e.Logger.Fatal(e.Start(":1323"))
 }
 
-func addBasketRoutes(e *echo.Echo, db *sql.DB, subscribe EventSubscriber) {
+func addBasketRoutes(e *echo.Echo, db *sql.DB, feed *live.Feed, events *bus.Bus) {
 	r, err := basket.NewSqliteBasketRepository(db)
 
 	if err != nil {
 		e.Logger.Fatal(e)
 	}
 
-	handler := handlers.BasketHandler{Application: application.NewBasketApplication(r)}
+	handler := handlers.BasketHandler{Application: application.NewBasketApplication(r), Feed: feed}
 
-	subscribe(func(message string) {
-		parts := strings.Split(message, ":")
+	err = events.Subscribe("baskets", handler.Application.HandleShopEvent)
 
-		if parts[0] == "shopStarted" {
-			e.Logger.Debug("Creating basket for shop " + parts[1])
-			shopId, _ := strconv.Atoi(parts[1])
-			b, err := basket.NewBasket(shopId)
-			if err != nil {
-				e.Logger.Error(err)
-			}
-			err = r.Save(b)
-			if err != nil {
-				e.Logger.Error(err)
-			}
-		}
-	})
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
 
 	e.GET("/baskets/:shopId", handler.GetBasket)
+	e.GET("/baskets/:shopId/stream", handler.StreamBasket)
 	e.POST("/baskets/:shopId/items", handler.AddItemToBasket)
 	e.DELETE("/baskets/:shopId/items/:ingredientId", handler.RemoveItemFromBasket)
 }
 
-func addMealRoutes(e *echo.Echo, db *sql.DB) {
+func addMealRoutes(e *echo.Echo, db *sql.DB, uow *unitofwork.UnitOfWork) {
 	mealRepo, err := meal.NewSqliteMealRepository(db)
 
 	if err != nil {
 		e.Logger.Fatal(e)
 	}
 
+	productRepo, err := product.NewSqliteProductRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(e)
+	}
+
 	handler := handlers.MealsHandler{
-		Application: application.NewMealApplication(mealRepo),
+		Application: application.NewMealApplication(mealRepo, productRepo, uow),
 	}
 
 	e.GET("/meals", handler.GetMeals)
 	e.GET("/meals/:id", handler.FindMeal)
 	e.POST("/meals", handler.AddMeal)
 	e.POST("/meals/:mealId/ingredients", handler.AddIngredientToMeal)
+	e.PATCH("/meals/:mealId/ingredients/:ingredientId", handler.UpdateIngredient)
 	e.DELETE("/meals/:mealId/ingredients/:ingredientId", handler.RemoveIngredientFromMeal)
 	e.PATCH("/meals/:mealId", handler.UpdateMeal)
+	e.DELETE("/meals/:id", handler.ArchiveMeal)
 }
 
-func addUploadRoutes(e *echo.Echo, db *sql.DB) {
+func addUploadRoutes(e *echo.Echo, db *sql.DB, uow *unitofwork.UnitOfWork) {
 	mealRepo, err := meal.NewSqliteMealRepository(db)
 
 	if err != nil {
@@ -155,47 +145,247 @@ func addUploadRoutes(e *echo.Echo, db *sql.DB) {
 		e.Logger.Fatal(e)
 	}
 
+	categoryRepo, err := category.NewSqliteCategoryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(e)
+	}
+
 	handler := handlers.UploadHandler{
-		Application: application.NewUploadMealsApplication(productRepo, mealRepo),
+		Application: application.NewUploadMealsApplication(productRepo, mealRepo, categoryRepo, uow),
 	}
 
 	e.POST("/meals/upload", handler.UploadMeals)
+
+	exportHandler := handlers.ExportHandler{
+		Application: application.NewExportMealsApplication(productRepo, mealRepo),
+	}
+
+	e.GET("/meals/export", exportHandler.ExportMeals)
 }
 
-func addShopRoutes(e *echo.Echo, db *sql.DB, publisher EventPublisher) {
+func addRecipeRoutes(e *echo.Echo, db *sql.DB) {
+	productRepo, err := product.NewSqliteProductRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(e)
+	}
+
+	handler := handlers.RecipesHandler{
+		Application: application.NewRecipeApplication(productRepo, recipe.NewHttpFetcher()),
+	}
+
+	e.POST("/meals/import", handler.ImportRecipe)
+}
+
+func addShopRoutes(e *echo.Echo, db *sql.DB, es *sqlStore.SQLite, feed *live.Feed, shoppingList func() (map[string]shoppinglist.ShoppingListItem, error), uow *unitofwork.UnitOfWork) {
 	r, err := shop.NewSqliteShopRepository(db)
 
 	if err != nil {
 		e.Logger.Fatal(e)
 	}
 
-	handler := handlers.ShopsHandler{Application: application.NewShopApplication(r, publisher)}
+	meals, err := meal.NewSqliteMealRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	stores, err := store.NewSqliteStoreRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
 
+	categories, err := category.NewSqliteCategoryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	baskets, err := basket.NewSqliteBasketRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	handler := handlers.ShopsHandler{
+		Application:  application.NewShopApplication(r, meals, stores, baskets, uow),
+		ShoppingList: shoppingList,
+		ShopShoppingList: func(shopId int) (shoppinglist.ShoppingListProjectionOutput, error) {
+			p, output := shoppinglist.CreateShopShoppingListProjection(es, shopId)
+
+			result := p.RunToEnd(context.TODO())
+			if result.Error != nil {
+				return shoppinglist.ShoppingListProjectionOutput{}, result.Error
+			}
+
+			return output, nil
+		},
+		Feed:       feed,
+		Categories: application.NewCategoryApplication(categories),
+		Stores:     newStoreApplication(e, db, categories),
+	}
+
+	e.GET("/shops", handler.GetShops)
 	e.GET("/shops/current", handler.CurrentShop)
+	e.GET("/shops/current/stream", handler.StreamCurrentShop)
+	e.GET("/shops/current/week", handler.CurrentShopWeek)
 	e.POST("/shops/current/meals", handler.AddMealToCurrentShop)
 	e.DELETE("/shops/current/meals/:mealId", handler.RemoveMealFromCurrentShop)
+	e.PUT("/shops/current/meals/:mealId/schedule", handler.ScheduleMealInCurrentShop)
+	e.DELETE("/shops/current/meals/:mealId/schedule", handler.UnscheduleMealInCurrentShop)
 	e.POST("/shops", handler.StartShop)
+	e.POST("/shops/current/complete", handler.CompleteCurrentShop)
 	e.POST("/shops/current/items", handler.AddItemToCurrentShop)
 	e.DELETE("/shops/current/items/:productId", handler.RemoveItemFromCurrentShop)
+	e.GET("/shops/:id", handler.GetShop)
+	e.GET("/shops/:id/shopping-list", handler.GetShopShoppingList)
 }
 
-func addProductRoutes(e *echo.Echo, db *sql.DB, es *sqlStore.SQLite) {
+func addProductRoutes(e *echo.Echo, db *sql.DB, es *sqlStore.SQLite, uow *unitofwork.UnitOfWork) {
 	r, err := product.NewSqliteProductRepository(db)
 
 	if err != nil {
 		e.Logger.Fatal(e)
 	}
 
-	handler := handlers.ProductHandler{Application: application.NewProductApplication(r), EventStore: es}
+	categories, err := category.NewSqliteCategoryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	meals, err := meal.NewSqliteMealRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	shops, err := shop.NewSqliteShopRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	baskets, err := basket.NewSqliteBasketRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	pantries, err := pantry.NewSqlitePantryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	handler := handlers.ProductHandler{
+		Application: application.NewProductApplication(r, categories),
+		Merge:       application.NewMergeProductsApplication(r, meals, shops, baskets, pantries, uow),
+		EventStore:  es,
+	}
 
 	e.GET("/products", handler.GetProducts)
 	e.POST("/products", handler.AddProduct)
+	e.PATCH("/products/:id", handler.UpdateProduct)
+	e.DELETE("/products/:id", handler.ArchiveProduct)
+	e.POST("/products/:id/merge", handler.MergeProduct)
+}
+
+func addPantryRoutes(e *echo.Echo, db *sql.DB) {
+	r, err := pantry.NewSqlitePantryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(e)
+	}
+
+	handler := handlers.PantryHandler{Application: application.NewPantryApplication(r)}
+
+	e.GET("/pantry", handler.GetPantry)
+	e.PUT("/pantry/items/:productId", handler.SetStock)
+	e.POST("/pantry/items/:productId/adjustments", handler.AdjustStock)
+	e.DELETE("/pantry/items/:productId", handler.ClearStock)
+}
+
+func addShoppingListRoutes(e *echo.Echo, db *sql.DB, service *shoppinglist.Service, feed *live.Feed) {
+	categories, err := category.NewSqliteCategoryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	handler := handlers.ShoppingListHandler{
+		Service:    service,
+		Feed:       feed,
+		Categories: application.NewCategoryApplication(categories),
+		Stores:     newStoreApplication(e, db, categories),
+	}
+
+	e.GET("/shopping-list", handler.GetShoppingList)
+	e.GET("/shopping-list/stream", handler.StreamShoppingList)
+}
+
+func addHealthRoutes(e *echo.Echo, shoppingList *shoppinglist.Service, feed *live.Feed, events *bus.Bus) {
+	handler := handlers.HealthHandler{ShoppingList: shoppingList, Feed: feed, Events: events}
+
+	e.GET("/health", handler.GetHealth)
 }
 
-func addCategoryRoutes(e *echo.Echo) {
+func addCategoryRoutes(e *echo.Echo, db *sql.DB) {
+	r, err := category.NewSqliteCategoryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
 	handler := handlers.CategoriesHandler{
-		Application: application.NewCategoryApplication(),
+		Application: application.NewCategoryApplication(r),
 	}
 
 	e.GET("/categories", handler.GetCategories)
+	e.POST("/categories", handler.AddCategory)
+	e.PATCH("/categories/:id", handler.RenameCategory)
+	e.PUT("/categories/order", handler.ReorderCategories)
+}
+
+func addStoreRoutes(e *echo.Echo, db *sql.DB) {
+	categories, err := category.NewSqliteCategoryRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	handler := handlers.StoresHandler{
+		Application: newStoreApplication(e, db, categories),
+	}
+
+	e.GET("/stores", handler.GetStores)
+	e.POST("/stores", handler.AddStore)
+	e.GET("/stores/:id", handler.GetStore)
+	e.PATCH("/stores/:id", handler.UpdateStore)
+	e.PUT("/stores/:id/locations/:productId", handler.LocateProduct)
+	e.DELETE("/stores/:id/locations/:productId", handler.ClearProductLocation)
+}
+
+func newStoreApplication(e *echo.Echo, db *sql.DB, categories *category.CategoryRepository) *application.StoreApplication {
+	r, err := store.NewSqliteStoreRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	products, err := product.NewSqliteProductRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	shops, err := shop.NewSqliteShopRepository(db)
+
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	return application.NewStoreApplication(r, categories, products, shops)
 }
@@ -74,6 +74,57 @@ export async function createProduct(body: string) {
   return response.json();
 }
 
+export async function updateProduct(productId: string, body: string) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/products/${productId}`,
+    {
+      method: "PATCH",
+      headers,
+      body,
+    },
+  );
+
+  if (!response.ok) {
+    const message = await response.json();
+
+    return { error: message.error, product: null };
+  }
+
+  return { error: null, product: await response.json() };
+}
+
+export async function archiveProduct(productId: string) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/products/${productId}`,
+    { method: "DELETE" },
+  );
+
+  if (!response.ok) {
+    throw new Error("Error archiving product");
+  }
+
+  return response.json();
+}
+
+export async function mergeProduct(productId: string, into: string) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/products/${productId}/merge`,
+    {
+      method: "POST",
+      headers,
+      body: JSON.stringify({ into }),
+    },
+  );
+
+  if (!response.ok) {
+    const message = await response.json();
+
+    return { error: message.error, product: null };
+  }
+
+  return { error: null, product: await response.json() };
+}
+
 export async function createMeal(body: string) {
   const response = await fetch(`${process.env.API_BASE_URL}/meals`, {
     method: "POST",
@@ -102,6 +153,33 @@ export async function updateMeal(mealId: string, body: string) {
   return { error: null, meal: await response.json() };
 }
 
+export async function importRecipe(url: string) {
+  const response = await fetch(`${process.env.API_BASE_URL}/meals/import`, {
+    method: "POST",
+    body: new URLSearchParams({ url }),
+  });
+
+  if (!response.ok) {
+    const message = await response.json();
+
+    return { error: message.error, proposal: null };
+  }
+
+  return { error: null, proposal: await response.json() };
+}
+
+export async function archiveMeal(mealId: string) {
+  const response = await fetch(`${process.env.API_BASE_URL}/meals/${mealId}`, {
+    method: "DELETE",
+  });
+
+  if (!response.ok) {
+    throw new Error("Error archiving meal");
+  }
+
+  return response.json();
+}
+
 export async function fetchCurrentShop() {
   const response = await fetch(`${process.env.API_BASE_URL}/shops/current`);
   if (!response.ok) {
@@ -110,6 +188,34 @@ export async function fetchCurrentShop() {
   return response.json();
 }
 
+export async function fetchShops(page = 1) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/shops?page=${page}`,
+  );
+  if (!response.ok) {
+    throw new Error("Error fetching shops");
+  }
+  return response.json();
+}
+
+export async function fetchShop(id: string) {
+  const response = await fetch(`${process.env.API_BASE_URL}/shops/${id}`);
+  if (!response.ok) {
+    throw new Error("Error fetching shop");
+  }
+  return response.json();
+}
+
+export async function fetchShopShoppingList(id: string) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/shops/${id}/shopping-list`,
+  );
+  if (!response.ok) {
+    throw new Error("Error fetching shopping list");
+  }
+  return response.json();
+}
+
 export async function fetchProducts() {
   const response = await fetch(`${process.env.API_BASE_URL}/products`);
   if (!response.ok) {
@@ -136,8 +242,16 @@ export async function fetchMeal(mealId: string) {
   return response.json();
 }
 
-export async function fetchMeals() {
-  const response = await fetch(`${process.env.API_BASE_URL}/meals`);
+export async function fetchMeals(filter?: {
+  tags?: string[];
+  maxTime?: number;
+}) {
+  const params = new URLSearchParams();
+  filter?.tags?.forEach((tag) => params.append("tag", tag));
+  if (filter?.maxTime) {
+    params.set("maxTime", String(filter.maxTime));
+  }
+  const response = await fetch(`${process.env.API_BASE_URL}/meals?${params}`);
   if (!response.ok) {
     throw new Error("Error fetching meals");
   }
@@ -152,6 +266,25 @@ export async function fetchShoppingList() {
   return response.json();
 }
 
+export async function updateIngredient(
+  mealId: string,
+  ingredientId: string,
+  body: string,
+) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/meals/${mealId}/ingredients/${ingredientId}`,
+    { method: "PATCH", headers, body },
+  );
+
+  if (!response.ok) {
+    const message = await response.json();
+
+    return { error: message.error, meal: null };
+  }
+
+  return { error: null, meal: await response.json() };
+}
+
 export async function removeIngredientFromMeal(
   mealId: string,
   ingredientId: string,
@@ -182,10 +315,82 @@ export async function removeMealFromCurrentShop(mealId: string) {
   return response.json();
 }
 
-export async function startShop() {
+export async function startShop(storeId?: string) {
   const response = await fetch(`${process.env.API_BASE_URL}/shops`, {
     method: "POST",
     headers,
+    body: JSON.stringify({ storeId }),
   });
   return response.json();
 }
+
+export async function fetchStores() {
+  const response = await fetch(`${process.env.API_BASE_URL}/stores`);
+  if (!response.ok) {
+    throw new Error("Error fetching stores");
+  }
+  return response.json();
+}
+
+export async function createStore(body: string) {
+  const response = await fetch(`${process.env.API_BASE_URL}/stores`, {
+    method: "POST",
+    headers,
+    body,
+  });
+  if (!response.ok) {
+    const message = await response.json();
+
+    return { error: message.error, store: null };
+  }
+
+  return { error: null, store: await response.json() };
+}
+
+export async function updateStore(storeId: string, body: string) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/stores/${storeId}`,
+    {
+      method: "PATCH",
+      headers,
+      body,
+    },
+  );
+
+  if (!response.ok) {
+    const message = await response.json();
+
+    return { error: message.error, store: null };
+  }
+
+  return { error: null, store: await response.json() };
+}
+
+export async function locateProductInStore(
+  storeId: string,
+  productId: string,
+  category: string | null,
+) {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/stores/${storeId}/locations/${productId}`,
+    category === null
+      ? { method: "DELETE", headers }
+      : { method: "PUT", headers, body: JSON.stringify({ category }) },
+  );
+
+  if (!response.ok) {
+    const message = await response.json();
+
+    return { error: message.error, store: null };
+  }
+
+  return { error: null, store: await response.json() };
+}
+
+export async function completeCurrentShop() {
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/shops/current/complete`,
+    { method: "POST", headers },
+  );
+  return response.json();
+}
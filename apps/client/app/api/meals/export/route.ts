@@ -0,0 +1,14 @@
+import { NextRequest, NextResponse } from "next/server";
+
+export async function GET(req: NextRequest) {
+  const format = req.nextUrl.searchParams.get("format") ?? "csv";
+
+  const response = await fetch(
+    `${process.env.API_BASE_URL}/meals/export?format=${format}`,
+  );
+
+  return new NextResponse(response.body, {
+    status: response.status,
+    headers: response.headers,
+  });
+}
@@ -56,15 +56,23 @@ export default function MealPage() {
       )}
       <ul className="mb-6">
         {meal.ingredients.map((ingredient) => (
-          <li key={ingredient.id} className="flex justify-between md:w-1/2">
-            <span>{products.find((i) => i.id === ingredient.id)?.name}</span>
+          <li
+            key={ingredient.ingredientId}
+            className="flex justify-between md:w-1/2"
+          >
+            <span>
+              {products.find((i) => i.id === ingredient.id)?.name}
+              {ingredient.note && (
+                <span className="text-gray-500">, {ingredient.note}</span>
+              )}
+            </span>
             <span>
               <span>
                 {ingredient.quantity.amount}
                 <Unit quantity={ingredient.quantity} />
               </span>
               <button
-                onClick={() => removeIngredientFromMeal(ingredient.id)}
+                onClick={() => removeIngredientFromMeal(ingredient.ingredientId)}
                 className="ml-2 text-red-500"
               >
                 ❌
@@ -78,7 +86,7 @@ export default function MealPage() {
           addIngredientToMeal({ id: productId, quantity });
         }}
         products={products}
-        productIdsToExclude={meal.ingredients.map(({ id }) => id)}
+        productIdsToExclude={[]}
         className="w-full md:w-2/3"
       />
     </div>
@@ -7,13 +7,17 @@ import React, { useState } from "react";
 import { Select } from "@headlessui/react";
 import { useCategories } from "../../../queries/useCategories";
 import clsx from "clsx";
+import { UploadMode, UploadRowError } from "../../../types";
 
 export default function UploadMealsPage() {
   const { push } = useRouter();
   const { mutateAsync } = useUploadMeals();
   const [notFoundIngredients, setNotFoundIngredients] = useState<string[]>([]);
+  const [rowErrors, setRowErrors] = useState<UploadRowError[]>([]);
 
   const [file, setFile] = useState<File | null>(null);
+  const [mode, setMode] = useState<UploadMode>("create");
+  const [autoCreateProducts, setAutoCreateProducts] = useState(false);
 
   function handleFileChange(e: React.ChangeEvent<HTMLInputElement>) {
     if (e.target.files) {
@@ -23,14 +27,19 @@ export default function UploadMealsPage() {
 
   async function handleUpload() {
     if (file) {
-      const { status, error } = await mutateAsync(file);
+      const { status, error } = await mutateAsync({
+        meals: file,
+        mode,
+        autoCreateProducts,
+      });
 
       if (!error) {
         return push("/");
       }
 
       if (status === 400) {
-        setNotFoundIngredients(error.data.notFoundIngredients);
+        setNotFoundIngredients(error.data.notFoundIngredients ?? []);
+        setRowErrors(error.data.errors ?? []);
       }
     }
   }
@@ -42,6 +51,21 @@ export default function UploadMealsPage() {
         <h1 className="text-lg font-bold">Upload meals</h1>
       </div>
 
+      {rowErrors.length > 0 && (
+        <div className="mb-4 bg-red-100 p-4">
+          <h2 className="font-bold text-red-800">Problems with your file</h2>
+          <ul>
+            {rowErrors.map((e) => (
+              <li key={`${e.row}-${e.column}`}>
+                Row {e.row}
+                {e.column && `, ${e.column}`}
+                {e.value && ` "${e.value}"`}: {e.reason}
+              </li>
+            ))}
+          </ul>
+        </div>
+      )}
+
       {notFoundIngredients.length > 0 && (
         <div className="mb-4 bg-red-100 p-4">
           <h2 className="font-bold text-red-800">Ingredients not found</h2>
@@ -67,6 +91,27 @@ export default function UploadMealsPage() {
         className="file-button file:mr-2"
       />
 
+      <Select
+        value={mode}
+        onChange={(e) => setMode(e.target.value as UploadMode)}
+        className="mr-2"
+        aria-label="When a meal already exists"
+      >
+        <option value="create">Stop if a meal already exists</option>
+        <option value="update">Update existing meals</option>
+        <option value="skip">Skip existing meals</option>
+      </Select>
+
+      <label className="mr-2">
+        <input
+          type="checkbox"
+          checked={autoCreateProducts}
+          onChange={(e) => setAutoCreateProducts(e.target.checked)}
+          className="mr-1"
+        />
+        Create missing products from the category column
+      </label>
+
       {file && (
         <button onClick={handleUpload} className="button">
           Upload
@@ -133,7 +178,7 @@ function AddIngredientForm({
           >
             <option value="">Select a category</option>
             {categories?.map((category) => (
-              <option key={category.name} value={category.name}>
+              <option key={category.id} value={category.id}>
                 {category.name}
               </option>
             ))}
@@ -49,6 +49,10 @@ export default function HomePage() {
             📤 Upload meals
           </Link>
 
+          <a href="/api/meals/export" className="button w-1/2 text-center">
+            📥 Export meals
+          </a>
+
           <Link href="/products" className="button w-1/2 text-center">
             🛍️ Products
           </Link>
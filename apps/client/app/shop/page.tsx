@@ -1,17 +1,19 @@
 "use client";
 
-import { Product } from "../../types";
+import { Product, Quantity } from "../../types";
 import React from "react";
 import BackButton from "../../components/BackButton";
 import clsx from "clsx";
 import { useAddItemToBasket } from "../../queries/useAddItemToBasket";
 import { useRemoveItemFromBasket } from "../../queries/useRemoveItemFromBasket";
 import { useShoppingList } from "../../queries/useShoppingList";
+import { useCategories } from "../../queries/useCategories";
 import { Popover, PopoverButton, PopoverPanel } from "@headlessui/react";
 import { Unit } from "../../components/Unit";
 
 export default function ShopPage() {
   const shoppingListQuery = useShoppingList();
+  const { data: categories } = useCategories();
 
   const [showItemsInBasket, setShowItemsInBasket] = React.useState(false);
 
@@ -97,8 +99,17 @@ export default function ShopPage() {
 
   const shoppingList = Object.values(shoppingListData);
 
+  const aisles = categories ?? [];
+  const aisleOf = (category: string) => {
+    const position = aisles.findIndex(({ id }) => id === category);
+    return position === -1 ? aisles.length : position;
+  };
+  const categoryName = (category: string) =>
+    aisles.find(({ id }) => id === category)?.name ?? category;
+
   const filteredIngredients = shoppingList.filter(
-    (ingredient) => showItemsInBasket || !ingredient.isInBasket,
+    (ingredient) =>
+      showItemsInBasket || (!ingredient.isInBasket && !ingredient.covered),
   );
 
   const categorisedIngredients = Object.groupBy<
@@ -106,7 +117,8 @@ export default function ShopPage() {
     Product & {
       mealCount: number;
       isInBasket: boolean;
-      quantities: { unit: string; amount: number }[];
+      covered?: boolean;
+      quantities: Quantity[];
     }
   >(filteredIngredients, ({ category }) => category);
 
@@ -151,10 +163,12 @@ export default function ShopPage() {
         ) : null}
 
         {Object.keys(categorisedIngredients)
-          .sort()
+          .sort((a, b) => aisleOf(a) - aisleOf(b) || a.localeCompare(b))
           .map((category) => (
             <div className="mb-4" key={category}>
-              <h2 className="mb-2 text-xl font-bold">{category}</h2>
+              <h2 className="mb-2 text-xl font-bold">
+                {categoryName(category)}
+              </h2>
               <ul>
                 {(categorisedIngredients[category] ?? []).map((ingredient) => (
                   <IngredientListItem
@@ -182,7 +196,8 @@ function IngredientListItem({
   ingredient: Product & {
     mealCount: number;
     isInBasket: boolean;
-    quantities: { unit: string; amount: number }[];
+    covered?: boolean;
+    quantities: Quantity[];
   };
   shopId: string;
   onAddToBasket: (ingredient: Product) => void;
@@ -198,7 +213,12 @@ function IngredientListItem({
           "line-through": ingredient.isInBasket,
         })}
       >
-        {ingredient.name}
+        <span>
+          {ingredient.name}
+          {ingredient.covered && (
+            <span className="ml-1 text-xs text-gray-500">in pantry</span>
+          )}
+        </span>
 
         <input
           type="checkbox"
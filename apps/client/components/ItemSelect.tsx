@@ -232,7 +232,7 @@ function AddNewProductModal({
                 >
                   <option value="">Select a category</option>
                   {categories?.map((category) => (
-                    <option key={category.name} value={category.name}>
+                    <option key={category.id} value={category.id}>
                       {category.name}
                     </option>
                   ))}
@@ -1,7 +1,16 @@
-export function Unit({
-  quantity: { unit, amount },
-}: {
-  quantity: { unit: string; amount: number };
-}) {
-  return unit !== "Number" ? " " + unit + (amount > 1 ? "s" : "") : "x";
+import { Amount, Quantity } from "../types";
+
+export function Unit({ quantity: { unit, amount } }: { quantity: Quantity }) {
+  return unit !== "Number"
+    ? " " + unit + (amountValue(amount) > 1 ? "s" : "")
+    : "x";
+}
+
+export function amountValue(amount: Amount): number {
+  if (typeof amount === "number") {
+    return amount;
+  }
+
+  const [numerator, denominator = "1"] = amount.split("/");
+  return Number(numerator) / Number(denominator);
 }
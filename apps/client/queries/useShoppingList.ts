@@ -1,11 +1,15 @@
 import { useQuery } from "@tanstack/react-query";
 import { fetchShoppingList } from "../actions";
 import { Product } from "../types/product";
+import { Quantity } from "../types/quantity";
 
 type ShoppingListItem = Product & {
   mealCount: number;
   isInBasket: boolean;
-  quantities: { unit: string; amount: number }[];
+  quantities: Quantity[];
+  total: Quantity[];
+  inPantry?: Quantity;
+  covered?: boolean;
 };
 
 export function useShoppingList() {
@@ -1,10 +1,24 @@
 import { useMutation } from "@tanstack/react-query";
+import { UploadMode, UploadReport } from "../types";
 
 export function useUploadMeals() {
   return useMutation({
-    mutationFn: async (meals: File) => {
+    mutationFn: async ({
+      meals,
+      mode = "create",
+      dryRun = false,
+      autoCreateProducts = false,
+    }: {
+      meals: File;
+      mode?: UploadMode;
+      dryRun?: boolean;
+      autoCreateProducts?: boolean;
+    }) => {
       const formData = new FormData();
       formData.append("meals", meals);
+      formData.append("mode", mode);
+      formData.append("dryRun", String(dryRun));
+      formData.append("autoCreateProducts", String(autoCreateProducts));
 
       const response = await fetch("/api/meals/upload", {
         method: "POST",
@@ -18,7 +32,11 @@ export function useUploadMeals() {
         };
       }
 
-      return { status: response.status, error: null };
+      return {
+        status: response.status,
+        error: null,
+        report: (await response.json()) as UploadReport,
+      };
     },
   });
 }
@@ -1,6 +1,7 @@
 export type Basket = {
   shopId: string;
   items: BasketItem[];
+  frozen?: boolean;
 };
 
 export type BasketItem = {
@@ -2,3 +2,6 @@ export * from "./product";
 export * from "./meal";
 export * from "./shop";
 export * from "./basket";
+export * from "./store";
+export * from "./recipe";
+export * from "./quantity";
@@ -1,14 +1,46 @@
+import { Product } from "./product";
+import { Quantity } from "./quantity";
+
 export type Meal = {
   id: string;
   name: string;
   url: string;
+  servings: number;
   ingredients: Ingredient[];
+  steps?: string[];
+  prepTime?: number;
+  cookTime?: number;
+  tags?: string[];
 };
 
 export type Ingredient = {
   id: string;
-  quantity: {
-    amount: number;
-    unit: string;
-  };
+  ingredientId: string;
+  quantity: Quantity;
+  note?: string;
+};
+
+export type UploadMode = "create" | "update" | "skip";
+
+export type UploadReport = {
+  dryRun: boolean;
+  meals: {
+    rows: number[];
+    name: string;
+    mealId?: string;
+    action: "create" | "update" | "unchanged" | "skip" | "reject";
+    reason?: string;
+    added?: Ingredient[];
+    removed?: Ingredient[];
+    changed?: Ingredient[];
+  }[];
+  notFoundProducts: string[];
+  createdProducts: Product[];
+};
+
+export type UploadRowError = {
+  row: number;
+  column?: string;
+  value?: string;
+  reason: string;
 };
@@ -1,9 +1,15 @@
+import { Quantity } from "./quantity";
+
 export type Product = {
   id: string;
   name: string;
   category: string;
+  defaultQuantity?: Quantity;
+  archived?: boolean;
+  mergedInto?: string;
 };
 
 export type Category = {
+  id: string;
   name: string;
 };
@@ -0,0 +1,8 @@
+// Amounts come back as numbers when they can be written as decimals, and as
+// fraction strings like "1/3" when they can't.
+export type Amount = number | string;
+
+export type Quantity = {
+  amount: Amount;
+  unit: string;
+};
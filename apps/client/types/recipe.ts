@@ -0,0 +1,22 @@
+import { Quantity } from "./quantity";
+
+export type MealProposal = {
+  name: string;
+  url: string;
+  servings: number;
+  ingredients: ProposedIngredient[];
+  steps: string[];
+  prepTime: number;
+  cookTime: number;
+  tags: string[];
+  notFoundProducts: string[];
+};
+
+export type ProposedIngredient = {
+  line: string;
+  product: string;
+  productId?: string;
+  matched: boolean;
+  quantity: Quantity;
+  note?: string;
+};
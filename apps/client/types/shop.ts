@@ -1,11 +1,29 @@
+import { Quantity } from "./quantity";
+
 export type Shop = {
   id: string;
-  meals: { id: string }[];
+  storeId?: string;
+  meals: {
+    id: string;
+    servings?: number;
+    schedule?: { date: string; slot: "Breakfast" | "Lunch" | "Dinner" };
+  }[];
   items: {
     productId: string;
-    quantity: {
-      amount: number;
-      unit: string;
-    };
+    quantity: Quantity;
   }[];
+  completion?: {
+    completedAt: string;
+    bought: string[];
+    abandoned: string[];
+    covered: string[];
+  };
+};
+
+export type ShopSummary = {
+  id: number;
+  startedAt: string;
+  completedAt?: string;
+  mealCount: number;
+  itemCount: number;
 };
@@ -0,0 +1,6 @@
+export type Store = {
+  id: string;
+  name: string;
+  layout: string[];
+  locations: Record<string, string>;
+};